@@ -0,0 +1,70 @@
+package uinput
+
+import "fmt"
+
+// AxisPolicy determines how a device handles values that are out of the range of an absolute axis.
+type AxisPolicy int
+
+const (
+	// AxisUnchecked passes all values to the device as they are. This is the default.
+	AxisUnchecked AxisPolicy = iota
+	// AxisClamp limits values to the range of the axis.
+	AxisClamp
+	// AxisError rejects values that are out of range with an error.
+	AxisError
+	// AxisWrap wraps values around the range of the axis, e.g. max+1 becomes min.
+	AxisWrap
+)
+
+// AbsAxisOrder determines the order in which the x- and the y-axis of an absolute position are reported within a
+// report. Consumers should not depend on the order, but some drivers do, which makes it useful for debugging.
+type AbsAxisOrder int
+
+const (
+	// AbsAxisOrderXY reports ABS_X before ABS_Y. This is the default.
+	AbsAxisOrderXY AbsAxisOrder = iota
+	// AbsAxisOrderYX reports ABS_Y before ABS_X.
+	AbsAxisOrderYX
+)
+
+// orderAbsEvents swaps the leading ABS_X and ABS_Y events of the given events if the order is AbsAxisOrderYX.
+func orderAbsEvents(events []inputEvent, order AbsAxisOrder) []inputEvent {
+	if order == AbsAxisOrderYX {
+		events[0], events[1] = events[1], events[0]
+	}
+	return events
+}
+
+// clampAxis applies the given policy to a value of an axis with the range [min, max].
+func clampAxis(value, min, max int32, policy AxisPolicy) (int32, error) {
+	if min > max || (value >= min && value <= max) {
+		return value, nil
+	}
+
+	switch policy {
+	case AxisClamp:
+		if value < min {
+			return min, nil
+		}
+		return max, nil
+	case AxisError:
+		return 0, fmt.Errorf("value %d is out of range [%d, %d]", value, min, max)
+	case AxisWrap:
+		size := int64(max) - int64(min) + 1
+		offset := (int64(value) - int64(min)) % size
+		if offset < 0 {
+			offset += size
+		}
+		return int32(int64(min) + offset), nil
+	}
+	return value, nil
+}
+
+// validateAxisRange ensures that the minimum of the named axis is lower than its maximum, since the kernel does
+// not define the behavior of devices with empty or inverted ranges.
+func validateAxisRange(axis string, min, max int32) error {
+	if min >= max {
+		return fmt.Errorf("invalid range of the %s-axis: minimum %d must be lower than maximum %d", axis, min, max)
+	}
+	return nil
+}
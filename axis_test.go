@@ -0,0 +1,94 @@
+package uinput
+
+import (
+	"testing"
+)
+
+func TestClampAxisPolicies(t *testing.T) {
+	tests := []struct {
+		policy   AxisPolicy
+		value    int32
+		expected int32
+		fails    bool
+	}{
+		{policy: AxisUnchecked, value: -1, expected: -1},
+		{policy: AxisUnchecked, value: 101, expected: 101},
+		{policy: AxisClamp, value: 0, expected: 0},
+		{policy: AxisClamp, value: 100, expected: 100},
+		{policy: AxisClamp, value: -1, expected: 0},
+		{policy: AxisClamp, value: 101, expected: 100},
+		{policy: AxisError, value: 0, expected: 0},
+		{policy: AxisError, value: 100, expected: 100},
+		{policy: AxisError, value: -1, fails: true},
+		{policy: AxisError, value: 101, fails: true},
+		{policy: AxisWrap, value: 0, expected: 0},
+		{policy: AxisWrap, value: 100, expected: 100},
+		{policy: AxisWrap, value: -1, expected: 100},
+		{policy: AxisWrap, value: 101, expected: 0},
+		{policy: AxisWrap, value: 303, expected: 0},
+	}
+
+	for _, test := range tests {
+		actual, err := clampAxis(test.value, 0, 100, test.policy)
+		if test.fails {
+			if err == nil {
+				t.Fatalf("Expected policy %d to reject %d, but got %d", test.policy, test.value, actual)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Expected policy %d to accept %d, but got: %v", test.policy, test.value, err)
+		}
+		if actual != test.expected {
+			t.Fatalf("Expected policy %d to map %d to %d, but got %d", test.policy, test.value, test.expected, actual)
+		}
+	}
+}
+
+func TestTouchPadAppliesAxisPolicy(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := &vTouchPad{deviceBase: deviceBase{report: newReportBuilder(file)}, minX: 0, maxX: 1024, minY: 0, maxY: 768, axisPolicy: AxisClamp}
+
+	err := dev.MoveTo(2000, -5)
+	if err != nil {
+		t.Fatalf("Failed to move touch pad. Last error was: %s\n", err)
+	}
+	if x, y := dev.GetPosition(); x != 1024 || y != 0 {
+		t.Fatalf("Expected position to be clamped to (1024, 0), but got (%d, %d)", x, y)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absX, Value: 1024},
+		{Type: evAbs, Code: absY, Value: 0},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestStylusRejectsValuesOutOfRange(t *testing.T) {
+	dev := vStylus{minX: 0, maxX: 1024, minY: 0, maxY: 768, axisPolicy: AxisError}
+
+	err := dev.HoverTo(0, 769)
+	if err == nil {
+		t.Fatalf("Expected hovering out of range to fail, but got no error.")
+	}
+}
+
+func TestGamepadWrapsValuesOutOfRange(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vGamepad{
+		deviceBase: deviceBase{report: newReportBuilder(file)},
+		axes:       map[uint16]gamepadAxis{absX: {code: absX, min: 0, max: 255}},
+		axisPolicy: AxisWrap,
+	}
+
+	// the normalized value 1 maps to the maximum, anything beyond wraps around
+	err := dev.LeftStickMoveX(1 + 2.0/255)
+	if err != nil {
+		t.Fatalf("Failed to move stick. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absX, Value: 0},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
@@ -0,0 +1,236 @@
+package uinput
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// A Device is a generic input device that has been constructed using a DeviceBuilder. Since its capabilities are
+// defined by the caller, it only provides low-level access to its events.
+type Device interface {
+	EventSender
+	RawEventWriter
+	Flusher
+	Resetter
+
+	// GetAxis returns the value that has last been reported for the given absolute axis, or zero if it has not been
+	// reported yet. An error is returned if the axis has not been added to the device.
+	GetAxis(code uint16) (int32, error)
+
+	// FetchSyspath will return the syspath to the device file.
+	FetchSyspath() (string, error)
+
+	// FetchSyspathContext works like FetchSyspath, but retries until the syspath is available or the context is
+	// done. In the latter case, the last error is returned along with the error of the context.
+	FetchSyspathContext(ctx context.Context) (string, error)
+
+	io.Closer
+}
+
+// A DeviceBuilder constructs devices with arbitrary capabilities, for cases that are not covered by the devices
+// of this package. All methods return the builder, so that calls can be chained:
+//
+//	device, err := uinput.NewDeviceBuilder().
+//		AddKey(uinput.KeyA).
+//		AddAbs(0x00, 0, 1024, 10).
+//		Build("/dev/uinput", []byte("Custom Device"))
+//
+// The event types of keys and axes are registered automatically. Invalid codes or ranges are reported by Build.
+type DeviceBuilder struct {
+	evTypes []uint16
+	keys    []uint16
+	rels    []uint16
+	abs     []builderAxis
+	props   []uint16
+	id      inputID
+	err     error
+}
+
+type builderAxis struct {
+	code       uint16
+	min, max   int32
+	resolution int32
+}
+
+// NewDeviceBuilder returns a builder for a device without any capabilities.
+func NewDeviceBuilder() *DeviceBuilder {
+	return &DeviceBuilder{id: inputID{Bustype: busUsb, Vendor: 0x4711, Product: 0x081d, Version: 1}}
+}
+
+// AddEvType registers the given event type (e.g. EV_MSC), which is only needed for types whose codes can not be
+// added using the other methods.
+func (b *DeviceBuilder) AddEvType(evType uint16) *DeviceBuilder {
+	for _, t := range b.evTypes {
+		if t == evType {
+			return b
+		}
+	}
+	b.evTypes = append(b.evTypes, evType)
+	return b
+}
+
+// AddKey registers the given key or button.
+func (b *DeviceBuilder) AddKey(code uint16) *DeviceBuilder {
+	if code > kernelKeyMax {
+		b.setErr(fmt.Errorf("failed to register key. Code %d is not in range", code))
+	}
+	b.keys = append(b.keys, code)
+	return b.AddEvType(evKey)
+}
+
+// AddAbs registers the given absolute axis with the given range and resolution (in units per millimeter, or
+// units per radian for rotational axes). Note that the resolution is only applied by kernels that support
+// UI_ABS_SETUP (see KernelSupportsSetup).
+func (b *DeviceBuilder) AddAbs(code uint16, min int32, max int32, resolution int32) *DeviceBuilder {
+	if code >= absSize {
+		b.setErr(fmt.Errorf("unsupported absolute axis code %d (must be lower than %d)", code, absSize))
+	} else if err := validateAxisRange(fmt.Sprintf("%d", code), min, max); err != nil {
+		b.setErr(err)
+	}
+	b.abs = append(b.abs, builderAxis{code: code, min: min, max: max, resolution: resolution})
+	return b.AddEvType(evAbs)
+}
+
+// AddRel registers the given relative axis.
+func (b *DeviceBuilder) AddRel(code uint16) *DeviceBuilder {
+	if code > relMax {
+		b.setErr(fmt.Errorf("failed to register relative axis. Code %d is not in range", code))
+	}
+	b.rels = append(b.rels, code)
+	return b.AddEvType(evRel)
+}
+
+// AddProp registers the given input property (e.g. INPUT_PROP_DIRECT).
+func (b *DeviceBuilder) AddProp(prop uint16) *DeviceBuilder {
+	b.props = append(b.props, prop)
+	return b
+}
+
+// SetID sets the bus type, vendor, product and version of the device.
+func (b *DeviceBuilder) SetID(bustype, vendor, product, version uint16) *DeviceBuilder {
+	b.id = inputID{Bustype: bustype, Vendor: vendor, Product: product, Version: version}
+	return b
+}
+
+func (b *DeviceBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// Build creates the device. The event types are registered in the order they have been added, followed by the
+// keys, relative axes, absolute axes and properties.
+func (b *DeviceBuilder) Build(path string, name []byte, opts ...Option) (Device, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	err := validateDevicePath(path)
+	if err != nil {
+		return nil, err
+	}
+	err = validateUinputName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	options := newDeviceOptions(opts)
+	fd, err := b.createDevice(path, name, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return vDevice{
+		deviceBase: deviceBase{
+			name:       name,
+			deviceFile: fd,
+			report:     newReportBuilderWithOptions(fd, name, options.withoutReportInterval()),
+		},
+		axes: b.abs,
+	}, nil
+}
+
+func (b *DeviceBuilder) createDevice(path string, name []byte, options deviceOptions) (fd *os.File, err error) {
+	deviceFile, err := createDeviceFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not create input device: %w", err)
+	}
+
+	for _, evType := range b.evTypes {
+		err = registerDevice(deviceFile, uintptr(evType))
+		if err != nil {
+			_ = deviceFile.Close()
+			return nil, fmt.Errorf("failed to register event type %v: %w", evType, err)
+		}
+	}
+
+	codes := []struct {
+		cmd   uintptr
+		kind  string
+		codes []uint16
+	}{
+		{uiSetKeyBit, "key", b.keys},
+		{uiSetRelBit, "relative axis", b.rels},
+		{uiSetAbsBit, "absolute axis", absCodes(b.abs)},
+		{uiSetPropBit, "property", b.props},
+	}
+	for _, c := range codes {
+		for _, code := range c.codes {
+			err = ioctl(deviceFile, c.cmd, uintptr(code))
+			if err != nil {
+				_ = deviceFile.Close()
+				return nil, fmt.Errorf("failed to register %s %v: %w", c.kind, code, err)
+			}
+		}
+	}
+
+	dev, resolution := b.userDev(name)
+	options.absResolution = resolution
+	return createUsbDevice(deviceFile, dev, options)
+}
+
+// userDev returns the description of the device, along with the resolution of its absolute axes.
+func (b *DeviceBuilder) userDev(name []byte) (dev uinputUserDev, resolution [absSize]int32) {
+	dev = uinputUserDev{Name: toUinputName(name), ID: b.id}
+	for _, axis := range b.abs {
+		dev.Absmin[axis.code] = axis.min
+		dev.Absmax[axis.code] = axis.max
+		resolution[axis.code] = axis.resolution
+	}
+	return dev, resolution
+}
+
+func absCodes(axes []builderAxis) []uint16 {
+	codes := make([]uint16, 0, len(axes))
+	for _, axis := range axes {
+		codes = append(codes, axis.code)
+	}
+	return codes
+}
+
+type vDevice struct {
+	deviceBase
+	axes []builderAxis
+}
+
+// Reset releases all keys that are held down and returns the absolute axes to zero, or to the midpoint of their
+// range if it does not include zero (see Resetter).
+func (vDev vDevice) Reset() error {
+	events := make([]inputEvent, 0, len(vDev.axes))
+	for _, axis := range vDev.axes {
+		events = append(events, inputEvent{Type: evAbs, Code: axis.code, Value: axisRest(axis.min, axis.max)})
+	}
+	return vDev.report.reset(events...)
+}
+
+// GetAxis returns the last value of the given absolute axis, no matter whether it has been reported using SendEvent,
+// WriteEventNoSync or Reset.
+func (vDev vDevice) GetAxis(code uint16) (int32, error) {
+	for _, axis := range vDev.axes {
+		if axis.code == code {
+			return vDev.report.absValue(code), nil
+		}
+	}
+	return 0, fmt.Errorf("absolute axis %d is not registered", code)
+}
@@ -0,0 +1,120 @@
+package uinput
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeviceBuilderRegistersAllCapabilities(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+	defer fakeUinputVersion(uinputSetupVersion)()
+
+	dev, err := NewDeviceBuilder().
+		AddEvType(evMsc).
+		AddKey(KeyA).
+		AddKey(evBtnTouch).
+		AddRel(relWheel).
+		AddAbs(absX, 0, 1024, 12).
+		AddProp(inputPropDirect).
+		SetID(busUsb, 0x1234, 0x5678, 2).
+		Build(path, []byte("Test Device"))
+	if err != nil {
+		t.Fatalf("Failed to build the device. Last error was: %s\n", err)
+	}
+	defer dev.Close()
+
+	var sequence []ioctlCall
+	for _, call := range *calls {
+		if call.cmd == uiAbsSetup || call.cmd == uiDevSetup {
+			break
+		}
+		sequence = append(sequence, call)
+	}
+	expected := []ioctlCall{
+		{cmd: uiSetEvBit, ptr: evMsc},
+		{cmd: uiSetEvBit, ptr: evKey},
+		{cmd: uiSetEvBit, ptr: evRel},
+		{cmd: uiSetEvBit, ptr: evAbs},
+		{cmd: uiSetKeyBit, ptr: KeyA},
+		{cmd: uiSetKeyBit, ptr: evBtnTouch},
+		{cmd: uiSetRelBit, ptr: relWheel},
+		{cmd: uiSetAbsBit, ptr: absX},
+		{cmd: uiSetPropBit, ptr: inputPropDirect},
+	}
+	if !reflect.DeepEqual(sequence, expected) {
+		t.Fatalf("Expected: %v\nActual: %v", expected, sequence)
+	}
+
+	if n := len(registeredCodes(*calls, uiAbsSetup)); n != 1 {
+		t.Fatalf("Expected UI_ABS_SETUP to be issued once, but it was issued %d times", n)
+	}
+}
+
+func TestDeviceBuilderSetsUpAxesWithResolution(t *testing.T) {
+	builder := NewDeviceBuilder().AddAbs(absX, 0, 1024, 12).SetID(busUsb, 0x1234, 0x5678, 2)
+	dev, resolution := builder.userDev([]byte("Test Device"))
+
+	expectedID := inputID{Bustype: busUsb, Vendor: 0x1234, Product: 0x5678, Version: 2}
+	if dev.ID != expectedID {
+		t.Fatalf("Expected the device to have id %+v, but got %+v", expectedID, dev.ID)
+	}
+	expected := []uinputAbsSetup{{Code: absX, Absinfo: inputAbsinfo{Minimum: 0, Maximum: 1024, Resolution: 12}}}
+	if setups := absSetups(dev, resolution); !reflect.DeepEqual(setups, expected) {
+		t.Fatalf("Expected: %+v\nActual: %+v", expected, setups)
+	}
+}
+
+func TestDeviceBuilderRejectsInvalidAxis(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	_, err := NewDeviceBuilder().AddAbs(absX, 100, 0, 0).Build(path, []byte("Test Device"))
+	if err == nil {
+		t.Fatalf("Expected building a device with an inverted axis range to fail, but got no error.")
+	}
+	_, err = NewDeviceBuilder().AddAbs(absSize, 0, 100, 0).Build(path, []byte("Test Device"))
+	if err == nil {
+		t.Fatalf("Expected building a device with an unsupported axis code to fail, but got no error.")
+	}
+	if len(*calls) != 0 {
+		t.Fatalf("Expected the device not to be set up, but %d ioctls were issued", len(*calls))
+	}
+}
+
+func TestGetAxisReturnsLastReportedValue(t *testing.T) {
+	file, stop := recordEvents(t)
+	defer stop()
+	dev := vDevice{
+		deviceBase: deviceBase{report: newReportBuilder(file)},
+		axes:       []builderAxis{{code: absX, min: 0, max: 1024}, {code: absY, min: 100, max: 200}},
+	}
+
+	err := dev.SendEvent(evAbs, absX, 300)
+	if err != nil {
+		t.Fatalf("Failed to send event. Last error was: %s\n", err)
+	}
+	err = dev.SendEvent(evSyn, synReport, 0)
+	if err != nil {
+		t.Fatalf("Failed to send event. Last error was: %s\n", err)
+	}
+	if value, err := dev.GetAxis(absX); err != nil || value != 300 {
+		t.Fatalf("Expected ABS_X to be 300, but got %d (error: %v)", value, err)
+	}
+
+	err = dev.Reset()
+	if err != nil {
+		t.Fatalf("Failed to reset device. Last error was: %s\n", err)
+	}
+	if value, err := dev.GetAxis(absY); err != nil || value != 150 {
+		t.Fatalf("Expected ABS_Y to be 150 after the reset, but got %d (error: %v)", value, err)
+	}
+
+	if _, err := dev.GetAxis(absPressure); err == nil {
+		t.Fatalf("Expected GetAxis to fail for an axis that has not been added, but got no error.")
+	}
+}
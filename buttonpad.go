@@ -0,0 +1,122 @@
+package uinput
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// maxButtonPadButtons is the number of generic buttons that the kernel defines (BTN_0 to BTN_9).
+const maxButtonPadButtons = 10
+
+// A ButtonPad is a device with generic buttons, like a custom button box or a HID panel. Its buttons are
+// identified by their index, where index i corresponds to the code BTN_0 + i.
+type ButtonPad interface {
+	// Press will press the button with the given index. The button will remain pressed until it is released.
+	Press(index int) error
+
+	// Release will release the button with the given index.
+	Release(index int) error
+
+	// FetchSyspath will return the syspath to the device file.
+	FetchSyspath() (string, error)
+
+	// FetchSyspathContext works like FetchSyspath, but retries until the syspath is available or the context is
+	// done. In the latter case, the last error is returned along with the error of the context.
+	FetchSyspathContext(ctx context.Context) (string, error)
+
+	// Reset releases all buttons that are held down (see Resetter).
+	Reset() error
+
+	io.Closer
+}
+
+type vButtonPad struct {
+	deviceBase
+	count int
+}
+
+// CreateButtonPad will create a new button pad with the given number of buttons, which are registered as the
+// generic buttons BTN_0 to BTN_(count-1). The count must be between 1 and 10.
+func CreateButtonPad(path string, name []byte, count int, opts ...Option) (ButtonPad, error) {
+	err := validateDevicePath(path)
+	if err != nil {
+		return nil, err
+	}
+	err = validateUinputName(name)
+	if err != nil {
+		return nil, err
+	}
+	if count < 1 || count > maxButtonPadButtons {
+		return nil, fmt.Errorf("invalid number of buttons %d: must be between 1 and %d", count, maxButtonPadButtons)
+	}
+
+	options := newDeviceOptions(opts)
+	fd, err := createButtonPad(path, name, count, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return vButtonPad{
+		deviceBase: deviceBase{
+			name:       name,
+			deviceFile: fd,
+			report:     newReportBuilderWithOptions(fd, name, options.withoutReportInterval()),
+		},
+		count: count,
+	}, nil
+}
+
+// Press will press the button with the given index. The button will remain pressed until it is released.
+func (vb vButtonPad) Press(index int) error {
+	return vb.sendButton(index, btnStatePressed)
+}
+
+// Release will release the button with the given index.
+func (vb vButtonPad) Release(index int) error {
+	return vb.sendButton(index, btnStateReleased)
+}
+
+func (vb vButtonPad) sendButton(index int, state int) error {
+	if index < 0 || index >= vb.count {
+		return fmt.Errorf("button %d is out of range: the device has %d buttons", index, vb.count)
+	}
+	return sendBtnEvent(vb.report, []int{btn0 + index}, state)
+}
+
+// Reset releases all buttons that are held down (see Resetter).
+func (vb vButtonPad) Reset() error {
+	return vb.report.reset()
+}
+
+func createButtonPad(path string, name []byte, count int, options deviceOptions) (fd *os.File, err error) {
+	deviceFile, err := createDeviceFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not create button pad input device: %w", err)
+	}
+
+	err = registerDevice(deviceFile, uintptr(evKey))
+	if err != nil {
+		deviceFile.Close()
+		return nil, fmt.Errorf("failed to register button pad input device: %w", err)
+	}
+
+	for i := 0; i < count; i++ {
+		err = ioctl(deviceFile, uiSetKeyBit, uintptr(btn0+i))
+		if err != nil {
+			deviceFile.Close()
+			return nil, fmt.Errorf("failed to register button %d: %w", i, err)
+		}
+	}
+
+	return createUsbDevice(deviceFile,
+		uinputUserDev{
+			Name: toUinputName(name),
+			ID: inputID{
+				Bustype: busUsb,
+				Vendor:  0x4711,
+				Product: 0x081e,
+				Version: 1}},
+		options)
+}
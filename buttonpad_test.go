@@ -0,0 +1,100 @@
+package uinput
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestButtonPadPress(t *testing.T) {
+	pad, err := CreateButtonPad("/dev/uinput", []byte("Test ButtonPad"), 5)
+	if err != nil {
+		t.Fatalf("Failed to create the virtual button pad. Last error was: %s\n", err)
+	}
+
+	err = pad.Press(3)
+	if err != nil {
+		t.Fatalf("Failed to press button. Last error was: %s\n", err)
+	}
+
+	err = pad.Release(3)
+	if err != nil {
+		t.Fatalf("Failed to release button. Last error was: %s\n", err)
+	}
+
+	err = pad.Close()
+	if err != nil {
+		t.Fatalf("Failed to close device. Last error was: %s\n", err)
+	}
+}
+
+func TestButtonPadPressSendsGenericButton(t *testing.T) {
+	file, stop := recordEvents(t)
+	pad := vButtonPad{deviceBase: deviceBase{report: newReportBuilder(file)}, count: 5}
+
+	err := pad.Press(3)
+	if err != nil {
+		t.Fatalf("Failed to press button. Last error was: %s\n", err)
+	}
+	err = pad.Release(3)
+	if err != nil {
+		t.Fatalf("Failed to release button. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: 0x103, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: 0x103, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestButtonPadRejectsIndexOutOfRange(t *testing.T) {
+	file, stop := recordEvents(t)
+	pad := vButtonPad{deviceBase: deviceBase{report: newReportBuilder(file)}, count: 5}
+
+	for _, index := range []int{-1, 5} {
+		expected := fmt.Sprintf("button %d is out of range: the device has 5 buttons", index)
+		err := pad.Press(index)
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected: %s\nActual: %v", expected, err)
+		}
+	}
+
+	assertEvents(t, nil, stop())
+}
+
+func TestButtonPadRegistersGivenNumberOfButtons(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	pad, err := CreateButtonPad(path, []byte("Test ButtonPad"), 3)
+	if err != nil {
+		t.Fatalf("Failed to create the virtual button pad. Last error was: %s\n", err)
+	}
+	defer pad.Close()
+
+	keyBits := registeredCodes(*calls, uiSetKeyBit)
+	if !reflect.DeepEqual(keyBits, []uintptr{0x100, 0x101, 0x102}) {
+		t.Fatalf("Expected BTN_0 to BTN_2 to be registered, but got %v", keyBits)
+	}
+}
+
+func TestButtonPadCreationFailsOnInvalidCount(t *testing.T) {
+	for _, count := range []int{0, 11} {
+		_, err := CreateButtonPad("/dev/uinput", []byte("Test ButtonPad"), count)
+		if err == nil {
+			t.Fatalf("Expected creation to fail for %d buttons, but no error was returned.", count)
+		}
+	}
+}
+
+func TestButtonPadCreationFailsOnEmptyPath(t *testing.T) {
+	expected := "device path must not be empty"
+	_, err := CreateButtonPad("", []byte("ButtonPadDevice"), 5)
+	if err.Error() != expected {
+		t.Fatalf("Expected: %s\nActual: %s", expected, err)
+	}
+}
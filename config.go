@@ -0,0 +1,102 @@
+package uinput
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// A DeviceConfig describes the capabilities of a device that can be constructed using a DeviceBuilder. It is the
+// JSON representation that is produced by MarshalConfig and consumed by CreateFromConfig, which allows storing
+// device definitions, e.g. as test fixtures.
+type DeviceConfig struct {
+	Name    string       `json:"name"`
+	ID      ConfigID     `json:"id"`
+	EvTypes []uint16     `json:"evTypes,omitempty"`
+	Keys    []uint16     `json:"keys,omitempty"`
+	Rels    []uint16     `json:"rels,omitempty"`
+	Abs     []ConfigAxis `json:"abs,omitempty"`
+	Props   []uint16     `json:"props,omitempty"`
+}
+
+// ConfigID holds the bus type, vendor, product and version of a DeviceConfig.
+type ConfigID struct {
+	Bustype uint16 `json:"bustype"`
+	Vendor  uint16 `json:"vendor"`
+	Product uint16 `json:"product"`
+	Version uint16 `json:"version"`
+}
+
+// ConfigAxis describes an absolute axis of a DeviceConfig (see DeviceBuilder.AddAbs).
+type ConfigAxis struct {
+	Code       uint16 `json:"code"`
+	Min        int32  `json:"min"`
+	Max        int32  `json:"max"`
+	Resolution int32  `json:"resolution,omitempty"`
+}
+
+// Config returns the capabilities that have been added to the builder, along with the given name (see DeviceConfig).
+func (b *DeviceBuilder) Config(name []byte) (DeviceConfig, error) {
+	if b.err != nil {
+		return DeviceConfig{}, b.err
+	}
+	err := validateUinputName(name)
+	if err != nil {
+		return DeviceConfig{}, err
+	}
+
+	config := DeviceConfig{
+		Name:    string(name),
+		ID:      ConfigID{Bustype: b.id.Bustype, Vendor: b.id.Vendor, Product: b.id.Product, Version: b.id.Version},
+		EvTypes: b.evTypes,
+		Keys:    b.keys,
+		Rels:    b.rels,
+		Props:   b.props,
+	}
+	for _, axis := range b.abs {
+		config.Abs = append(config.Abs, ConfigAxis{Code: axis.code, Min: axis.min, Max: axis.max, Resolution: axis.resolution})
+	}
+	return config, nil
+}
+
+// MarshalConfig serializes the capabilities that have been added to the builder, along with the given name, to
+// JSON (see DeviceConfig). The device can be recreated from the result using CreateFromConfig.
+func (b *DeviceBuilder) MarshalConfig(name []byte) ([]byte, error) {
+	config, err := b.Config(name)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(config)
+}
+
+// CreateFromConfig creates a device from a configuration that has been serialized using MarshalConfig. The
+// capabilities are registered in the same order as they were by the builder the configuration was created from.
+func CreateFromConfig(path string, config []byte, opts ...Option) (Device, error) {
+	var c DeviceConfig
+	err := json.Unmarshal(config, &c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse device config: %w", err)
+	}
+	return newBuilderFromConfig(c).Build(path, []byte(c.Name), opts...)
+}
+
+// newBuilderFromConfig returns a builder with the capabilities of the given configuration. The event types are
+// added first, in order to keep their order.
+func newBuilderFromConfig(c DeviceConfig) *DeviceBuilder {
+	b := NewDeviceBuilder().SetID(c.ID.Bustype, c.ID.Vendor, c.ID.Product, c.ID.Version)
+	for _, evType := range c.EvTypes {
+		b.AddEvType(evType)
+	}
+	for _, code := range c.Keys {
+		b.AddKey(code)
+	}
+	for _, code := range c.Rels {
+		b.AddRel(code)
+	}
+	for _, axis := range c.Abs {
+		b.AddAbs(axis.Code, axis.Min, axis.Max, axis.Resolution)
+	}
+	for _, prop := range c.Props {
+		b.AddProp(prop)
+	}
+	return b
+}
@@ -0,0 +1,105 @@
+package uinput
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func testConfigBuilder() *DeviceBuilder {
+	return NewDeviceBuilder().
+		AddEvType(evMsc).
+		AddKey(KeyA).
+		AddKey(evBtnTouch).
+		AddRel(relWheel).
+		AddAbs(absX, 0, 1024, 12).
+		AddAbs(absY, -100, 100, 0).
+		AddProp(inputPropDirect).
+		SetID(busUsb, 0x1234, 0x5678, 2)
+}
+
+func TestDeviceConfigRoundTrip(t *testing.T) {
+	builder := testConfigBuilder()
+	data, err := builder.MarshalConfig([]byte("Test Device"))
+	if err != nil {
+		t.Fatalf("Failed to marshal the config. Last error was: %s\n", err)
+	}
+
+	var config DeviceConfig
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal the config. Last error was: %s\n", err)
+	}
+	if config.Name != "Test Device" {
+		t.Fatalf("Expected the name %q, but got %q", "Test Device", config.Name)
+	}
+	if recreated := newBuilderFromConfig(config); !reflect.DeepEqual(recreated, builder) {
+		t.Fatalf("Expected: %+v\nActual: %+v", builder, recreated)
+	}
+}
+
+// createCalls returns the ioctl calls that register the capabilities of a device, along with its setup.
+func createCalls(t *testing.T, create func(path string) (Device, error)) ([]ioctlCall, uinputUserDev) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	dev, err := create(path)
+	if err != nil {
+		t.Fatalf("Failed to create the device. Last error was: %s\n", err)
+	}
+	defer dev.Close()
+
+	var registrations []ioctlCall
+	for _, call := range *calls {
+		switch call.cmd {
+		case uiSetEvBit, uiSetKeyBit, uiSetRelBit, uiSetAbsBit, uiSetPropBit:
+			registrations = append(registrations, call)
+		}
+	}
+	return registrations, readUserDev(t, path)
+}
+
+func TestCreateFromConfigRecreatesEquivalentDevice(t *testing.T) {
+	builder := testConfigBuilder()
+	data, err := builder.MarshalConfig([]byte("Test Device"))
+	if err != nil {
+		t.Fatalf("Failed to marshal the config. Last error was: %s\n", err)
+	}
+
+	expectedCalls, expectedDev := createCalls(t, func(path string) (Device, error) {
+		return builder.Build(path, []byte("Test Device"))
+	})
+	calls, dev := createCalls(t, func(path string) (Device, error) {
+		return CreateFromConfig(path, data)
+	})
+
+	if !reflect.DeepEqual(calls, expectedCalls) {
+		t.Fatalf("Expected: %v\nActual: %v", expectedCalls, calls)
+	}
+	if dev != expectedDev {
+		t.Fatalf("Expected: %+v\nActual: %+v", expectedDev, dev)
+	}
+}
+
+func TestCreateFromConfigRejectsInvalidConfig(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+
+	_, err := CreateFromConfig(path, []byte("{"))
+	if err == nil {
+		t.Fatalf("Expected creating a device from malformed JSON to fail, but got no error.")
+	}
+	_, err = CreateFromConfig(path, []byte(`{"name": "Test Device", "abs": [{"code": 0, "min": 100, "max": 0}]}`))
+	if err == nil {
+		t.Fatalf("Expected creating a device with an inverted axis range to fail, but got no error.")
+	}
+}
+
+func TestMarshalConfigFailsForInvalidBuilder(t *testing.T) {
+	_, err := NewDeviceBuilder().AddKey(kernelKeyMax + 1).MarshalConfig([]byte("Test Device"))
+	if err == nil {
+		t.Fatalf("Expected marshalling an invalid builder to fail, but got no error.")
+	}
+}
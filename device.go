@@ -0,0 +1,107 @@
+package uinput
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+// errNoDeviceFile is returned by the methods that need a uinput device if the device writes elsewhere instead,
+// like a mouse that has been created using CreateMouseWriter.
+var errNoDeviceFile = errors.New("device is not backed by a uinput device")
+
+// deviceBase holds what all devices have in common, and implements the capabilities that only depend on the
+// device file and the reportBuilder (e.g. Flusher, StatsReporter or HealthReporter). Devices embed it, so that
+// these methods are not repeated for every device.
+type deviceBase struct {
+	name       []byte
+	deviceFile *os.File
+	// report writes all input events. Its destination is the device file itself, unless the device has been
+	// created using a writer (see CreateMouseWriter), in which case deviceFile is nil.
+	report *reportBuilder
+}
+
+// FetchSyspath will return the syspath to the device file.
+func (b deviceBase) FetchSyspath() (string, error) {
+	if b.deviceFile == nil {
+		return "", errNoDeviceFile
+	}
+	return fetchSyspath(b.deviceFile)
+}
+
+// FetchSyspathContext works like FetchSyspath, but retries until the syspath is available or the context is done.
+func (b deviceBase) FetchSyspathContext(ctx context.Context) (string, error) {
+	if b.deviceFile == nil {
+		return "", errNoDeviceFile
+	}
+	return fetchSyspathContext(ctx, b.deviceFile)
+}
+
+// ChownDevNode changes the owner of the event node of the device (see DevNodeChowner).
+func (b deviceBase) ChownDevNode(uid int, gid int) error {
+	return chownDevNode(b.FetchSyspath, uid, gid)
+}
+
+// GrabDevNode grabs the event node of the device exclusively (see DevNodeGrabber).
+func (b deviceBase) GrabDevNode() error {
+	return grabDevNode(b.deviceFile, b.FetchSyspath)
+}
+
+// UngrabDevNode releases the grab of GrabDevNode (see DevNodeGrabber).
+func (b deviceBase) UngrabDevNode() error {
+	return ungrabDevNode(b.deviceFile)
+}
+
+// WriteEventNoSync writes a raw event without completing the report (see RawEventWriter).
+func (b deviceBase) WriteEventNoSync(evType uint16, code uint16, value int32) error {
+	return b.report.WriteEventNoSync(evType, code, value)
+}
+
+// Sync completes the report that has been started using WriteEventNoSync (see RawEventWriter).
+func (b deviceBase) Sync() error {
+	return b.report.Sync()
+}
+
+// SendEvent adds a raw event to the pending report, which is written once a SYN_REPORT is sent (see EventSender).
+func (b deviceBase) SendEvent(evType uint16, code uint16, value int32) error {
+	return b.report.SendEvent(evType, code, value)
+}
+
+// Flush writes the reports that have been buffered due to the flush threshold (see Flusher).
+func (b deviceBase) Flush() error {
+	return b.report.Flush()
+}
+
+// Stats returns the counters of the events that have been written to the device (see StatsReporter).
+func (b deviceBase) Stats() Stats {
+	return b.report.Stats()
+}
+
+// Healthy reports whether the most recent write to the device succeeded (see HealthReporter).
+func (b deviceBase) Healthy() bool {
+	return b.report.Healthy()
+}
+
+// LastError returns the error of the most recent write that failed (see HealthReporter).
+func (b deviceBase) LastError() error {
+	return b.report.LastError()
+}
+
+// IsAlive probes whether the kernel still has the device (see HealthReporter).
+func (b deviceBase) IsAlive() bool {
+	return b.report.IsAlive()
+}
+
+// KeepAlive emits a timestamp every interval until stopped (see KeepAliver).
+func (b deviceBase) KeepAlive(interval time.Duration) (stop func(), err error) {
+	return b.report.KeepAlive(interval)
+}
+
+// Close writes the pending events, then closes and releases the device.
+func (b deviceBase) Close() error {
+	if b.deviceFile == nil {
+		return errors.Join(b.report.close(), b.report.heldKeys())
+	}
+	return closeDeviceWithReport(b.report, b.deviceFile)
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"syscall"
 )
 
 // A Dial is a device that will trigger rotation events.
@@ -17,12 +16,11 @@ type Dial interface {
 }
 
 type vDial struct {
-	name       []byte
-	deviceFile *os.File
+	deviceBase
 }
 
 // CreateDial will create a new dial input device. A dial is a device that can trigger rotation events.
-func CreateDial(path string, name []byte) (Dial, error) {
+func CreateDial(path string, name []byte, opts ...Option) (Dial, error) {
 	err := validateDevicePath(path)
 	if err != nil {
 		return nil, err
@@ -32,41 +30,48 @@ func CreateDial(path string, name []byte) (Dial, error) {
 		return nil, err
 	}
 
-	fd, err := createDial(path, name)
+	options := newDeviceOptions(opts)
+	fd, err := createDial(path, name, options)
 	if err != nil {
 		return nil, err
 	}
 
-	return vDial{name: name, deviceFile: fd}, nil
+	return vDial{
+		deviceBase: deviceBase{
+			name:       name,
+			deviceFile: fd,
+			report:     newReportBuilderWithOptions(fd, name, options.withoutReportInterval()),
+		},
+	}, nil
 }
 
 // Turn will simulate a dial movement.
 func (vRel vDial) Turn(delta int32) error {
-	return sendDialEvent(vRel.deviceFile, delta)
+	return sendRelEvent(vRel.report, relDial, delta)
 }
 
-// Close closes the device and releases the device.
-func (vRel vDial) Close() error {
-	return closeDevice(vRel.deviceFile)
+// Reset does nothing, since a dial has no state to reset (see Resetter).
+func (vRel vDial) Reset() error {
+	return vRel.report.reset()
 }
 
-func createDial(path string, name []byte) (fd *os.File, err error) {
+func createDial(path string, name []byte, options deviceOptions) (fd *os.File, err error) {
 	deviceFile, err := createDeviceFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("could not create dial input device: %v", err)
+		return nil, fmt.Errorf("could not create dial input device: %w", err)
 	}
 
 	err = registerDevice(deviceFile, uintptr(evRel))
 	if err != nil {
 		deviceFile.Close()
-		return nil, fmt.Errorf("failed to register dial input device: %v", err)
+		return nil, fmt.Errorf("failed to register dial input device: %w", err)
 	}
 
 	// register dial events
 	err = ioctl(deviceFile, uiSetRelBit, uintptr(relDial))
 	if err != nil {
 		deviceFile.Close()
-		return nil, fmt.Errorf("failed to register dial events: %v", err)
+		return nil, fmt.Errorf("failed to register dial events: %w", err)
 	}
 
 	return createUsbDevice(deviceFile,
@@ -76,25 +81,6 @@ func createDial(path string, name []byte) (fd *os.File, err error) {
 				Bustype: busUsb,
 				Vendor:  0x4711,
 				Product: 0x0816,
-				Version: 1}})
-}
-
-func sendDialEvent(deviceFile *os.File, delta int32) error {
-	iev := inputEvent{
-		Time:  syscall.Timeval{Sec: 0, Usec: 0},
-		Type:  evRel,
-		Code:  relDial,
-		Value: delta}
-
-	buf, err := inputEventToBuffer(iev)
-	if err != nil {
-		return fmt.Errorf("writing abs event failed: %v", err)
-	}
-
-	_, err = deviceFile.Write(buf)
-	if err != nil {
-		return fmt.Errorf("failed to write rel event to device file: %v", err)
-	}
-
-	return syncEvents(deviceFile)
+				Version: 1}},
+		options)
 }
@@ -0,0 +1,140 @@
+package uinput
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// An EventSender accepts raw input events. The events are collected until a SYN_REPORT is sent, which writes
+// them to the device as a single report. All devices of this package (except for the noop devices) implement
+// EventSender, so that any of them can be used for this low-level access, e.g. using mouse.(uinput.EventSender).
+type EventSender interface {
+	SendEvent(evType uint16, code uint16, value int32) error
+}
+
+// SendEvent adds the given event to the pending report. A SYN_REPORT writes the pending report.
+func (rb *reportBuilder) SendEvent(evType uint16, code uint16, value int32) error {
+	if evType == evSyn && code == synReport {
+		return rb.flush()
+	}
+	return rb.add(inputEvent{Type: evType, Code: code, Value: value})
+}
+
+// A RawEventWriter provides full control over the reports of a device: events are written right away, without
+// a trailing SYN_REPORT, until Sync is called. All devices of this package (except for the noop devices) implement
+// RawEventWriter. Note that consumers ignore events until the report that they are part of has been completed.
+type RawEventWriter interface {
+	WriteEventNoSync(evType uint16, code uint16, value int32) error
+	Sync() error
+}
+
+// WriteEventNoSync writes the given event to the device, preceded by any events that are pending or buffered,
+// but without completing the report.
+func (rb *reportBuilder) WriteEventNoSync(evType uint16, code uint16, value int32) error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	ev := inputEvent{Type: evType, Code: code, Value: value}
+	err := rb.validateLocked([]inputEvent{ev})
+	if err != nil {
+		return err
+	}
+	events := append(rb.events, ev)
+	rb.events = nil
+
+	err = rb.bufferLocked(events)
+	if err != nil {
+		return err
+	}
+	return rb.writeBufferLocked()
+}
+
+// Sync completes the current report by writing a SYN_REPORT, along with any events that are pending or buffered.
+func (rb *reportBuilder) Sync() error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	err := rb.flushLocked()
+	if err != nil {
+		return err
+	}
+	return rb.writeBufferLocked()
+}
+
+// ReplayEvemu reads a recording in the text format of evemu-record and sends the recorded events to the given
+// device, keeping the original timing between them. Only the event lines ("E: sec.usec type code value") are
+// replayed; comments as well as the lines that describe the recorded device are skipped. Note that the device
+// needs to support the recorded events in order for them to have any effect.
+func ReplayEvemu(r io.Reader, device EventSender) error {
+	scanner := bufio.NewScanner(r)
+	var start, replayStart time.Time
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "E:") {
+			continue
+		}
+
+		timestamp, ev, err := parseEvemuEvent(strings.TrimSpace(line[2:]))
+		if err != nil {
+			return fmt.Errorf("invalid event in line %d: %w", lineNumber, err)
+		}
+
+		if start.IsZero() {
+			start, replayStart = timestamp, time.Now()
+		} else if wait := timestamp.Sub(start) - time.Since(replayStart); wait > 0 {
+			time.Sleep(wait)
+		}
+
+		err = device.SendEvent(ev.Type, ev.Code, ev.Value)
+		if err != nil {
+			return fmt.Errorf("failed to replay event in line %d: %w", lineNumber, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read recording: %w", err)
+	}
+	return nil
+}
+
+// parseEvemuEvent parses the fields of an event line of evemu, e.g. "0.000001 0001 0110 1". The type and the
+// code are hexadecimal, the value is decimal.
+func parseEvemuEvent(fields string) (time.Time, inputEvent, error) {
+	parts := strings.Fields(fields)
+	if len(parts) != 4 {
+		return time.Time{}, inputEvent{}, fmt.Errorf("expected 4 fields, but got %d", len(parts))
+	}
+
+	secs, usecs, ok := strings.Cut(parts[0], ".")
+	if !ok {
+		return time.Time{}, inputEvent{}, fmt.Errorf("invalid timestamp %q", parts[0])
+	}
+	sec, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return time.Time{}, inputEvent{}, fmt.Errorf("invalid timestamp %q: %w", parts[0], err)
+	}
+	usec, err := strconv.ParseInt(usecs, 10, 64)
+	if err != nil {
+		return time.Time{}, inputEvent{}, fmt.Errorf("invalid timestamp %q: %w", parts[0], err)
+	}
+	evType, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return time.Time{}, inputEvent{}, fmt.Errorf("invalid type %q: %w", parts[1], err)
+	}
+	code, err := strconv.ParseUint(parts[2], 16, 16)
+	if err != nil {
+		return time.Time{}, inputEvent{}, fmt.Errorf("invalid code %q: %w", parts[2], err)
+	}
+	value, err := strconv.ParseInt(parts[3], 10, 32)
+	if err != nil {
+		return time.Time{}, inputEvent{}, fmt.Errorf("invalid value %q: %w", parts[3], err)
+	}
+
+	return time.Unix(sec, usec*int64(time.Microsecond)),
+		inputEvent{Type: uint16(evType), Code: uint16(code), Value: int32(value)}, nil
+}
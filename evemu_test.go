@@ -0,0 +1,142 @@
+package uinput
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const evemuRecording = `# EVEMU 1.3
+# Input device name: "Test Mouse"
+N: Test Mouse
+I: 0003 4711 0816 0001
+P: 00 00 00 00 00 00 00 00
+B: 00 0b 00 00 00 00 00 00 00
+A: 00 0 1920 0 0 0
+################################
+#      Waiting for events      #
+################################
+E: 0.000001 0004 0004 589825	# EV_MSC / MSC_SCAN             589825
+E: 0.000001 0001 0110 0001	# EV_KEY / BTN_LEFT             1
+E: 0.000001 0000 0000 0000	# ------------ SYN_REPORT (0) ---------- +0ms
+E: 0.030001 0002 0000 -0005	# EV_REL / REL_X                -5
+E: 0.030001 0002 0001 0003	# EV_REL / REL_Y                3
+E: 0.030001 0000 0000 0000	# ------------ SYN_REPORT (0) ---------- +30ms
+`
+
+func TestReplayEvemuEmitsRecordedEvents(t *testing.T) {
+	file, stop := recordEvents(t)
+
+	start := time.Now()
+	err := ReplayEvemu(strings.NewReader(evemuRecording), newReportBuilder(file))
+	if err != nil {
+		t.Fatalf("Failed to replay recording. Last error was: %s\n", err)
+	}
+
+	events := stop()
+	assertEvents(t, []inputEvent{
+		{Type: evMsc, Code: mscScan, Value: 0x90001},
+		{Type: evKey, Code: evMouseBtnLeft, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evRel, Code: relX, Value: -5},
+		{Type: evRel, Code: relY, Value: 3},
+		{Type: evSyn, Code: synReport},
+	}, events)
+	if elapsed := events[3].received.Sub(start); elapsed < 30*time.Millisecond {
+		t.Fatalf("Expected the original timing to be kept, but the second report was sent after %v", elapsed)
+	}
+}
+
+func TestReplayEvemuFailsOnMalformedEvent(t *testing.T) {
+	file, stop := recordEvents(t)
+	defer stop()
+
+	expected := "invalid event in line 2: invalid type \"zz\": strconv.ParseUint: parsing \"zz\": invalid syntax"
+	err := ReplayEvemu(strings.NewReader("# EVEMU 1.3\nE: 0.000001 zz 0000 0\n"), newReportBuilder(file))
+	if err == nil || err.Error() != expected {
+		t.Fatalf("Expected: %s\nActual: %v", expected, err)
+	}
+}
+
+func TestReplayEvemuToDevice(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse, err := CreateMouseWriter(file, []byte("Test Mouse"))
+	if err != nil {
+		t.Fatalf("Failed to create the dry run mouse. Last error was: %s\n", err)
+	}
+
+	sender, ok := mouse.(EventSender)
+	if !ok {
+		t.Fatalf("Expected mouse to implement EventSender")
+	}
+	err = ReplayEvemu(strings.NewReader("E: 0.000001 0002 0008 0001\nE: 0.000001 0000 0000 0000\n"), sender)
+	if err != nil {
+		t.Fatalf("Failed to replay recording. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evRel, Code: relWheel, Value: 1},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+// rawDevice combines the interfaces for low-level access that all devices need to implement.
+type rawDevice interface {
+	EventSender
+	RawEventWriter
+	Flusher
+}
+
+var (
+	_ rawDevice = (*vKeyboard)(nil)
+	_ rawDevice = vMouse{}
+	_ rawDevice = (*vTouchPad)(nil)
+	_ rawDevice = vDial{}
+	_ rawDevice = vGamepad{}
+	_ rawDevice = vMultiTouch{}
+	_ rawDevice = vScrollDevice{}
+	_ rawDevice = vSpaceMouse{}
+	_ rawDevice = vStylus{}
+	_ rawDevice = vHybridPointer{}
+	_ rawDevice = vTouchScreen{}
+	_ rawDevice = vButtonPad{}
+	_ rawDevice = vSlider{}
+	_ rawDevice = vRelativeDevice{}
+)
+
+func TestWriteEventNoSyncIsNotFollowedBySynReport(t *testing.T) {
+	file, stop := recordEvents(t)
+	report := newReportBuilder(file)
+
+	err := report.WriteEventNoSync(evRel, relX, 5)
+	if err != nil {
+		t.Fatalf("Failed to write event. Last error was: %s\n", err)
+	}
+	assertEvents(t, []inputEvent{
+		{Type: evRel, Code: relX, Value: 5},
+	}, stop())
+}
+
+func TestSyncCompletesReportStartedWithoutSync(t *testing.T) {
+	file, stop := recordEvents(t)
+	report := newReportBuilder(file)
+
+	err := report.WriteEventNoSync(evRel, relX, 5)
+	if err != nil {
+		t.Fatalf("Failed to write event. Last error was: %s\n", err)
+	}
+	err = report.WriteEventNoSync(evRel, relY, -3)
+	if err != nil {
+		t.Fatalf("Failed to write event. Last error was: %s\n", err)
+	}
+	err = report.Sync()
+	if err != nil {
+		t.Fatalf("Failed to sync. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evRel, Code: relX, Value: 5},
+		{Type: evRel, Code: relY, Value: -3},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
@@ -0,0 +1,281 @@
+package uinput
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// FFEventType describes the kind of a force feedback request that the host has sent to a device.
+type FFEventType int
+
+const (
+	// FFUpload is a request to upload a new (or update an existing) force feedback effect.
+	FFUpload FFEventType = iota
+	// FFErase is a request to erase a previously uploaded effect.
+	FFErase
+	// FFPlay is a request to start playing an uploaded effect.
+	FFPlay
+	// FFStop is a request to stop playing an effect.
+	FFStop
+)
+
+// An FFEvent is a force feedback request that the host has sent to a device, e.g. because a game wants the
+// gamepad to rumble.
+type FFEvent struct {
+	Type FFEventType
+	// EffectID is the id of the effect that the request refers to.
+	EffectID int16
+	// StrongMagnitude and WeakMagnitude are the magnitudes of the heavy and the light motor of an uploaded rumble
+	// effect (FFUpload only).
+	StrongMagnitude uint16
+	WeakMagnitude   uint16
+	// Duration is the time an uploaded effect lasts when played, or zero if it lasts until stopped (FFUpload only).
+	Duration time.Duration
+	// Count is the number of times that the effect should be played (FFPlay only).
+	Count int32
+}
+
+// A ForceFeedbackReader receives the force feedback requests that the host sends to a device. All gamepads of
+// this package (except for the noop gamepad) implement ForceFeedbackReader, but only gamepads that have been
+// created using WithForceFeedback receive requests.
+type ForceFeedbackReader interface {
+	// ReadForceFeedback starts reading the force feedback requests in the background, and returns an FFReader that
+	// delivers them. Upload and erase requests are answered as soon as they are read, since the host waits for the
+	// answer; requests that arrive while no reader runs time out instead. Starting another reader stops the
+	// previous one. An error is returned if the device has not been created using WithForceFeedback.
+	ReadForceFeedback() (*FFReader, error)
+}
+
+// An FFReader reads force feedback requests from a device and delivers them on a channel. Reading blocks on the
+// device file without any polling, so the requests are delivered as soon as they arrive.
+type FFReader struct {
+	deviceFile *os.File
+	// wake is a pipe whose write end is closed by Stop, which unblocks the reader while it waits for a request
+	wake     *os.File
+	stopWake *os.File
+	events   chan FFEvent
+	stop     chan struct{}
+	finished chan struct{}
+	stopOnce sync.Once
+	err      error
+}
+
+// ffState holds the force feedback reader of a device. It is shared by all copies of the device.
+type ffState struct {
+	mu     sync.Mutex
+	reader *FFReader
+}
+
+// ReadForceFeedback starts reading the force feedback requests of the gamepad (see ForceFeedbackReader).
+func (vg vGamepad) ReadForceFeedback() (*FFReader, error) {
+	if vg.ff == nil {
+		return nil, errors.New("force feedback is not enabled (see WithForceFeedback)")
+	}
+	return vg.ff.start(vg.deviceFile)
+}
+
+// start stops the previous reader, if any, and starts a new one.
+func (s *ffState) start(deviceFile *os.File) (*FFReader, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.reader != nil {
+		_ = s.reader.Stop()
+		s.reader = nil
+	}
+
+	reader, err := newFFReader(deviceFile)
+	if err != nil {
+		return nil, err
+	}
+	s.reader = reader
+	return reader, nil
+}
+
+// stop stops the reader, if any. It is called when the device is closed.
+func (s *ffState) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.reader != nil {
+		_ = s.reader.Stop()
+		s.reader = nil
+	}
+}
+
+func newFFReader(deviceFile *os.File) (*FFReader, error) {
+	wake, stopWake, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create force feedback reader: %w", err)
+	}
+	ffr := &FFReader{
+		deviceFile: deviceFile,
+		wake:       wake,
+		stopWake:   stopWake,
+		events:     make(chan FFEvent),
+		stop:       make(chan struct{}),
+		finished:   make(chan struct{}),
+	}
+	go ffr.read(int(deviceFile.Fd()), int(wake.Fd()))
+	return ffr, nil
+}
+
+// Events returns the channel on which the requests are delivered. The channel is closed once reading stops.
+func (ffr *FFReader) Events() <-chan FFEvent {
+	return ffr.events
+}
+
+// Stop unblocks the reader and waits until reading has stopped. The device is not affected.
+func (ffr *FFReader) Stop() error {
+	var err error
+	ffr.stopOnce.Do(func() {
+		close(ffr.stop)
+		err = ffr.stopWake.Close()
+	})
+	<-ffr.finished
+	return err
+}
+
+// Err returns the error that caused reading to stop, if reading did not stop due to a call to Stop.
+func (ffr *FFReader) Err() error {
+	<-ffr.finished
+	return ffr.err
+}
+
+func (ffr *FFReader) read(fd int, wake int) {
+	defer close(ffr.finished)
+	defer close(ffr.events)
+	defer ffr.wake.Close()
+
+	for {
+		readable, err := waitReadable(fd, wake)
+		if err != nil {
+			ffr.err = fmt.Errorf("failed to wait for force feedback requests: %w", err)
+			return
+		}
+		if !readable {
+			return
+		}
+
+		var ev inputEvent
+		err = binary.Read(ffr.deviceFile, binary.LittleEndian, &ev)
+		if err != nil {
+			ffr.err = err
+			return
+		}
+
+		ffEvent, ok := answerFFRequest(ffr.deviceFile, ev)
+		if !ok {
+			continue
+		}
+		select {
+		case ffr.events <- ffEvent:
+		case <-ffr.stop:
+			return
+		}
+	}
+}
+
+// answerFFRequest converts the given input event into a force feedback request, answering upload and erase
+// requests on the way. Events that are not related to force feedback, like changes of the gain, are ignored, as
+// well as requests that can not be answered, e.g. because they have timed out already.
+func answerFFRequest(deviceFile *os.File, ev inputEvent) (FFEvent, bool) {
+	switch {
+	case ev.Type == evUinput && ev.Code == uiFFUpload:
+		upload := uinputFFUpload{RequestID: uint32(ev.Value)}
+		if ffUploadIoctl(deviceFile, uiBeginFFUpload, &upload) != nil {
+			return FFEvent{}, false
+		}
+		upload.Retval = 0
+		if ffUploadIoctl(deviceFile, uiEndFFUpload, &upload) != nil {
+			return FFEvent{}, false
+		}
+		return uploadEvent(upload.Effect), true
+	case ev.Type == evUinput && ev.Code == uiFFErase:
+		erase := uinputFFErase{RequestID: uint32(ev.Value)}
+		if ffEraseIoctl(deviceFile, uiBeginFFErase, &erase) != nil {
+			return FFEvent{}, false
+		}
+		erase.Retval = 0
+		if ffEraseIoctl(deviceFile, uiEndFFErase, &erase) != nil {
+			return FFEvent{}, false
+		}
+		return FFEvent{Type: FFErase, EffectID: int16(erase.EffectID)}, true
+	case ev.Type == evFF && ev.Code < ffGain && ev.Value > 0:
+		return FFEvent{Type: FFPlay, EffectID: int16(ev.Code), Count: ev.Value}, true
+	case ev.Type == evFF && ev.Code < ffGain:
+		return FFEvent{Type: FFStop, EffectID: int16(ev.Code)}, true
+	}
+	return FFEvent{}, false
+}
+
+// uploadEvent returns the request for the given uploaded effect. Only rumble effects are registered, so the
+// parameters of other effects are never uploaded.
+func uploadEvent(effect ffEffect) FFEvent {
+	ev := FFEvent{
+		Type:     FFUpload,
+		EffectID: effect.ID,
+		Duration: time.Duration(effect.Replay.Length) * time.Millisecond,
+	}
+	if effect.Type == ffRumble {
+		ev.StrongMagnitude = binary.LittleEndian.Uint16(effect.U.Data[0:])
+		ev.WeakMagnitude = binary.LittleEndian.Uint16(effect.U.Data[2:])
+	}
+	return ev
+}
+
+// ffUploadIoctl and ffEraseIoctl issue the ioctls that answer upload and erase requests. They are variables in
+// order to be replaced in tests, which could not access the requests through the pointer passed to ioctl.
+var ffUploadIoctl = func(deviceFile *os.File, cmd uintptr, upload *uinputFFUpload) error {
+	return ioctl(deviceFile, cmd, uintptr(unsafe.Pointer(upload)))
+}
+
+var ffEraseIoctl = func(deviceFile *os.File, cmd uintptr, erase *uinputFFErase) error {
+	return ioctl(deviceFile, cmd, uintptr(unsafe.Pointer(erase)))
+}
+
+// fdBits is the number of file descriptors per element of syscall.FdSet, which depends on the architecture.
+const fdBits = int(8 * unsafe.Sizeof(syscall.FdSet{}.Bits[0]))
+
+// waitReadable blocks until the file descriptor fd is readable, or until wake is readable, which is the case once
+// the write end of its pipe has been closed. It reports whether fd is readable and wake is not.
+func waitReadable(fd int, wake int) (bool, error) {
+	nfd := fd
+	if wake > nfd {
+		nfd = wake
+	}
+	if nfd >= len(syscall.FdSet{}.Bits)*fdBits {
+		return false, fmt.Errorf("file descriptor %d exceeds the limit of select", nfd)
+	}
+
+	for {
+		var set syscall.FdSet
+		set.Bits[fd/fdBits] |= 1 << (fd % fdBits)
+		set.Bits[wake/fdBits] |= 1 << (wake % fdBits)
+		_, err := syscall.Select(nfd+1, &set, nil, nil, nil)
+		if errors.Is(err, syscall.EINTR) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		return set.Bits[wake/fdBits]&(1<<(wake%fdBits)) == 0, nil
+	}
+}
+
+// registerForceFeedback registers rumble effects, which is the only kind of force feedback that gamepads support.
+func registerForceFeedback(deviceFile *os.File) error {
+	err := ioctl(deviceFile, uiSetEvBit, evFF)
+	if err != nil {
+		return fmt.Errorf("failed to register force feedback events: %w", err)
+	}
+	err = ioctl(deviceFile, uiSetFFBit, ffRumble)
+	if err != nil {
+		return fmt.Errorf("failed to register rumble effects: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package uinput
+
+import (
+	"encoding/binary"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+var _ ForceFeedbackReader = vGamepad{}
+
+// ffGamepad returns a gamepad with force feedback enabled that reads its requests from a pipe, along with the
+// write end of the pipe, which takes the role of the kernel.
+func ffGamepad(t *testing.T) (vGamepad, *os.File) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to setup test. Unable to create pipe: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = w.Close()
+		_ = r.Close()
+	})
+	return vGamepad{deviceBase: deviceBase{deviceFile: r}, ff: &ffState{}}, w
+}
+
+// ffAnswer is an answer to an upload or erase request that has been issued using an ioctl.
+type ffAnswer struct {
+	cmd       uintptr
+	requestID uint32
+	retval    int32
+}
+
+// fakeFFIoctls replaces the ioctls that answer force feedback requests. The kernel's part of the upload is to
+// provide the given effect. The returned function restores the ioctls.
+func fakeFFIoctls(effect ffEffect, effectID uint32) (*[]ffAnswer, func()) {
+	originalUpload, originalErase := ffUploadIoctl, ffEraseIoctl
+	answers := &[]ffAnswer{}
+	ffUploadIoctl = func(deviceFile *os.File, cmd uintptr, upload *uinputFFUpload) error {
+		if cmd == uiBeginFFUpload {
+			upload.Effect = effect
+		}
+		*answers = append(*answers, ffAnswer{cmd: cmd, requestID: upload.RequestID, retval: upload.Retval})
+		return nil
+	}
+	ffEraseIoctl = func(deviceFile *os.File, cmd uintptr, erase *uinputFFErase) error {
+		if cmd == uiBeginFFErase {
+			erase.EffectID = effectID
+		}
+		*answers = append(*answers, ffAnswer{cmd: cmd, requestID: erase.RequestID, retval: erase.Retval})
+		return nil
+	}
+	return answers, func() { ffUploadIoctl, ffEraseIoctl = originalUpload, originalErase }
+}
+
+func nextFFEvent(t *testing.T, reader *FFReader) FFEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-reader.Events():
+		if !ok {
+			t.Fatalf("Expected a force feedback event, but the channel was closed.")
+		}
+		return ev
+	case <-time.After(time.Second):
+		t.Fatalf("Expected a force feedback event, but none was delivered.")
+	}
+	return FFEvent{}
+}
+
+func TestFFStructsMatchIoctlSizes(t *testing.T) {
+	// the size of ff_effect is 48 bytes on 64-bit architectures, and 44 bytes on 32-bit ones
+	if size := unsafe.Sizeof(ffEffect{}); size != 40+unsafe.Sizeof(uintptr(0)) {
+		t.Fatalf("Expected ff_effect to have %d bytes, but the struct has %d", 40+unsafe.Sizeof(uintptr(0)), size)
+	}
+	for cmd, size := range map[uintptr]uintptr{
+		uiBeginFFUpload: unsafe.Sizeof(uinputFFUpload{}),
+		uiEndFFUpload:   unsafe.Sizeof(uinputFFUpload{}),
+		uiBeginFFErase:  unsafe.Sizeof(uinputFFErase{}),
+		uiEndFFErase:    unsafe.Sizeof(uinputFFErase{}),
+	} {
+		if encoded := (cmd >> 16) & 0x3fff; encoded != size {
+			t.Fatalf("Expected size %d for request %#x, but the struct has %d bytes", encoded, cmd, size)
+		}
+	}
+}
+
+func TestFFReaderAnswersAndDeliversRequests(t *testing.T) {
+	var effect ffEffect
+	effect.Type = ffRumble
+	effect.ID = 3
+	effect.Replay.Length = 500
+	binary.LittleEndian.PutUint16(effect.U.Data[0:], 0x8000)
+	binary.LittleEndian.PutUint16(effect.U.Data[2:], 0x4000)
+	answers, restore := fakeFFIoctls(effect, 3)
+	defer restore()
+
+	gamepad, w := ffGamepad(t)
+	reader, err := gamepad.ReadForceFeedback()
+	if err != nil {
+		t.Fatalf("Failed to read force feedback. Last error was: %s\n", err)
+	}
+	defer reader.Stop()
+
+	writeRequest(t, w, inputEvent{Type: evUinput, Code: uiFFUpload, Value: 7})
+	writeRequest(t, w, inputEvent{Type: evSyn, Code: synReport})
+	writeRequest(t, w, inputEvent{Type: evFF, Code: ffGain, Value: 0xffff})
+	writeRequest(t, w, inputEvent{Type: evFF, Code: 3, Value: 2})
+	writeRequest(t, w, inputEvent{Type: evFF, Code: 3, Value: 0})
+	writeRequest(t, w, inputEvent{Type: evUinput, Code: uiFFErase, Value: 8})
+
+	expected := []FFEvent{
+		{Type: FFUpload, EffectID: 3, StrongMagnitude: 0x8000, WeakMagnitude: 0x4000, Duration: 500 * time.Millisecond},
+		{Type: FFPlay, EffectID: 3, Count: 2},
+		{Type: FFStop, EffectID: 3},
+		{Type: FFErase, EffectID: 3},
+	}
+	for _, e := range expected {
+		if actual := nextFFEvent(t, reader); actual != e {
+			t.Fatalf("Expected %+v, but got %+v", e, actual)
+		}
+	}
+
+	expectedAnswers := []ffAnswer{
+		{cmd: uiBeginFFUpload, requestID: 7},
+		{cmd: uiEndFFUpload, requestID: 7},
+		{cmd: uiBeginFFErase, requestID: 8},
+		{cmd: uiEndFFErase, requestID: 8},
+	}
+	if !reflect.DeepEqual(*answers, expectedAnswers) {
+		t.Fatalf("Expected: %+v\nActual: %+v", expectedAnswers, *answers)
+	}
+}
+
+func TestFFReaderStopUnblocksReading(t *testing.T) {
+	gamepad, _ := ffGamepad(t)
+	reader, err := gamepad.ReadForceFeedback()
+	if err != nil {
+		t.Fatalf("Failed to read force feedback. Last error was: %s\n", err)
+	}
+
+	err = reader.Stop()
+	if err != nil {
+		t.Fatalf("Failed to stop reader. Last error was: %s\n", err)
+	}
+	if _, ok := <-reader.Events(); ok {
+		t.Fatalf("Expected events channel to be closed after Stop.")
+	}
+	if err := reader.Err(); err != nil {
+		t.Fatalf("Expected no error after Stop, but got: %v", err)
+	}
+}
+
+func TestFFReaderStopsIfNoOneReceives(t *testing.T) {
+	_, restore := fakeFFIoctls(ffEffect{}, 0)
+	defer restore()
+	gamepad, w := ffGamepad(t)
+	reader, err := gamepad.ReadForceFeedback()
+	if err != nil {
+		t.Fatalf("Failed to read force feedback. Last error was: %s\n", err)
+	}
+
+	writeRequest(t, w, inputEvent{Type: evUinput, Code: uiFFUpload, Value: 1})
+
+	done := make(chan struct{})
+	go func() {
+		_ = reader.Stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected Stop to return even though the pending event was not received.")
+	}
+}
+
+func TestReadForceFeedbackStopsPreviousReader(t *testing.T) {
+	gamepad, _ := ffGamepad(t)
+	previous, err := gamepad.ReadForceFeedback()
+	if err != nil {
+		t.Fatalf("Failed to read force feedback. Last error was: %s\n", err)
+	}
+	reader, err := gamepad.ReadForceFeedback()
+	if err != nil {
+		t.Fatalf("Failed to read force feedback. Last error was: %s\n", err)
+	}
+	defer reader.Stop()
+
+	if _, ok := <-previous.Events(); ok {
+		t.Fatalf("Expected the previous reader to be stopped.")
+	}
+}
+
+func TestReadForceFeedbackFailsIfNotEnabled(t *testing.T) {
+	_, err := vGamepad{}.ReadForceFeedback()
+	if err == nil {
+		t.Fatalf("Expected reading force feedback to fail without WithForceFeedback, but got no error.")
+	}
+}
+
+func TestGamepadRegistersForceFeedback(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	gamepad, err := CreateGamepad(path, []byte("Test Gamepad"), 0x4711, 0x0815, WithForceFeedback(4))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual gamepad. Last error was: %s\n", err)
+	}
+	defer gamepad.Close()
+
+	if evBits := registeredCodes(*calls, uiSetEvBit); !reflect.DeepEqual(evBits, []uintptr{evKey, evAbs, evFF}) {
+		t.Fatalf("Expected EV_KEY, EV_ABS and EV_FF to be registered, but got %v", evBits)
+	}
+	if ffBits := registeredCodes(*calls, uiSetFFBit); !reflect.DeepEqual(ffBits, []uintptr{ffRumble}) {
+		t.Fatalf("Expected FF_RUMBLE to be registered, but got %v", ffBits)
+	}
+	if effectsMax := readUserDev(t, path).EffectsMax; effectsMax != 4 {
+		t.Fatalf("Expected up to 4 effects, but got %d", effectsMax)
+	}
+}
+
+func TestGamepadCreationFailsOnInvalidEffectCount(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	_, restore := fakeIoctl(nil)
+	defer restore()
+
+	for _, effects := range []int{0, ffGain + 1} {
+		_, err := CreateGamepad(path, []byte("Test Gamepad"), 0x4711, 0x0815, WithForceFeedback(effects))
+		if err == nil {
+			t.Fatalf("Expected creating a gamepad with %d effects to fail, but got no error.", effects)
+		}
+	}
+}
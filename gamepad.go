@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"sort"
 )
 
 const MaximumAxisValue = 32767
@@ -26,6 +28,105 @@ const (
 	Release
 )
 
+// Bus types that may be passed to WithGamepadID, as defined in input.h.
+const (
+	BusUSB       uint16 = busUsb
+	BusBluetooth uint16 = 0x05
+	BusVirtual   uint16 = 0x06
+)
+
+// GamepadPreset selects the layout of a well known controller (see CreateGamepadPreset).
+type GamepadPreset int
+
+const (
+	// PresetXbox360 mimics a wired Xbox 360 controller as exposed by the xpad driver.
+	PresetXbox360 GamepadPreset = iota + 1
+	// PresetDualShock4 mimics a Sony DualShock 4 (second revision) as exposed by the hid-playstation driver.
+	PresetDualShock4
+)
+
+// gamepadAxis describes an absolute axis of a gamepad along with its range.
+// If min and max are equal, the range of MaximumAxisValue is assumed when moving the axis.
+type gamepadAxis struct {
+	code uint16
+	min  int32
+	max  int32
+	fuzz int32
+	flat int32
+}
+
+// rest returns the resting position of the axis: triggers (ABS_Z and ABS_RZ) rest at their minimum, while sticks
+// and hats rest at their center.
+func (axis gamepadAxis) rest() int32 {
+	if axis.min == axis.max {
+		// the range of MaximumAxisValue is assumed, which is centered at zero
+		return 0
+	}
+	if axis.code == absZ || axis.code == absRZ {
+		return axis.min
+	}
+	return axisCenter(axis.min, axis.max)
+}
+
+// gamepadLayout defines the identity and the capabilities of a gamepad device.
+type gamepadLayout struct {
+	bustype uint16
+	vendor  uint16
+	product uint16
+	version uint16
+	buttons []uint16
+	axes    []gamepadAxis
+}
+
+// gamepadPresets holds the layouts of the available presets. These resemble what the respective kernel
+// drivers register for the original controllers, so that games and libraries like SDL identify them correctly.
+var gamepadPresets = map[GamepadPreset]gamepadLayout{
+	PresetXbox360: {
+		bustype: busUsb,
+		vendor:  0x045e,
+		product: 0x028e,
+		version: 0x0114,
+		buttons: []uint16{
+			ButtonSouth, ButtonEast, ButtonNorth, ButtonWest,
+			ButtonBumperLeft, ButtonBumperRight,
+			ButtonSelect, ButtonStart, ButtonMode,
+			ButtonThumbLeft, ButtonThumbRight,
+		},
+		axes: []gamepadAxis{
+			{code: absX, min: -32768, max: 32767, fuzz: 16, flat: 128},
+			{code: absY, min: -32768, max: 32767, fuzz: 16, flat: 128},
+			{code: absZ, min: 0, max: 255},
+			{code: absRX, min: -32768, max: 32767, fuzz: 16, flat: 128},
+			{code: absRY, min: -32768, max: 32767, fuzz: 16, flat: 128},
+			{code: absRZ, min: 0, max: 255},
+			{code: absHat0X, min: -1, max: 1},
+			{code: absHat0Y, min: -1, max: 1},
+		},
+	},
+	PresetDualShock4: {
+		bustype: busUsb,
+		vendor:  0x054c,
+		product: 0x09cc,
+		version: 0x8111,
+		buttons: []uint16{
+			ButtonSouth, ButtonEast, ButtonNorth, ButtonWest,
+			ButtonBumperLeft, ButtonBumperRight, ButtonTriggerLeft, ButtonTriggerRight,
+			ButtonSelect, ButtonStart, ButtonMode,
+			ButtonThumbLeft, ButtonThumbRight,
+		},
+		axes: []gamepadAxis{
+			{code: absX, min: 0, max: 255},
+			{code: absY, min: 0, max: 255},
+			{code: absZ, min: 0, max: 255},
+			{code: absRX, min: 0, max: 255},
+			{code: absRY, min: 0, max: 255},
+			{code: absRZ, min: 0, max: 255},
+			{code: absHat0X, min: -1, max: 1},
+			{code: absHat0Y, min: -1, max: 1},
+		},
+	},
+}
+
 // Gamepad is a hybrid key / absolute change event output device.
 // It used to enable a program to simulate gamepad input events.
 type Gamepad interface {
@@ -61,17 +162,21 @@ type Gamepad interface {
 	// HatRelease will issue a hat-release event in the given direction
 	HatRelease(direction HatDirection) error
 
+	// Close will reset the gamepad like Reset (see Resetter) before the device is destroyed.
 	io.Closer
 }
 
 type vGamepad struct {
-	name       []byte
-	deviceFile *os.File
+	deviceBase
+	axes       map[uint16]gamepadAxis
+	axisPolicy AxisPolicy
+	// ff is set if force feedback is enabled (see WithForceFeedback)
+	ff *ffState
 }
 
 // CreateGamepad will create a new gamepad using the given uinput
 // device path of the uinput device.
-func CreateGamepad(path string, name []byte, vendor uint16, product uint16) (Gamepad, error) { // TODO: Consider moving this to a generic function that works for all devices
+func CreateGamepad(path string, name []byte, vendor uint16, product uint16, opts ...Option) (Gamepad, error) { // TODO: Consider moving this to a generic function that works for all devices
 	err := validateDevicePath(path)
 	if err != nil {
 		return nil, err
@@ -81,12 +186,58 @@ func CreateGamepad(path string, name []byte, vendor uint16, product uint16) (Gam
 		return nil, err
 	}
 
-	fd, err := createVGamepadDevice(path, name, vendor, product)
+	return createGamepadFromLayout(path, name, defaultGamepadLayout(vendor, product), newDeviceOptions(opts))
+}
+
+// CreateGamepadPreset will create a new gamepad that mimics the given, well known controller. Vendor and
+// product ID as well as the registered buttons and axes (including their ranges) are set according to the preset.
+// Stick movements are mapped onto the axis ranges of the preset.
+func CreateGamepadPreset(path string, name []byte, preset GamepadPreset, opts ...Option) (Gamepad, error) {
+	layout, ok := gamepadPresets[preset]
+	if !ok {
+		return nil, fmt.Errorf("unknown gamepad preset %d", preset)
+	}
+	err := validateDevicePath(path)
+	if err != nil {
+		return nil, err
+	}
+	err = validateUinputName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return createGamepadFromLayout(path, name, layout, newDeviceOptions(opts))
+}
+
+func createGamepadFromLayout(path string, name []byte, layout gamepadLayout, options deviceOptions) (Gamepad, error) {
+	if id := options.gamepadID; id != nil {
+		layout.bustype, layout.vendor, layout.product, layout.version = id.Bustype, id.Vendor, id.Product, id.Version
+	}
+	if effects := options.ffEffects; effects != nil && (*effects < 1 || *effects > ffGain) {
+		return nil, fmt.Errorf("invalid number of force feedback effects %d (must be between 1 and %d)", *effects, ffGain)
+	}
+	fd, err := createVGamepadDevice(path, name, layout, options)
 	if err != nil {
 		return nil, err
 	}
 
-	return vGamepad{name: name, deviceFile: fd}, nil
+	axes := make(map[uint16]gamepadAxis, len(layout.axes))
+	for _, axis := range layout.axes {
+		axes[axis.code] = axis
+	}
+	vg := vGamepad{
+		deviceBase: deviceBase{
+			name:       name,
+			deviceFile: fd,
+			report:     newReportBuilderWithOptions(fd, name, options.withoutReportInterval()),
+		},
+		axes:       axes,
+		axisPolicy: options.axisPolicy,
+	}
+	if options.ffEffects != nil {
+		vg.ff = &ffState{}
+	}
+	return vg, nil
 }
 
 func (vg vGamepad) ButtonPress(key int) error {
@@ -102,11 +253,11 @@ func (vg vGamepad) ButtonPress(key int) error {
 }
 
 func (vg vGamepad) ButtonDown(key int) error {
-	return sendBtnEvent(vg.deviceFile, []int{key}, btnStatePressed)
+	return sendBtnEvent(vg.report, []int{key}, btnStatePressed)
 }
 
 func (vg vGamepad) ButtonUp(key int) error {
-	return sendBtnEvent(vg.deviceFile, []int{key}, btnStateReleased)
+	return sendBtnEvent(vg.report, []int{key}, btnStateReleased)
 }
 
 func (vg vGamepad) LeftStickMoveX(value float32) error {
@@ -150,45 +301,42 @@ func (vg vGamepad) HatRelease(direction HatDirection) error {
 }
 
 func (vg vGamepad) sendStickAxisEvent(absCode uint16, value float32) error {
+	axisValue, err := vg.axisValue(absCode, value)
+	if err != nil {
+		return err
+	}
 	ev := inputEvent{
 		Type:  evAbs,
 		Code:  absCode,
-		Value: denormalizeInput(value),
+		Value: axisValue,
 	}
 
-	buf, err := inputEventToBuffer(ev)
+	err = vg.report.send(ev)
 	if err != nil {
-		return fmt.Errorf("writing abs stick event failed: %v", err)
+		return fmt.Errorf("failed to write abs stick event to device file: %w", err)
 	}
-
-	_, err = vg.deviceFile.Write(buf)
-	if err != nil {
-		return fmt.Errorf("failed to write abs stick event to device file: %v", err)
-	}
-
-	return syncEvents(vg.deviceFile)
+	return nil
 }
 
 func (vg vGamepad) sendStickEvent(values map[uint16]float32) error {
+	events := make([]inputEvent, 0, len(values))
 	for code, value := range values {
-		ev := inputEvent{
-			Type:  evAbs,
-			Code:  code,
-			Value: denormalizeInput(value),
-		}
-
-		buf, err := inputEventToBuffer(ev)
-		if err != nil {
-			return fmt.Errorf("writing abs stick event failed: %v", err)
-		}
-
-		_, err = vg.deviceFile.Write(buf)
+		axisValue, err := vg.axisValue(code, value)
 		if err != nil {
-			return fmt.Errorf("failed to write abs stick event to device file: %v", err)
+			return err
 		}
+		events = append(events, inputEvent{
+			Type:  evAbs,
+			Code:  code,
+			Value: axisValue,
+		})
 	}
 
-	return syncEvents(vg.deviceFile)
+	err := vg.report.send(events...)
+	if err != nil {
+		return fmt.Errorf("failed to write abs stick event to device file: %w", err)
+	}
+	return nil
 }
 
 func (vg vGamepad) sendHatEvent(direction HatDirection, action HatAction) error {
@@ -232,24 +380,44 @@ func (vg vGamepad) sendHatEvent(direction HatDirection, action HatAction) error
 		Value: value,
 	}
 
-	buf, err := inputEventToBuffer(ev)
+	err := vg.report.send(ev)
 	if err != nil {
-		return fmt.Errorf("writing abs stick event failed: %v", err)
+		return fmt.Errorf("failed to write abs stick event to device file: %w", err)
 	}
+	return nil
+}
 
-	_, err = vg.deviceFile.Write(buf)
-	if err != nil {
-		return fmt.Errorf("failed to write abs stick event to device file: %v", err)
+// Reset releases all buttons, centers the sticks and hats and releases the triggers (see Resetter).
+func (vg vGamepad) Reset() error {
+	codes := make([]uint16, 0, len(vg.axes))
+	for code := range vg.axes {
+		codes = append(codes, code)
 	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
 
-	return syncEvents(vg.deviceFile)
+	events := make([]inputEvent, 0, len(codes))
+	for _, code := range codes {
+		events = append(events, inputEvent{Type: evAbs, Code: code, Value: vg.axes[code].rest()})
+	}
+	return vg.report.reset(events...)
 }
 
+// Close re-centers all axes and releases all buttons (see Reset) before the device is destroyed, since the host
+// may otherwise keep the last state of the device, e.g. a stick that drifts off-center. The device is destroyed
+// even if the reset fails.
 func (vg vGamepad) Close() error {
-	return closeDevice(vg.deviceFile)
+	if vg.ff != nil {
+		vg.ff.stop()
+	}
+	err := vg.Reset()
+	if err != nil {
+		err = fmt.Errorf("failed to re-center gamepad: %w", err)
+	}
+	return errors.Join(err, closeDeviceWithReport(vg.report, vg.deviceFile))
 }
 
-func createVGamepadDevice(path string, name []byte, vendor uint16, product uint16) (fd *os.File, err error) {
+// defaultGamepadLayout returns the layout used by CreateGamepad.
+func defaultGamepadLayout(vendor uint16, product uint16) gamepadLayout {
 	// This array is needed to register the event keys for the gamepad device.
 	keys := []uint16{
 		ButtonGamepad,
@@ -278,34 +446,43 @@ func createVGamepadDevice(path string, name []byte, vendor uint16, product uint1
 	}
 
 	// absEvents is for the absolute events for the gamepad device.
-	absEvents := []uint16{
-		absX,
-		absY,
-		absZ,
-		absRX,
-		absRY,
-		absRZ,
-		absHat0X,
-		absHat0Y,
-	}
-
-	deviceFile, err := createDeviceFile(path)
+	absEvents := []gamepadAxis{
+		{code: absX},
+		{code: absY},
+		{code: absZ},
+		{code: absRX},
+		{code: absRY},
+		{code: absRZ},
+		{code: absHat0X},
+		{code: absHat0Y},
+	}
+
+	return gamepadLayout{bustype: busUsb, vendor: vendor, product: product, version: 1, buttons: keys, axes: absEvents}
+}
+
+func createVGamepadDevice(path string, name []byte, layout gamepadLayout, options deviceOptions) (fd *os.File, err error) {
+	var deviceFile *os.File
+	if options.ffEffects != nil {
+		deviceFile, err = createReadableDeviceFile(path)
+	} else {
+		deviceFile, err = createDeviceFile(path)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create virtual gamepad device: %v", err)
+		return nil, fmt.Errorf("failed to create virtual gamepad device: %w", err)
 	}
 
 	// register button events
 	err = registerDevice(deviceFile, uintptr(evKey))
 	if err != nil {
 		_ = deviceFile.Close()
-		return nil, fmt.Errorf("failed to register virtual gamepad device: %v", err)
+		return nil, fmt.Errorf("failed to register virtual gamepad device: %w", err)
 	}
 
-	for _, code := range keys {
+	for _, code := range layout.buttons {
 		err = ioctl(deviceFile, uiSetKeyBit, uintptr(code))
 		if err != nil {
 			_ = deviceFile.Close()
-			return nil, fmt.Errorf("failed to register key number %d: %v", code, err)
+			return nil, fmt.Errorf("failed to register key number %d: %w", code, err)
 		}
 	}
 
@@ -313,25 +490,70 @@ func createVGamepadDevice(path string, name []byte, vendor uint16, product uint1
 	err = registerDevice(deviceFile, uintptr(evAbs))
 	if err != nil {
 		_ = deviceFile.Close()
-		return nil, fmt.Errorf("failed to register absolute event input device: %v", err)
+		return nil, fmt.Errorf("failed to register absolute event input device: %w", err)
+	}
+
+	var absMin, absMax, absFuzz, absFlat [absSize]int32
+	for _, axis := range layout.axes {
+		if int(axis.code) >= absSize {
+			_ = deviceFile.Close()
+			return nil, fmt.Errorf("unsupported absolute axis code %d (must be lower than %d)", axis.code, absSize)
+		}
+		err = ioctl(deviceFile, uiSetAbsBit, uintptr(axis.code))
+		if err != nil {
+			_ = deviceFile.Close()
+			return nil, fmt.Errorf("failed to register absolute event %v: %w", axis.code, err)
+		}
+		absMin[axis.code] = axis.min
+		absMax[axis.code] = axis.max
+		absFuzz[axis.code] = axis.fuzz
+		absFlat[axis.code] = axis.flat
 	}
 
-	for _, event := range absEvents {
-		err = ioctl(deviceFile, uiSetAbsBit, uintptr(event))
+	var effectsMax uint32
+	if options.ffEffects != nil {
+		err = registerForceFeedback(deviceFile)
 		if err != nil {
 			_ = deviceFile.Close()
-			return nil, fmt.Errorf("failed to register absolute event %v: %v", event, err)
+			return nil, err
 		}
+		effectsMax = uint32(*options.ffEffects)
 	}
 
 	return createUsbDevice(deviceFile,
 		uinputUserDev{
 			Name: toUinputName(name),
 			ID: inputID{
-				Bustype: busUsb,
-				Vendor:  vendor,
-				Product: product,
-				Version: 1}})
+				Bustype: layout.bustype,
+				Vendor:  layout.vendor,
+				Product: layout.product,
+				Version: layout.version},
+			EffectsMax: effectsMax,
+			Absmin:     absMin,
+			Absmax:     absMax,
+			Absfuzz:    absFuzz,
+			Absflat:    absFlat},
+		options)
+}
+
+// axisValue converts a normalized value (-1.0:1.0) into an event value within the range of the given axis.
+// The axis policy of the gamepad is applied to the resulting value.
+func (vg vGamepad) axisValue(code uint16, value float32) (int32, error) {
+	axis, ok := vg.axes[code]
+	min, max := int32(-MaximumAxisValue), int32(MaximumAxisValue)
+	var axisValue int32
+	if !ok || axis.min == axis.max {
+		axisValue = denormalizeInput(value)
+	} else {
+		min, max = axis.min, axis.max
+		axisValue = axis.min + int32(math.Round(float64(value+1)/2*float64(axis.max-axis.min)))
+	}
+
+	axisValue, err := clampAxis(axisValue, min, max, vg.axisPolicy)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move axis %d: %w", code, err)
+	}
+	return axisValue, nil
 }
 
 // Takes in a normalized value (-1.0:1.0) and return an event value
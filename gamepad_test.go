@@ -246,3 +246,149 @@ func TestGamepadMoveToFailsOnClosedDevice(t *testing.T) {
 		t.Fatalf("Expected error due to closed device, but no error was returned.")
 	}
 }
+
+func TestXbox360PresetLayout(t *testing.T) {
+	layout, ok := gamepadPresets[PresetXbox360]
+	if !ok {
+		t.Fatalf("Xbox 360 preset is missing")
+	}
+	if layout.vendor != 0x045e || layout.product != 0x028e {
+		t.Fatalf("Expected VID/PID 045e:028e, but got %04x:%04x", layout.vendor, layout.product)
+	}
+	if len(layout.axes) != 8 {
+		t.Fatalf("Expected 8 axes, but got %d", len(layout.axes))
+	}
+	if len(layout.buttons) != 11 {
+		t.Fatalf("Expected 11 buttons, but got %d", len(layout.buttons))
+	}
+}
+
+func TestPresetStickValuesAreMappedToAxisRange(t *testing.T) {
+	layout := gamepadPresets[PresetDualShock4]
+	axes := make(map[uint16]gamepadAxis)
+	for _, axis := range layout.axes {
+		axes[axis.code] = axis
+	}
+	vg := vGamepad{axes: axes}
+
+	for value, expected := range map[float32]int32{-1: 0, 0: 128, 1: 255} {
+		if actual, _ := vg.axisValue(absX, value); actual != expected {
+			t.Fatalf("Expected %v to be mapped to %d, but got %d", value, expected, actual)
+		}
+	}
+}
+
+func TestGamepadPresetCreation(t *testing.T) {
+	for _, preset := range []GamepadPreset{PresetXbox360, PresetDualShock4} {
+		vg, err := CreateGamepadPreset("/dev/uinput", []byte("Preset gamepad"), preset)
+		if err != nil {
+			t.Fatalf("Failed to create the virtual gamepad for preset %d. Last error was: %s\n", preset, err)
+		}
+
+		err = vg.LeftStickMove(0.5, -0.5)
+		if err != nil {
+			t.Fatalf("Failed to send axis event. Last error was: %s\n", err)
+		}
+
+		err = vg.Close()
+		if err != nil {
+			t.Fatalf("Failed to close device. Last error was: %s\n", err)
+		}
+	}
+}
+
+func TestGamepadPresetCreationFailsOnUnknownPreset(t *testing.T) {
+	expected := "unknown gamepad preset 42"
+	_, err := CreateGamepadPreset("/dev/uinput", []byte("Preset gamepad"), GamepadPreset(42))
+	if err == nil || err.Error() != expected {
+		t.Fatalf("Expected: %s\nActual: %v", expected, err)
+	}
+}
+
+func TestGamepadCreationFailsForUnsupportedAxisCode(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	_, restore := fakeIoctl(nil)
+	defer restore()
+
+	layout := defaultGamepadLayout(0x4711, 0x0815)
+	layout.axes = append(layout.axes, gamepadAxis{code: absSize, min: -1, max: 1})
+
+	_, err := createVGamepadDevice(path, []byte("Test Gamepad"), layout, deviceOptions{})
+	expected := fmt.Sprintf("unsupported absolute axis code %d (must be lower than %d)", absSize, absSize)
+	if err == nil || err.Error() != expected {
+		t.Fatalf("Expected: %s\nActual: %v", expected, err)
+	}
+}
+
+func TestGamepadCreationAcceptsHighestAxisCode(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	layout := defaultGamepadLayout(0x4711, 0x0815)
+	layout.axes = append(layout.axes, gamepadAxis{code: absSize - 1, min: -1, max: 1})
+
+	fd, err := createVGamepadDevice(path, []byte("Test Gamepad"), layout, deviceOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create the virtual gamepad. Last error was: %s\n", err)
+	}
+	defer fd.Close()
+
+	absBits := registeredCodes(*calls, uiSetAbsBit)
+	if absBits[len(absBits)-1] != absSize-1 {
+		t.Fatalf("Expected axis %d to be registered, but got %v", absSize-1, absBits)
+	}
+}
+
+func TestGamepadIDCanBeSet(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	_, restore := fakeIoctl(nil)
+	defer restore()
+
+	gamepad, err := CreateGamepad(path, []byte("Test Gamepad"), 0x4711, 0x0815, WithGamepadID(BusBluetooth, 0x054c, 0x05c4, 0x8100))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual gamepad. Last error was: %s\n", err)
+	}
+	defer gamepad.Close()
+
+	expected := inputID{Bustype: BusBluetooth, Vendor: 0x054c, Product: 0x05c4, Version: 0x8100}
+	if id := readUserDev(t, path).ID; id != expected {
+		t.Fatalf("Expected: %+v\nActual: %+v", expected, id)
+	}
+}
+
+func TestGamepadCloseRecentersSticks(t *testing.T) {
+	_, restore := fakeIoctl(nil)
+	defer restore()
+	file, stop := recordEvents(t)
+	layout := defaultGamepadLayout(0x4711, 0x0815)
+	axes := make(map[uint16]gamepadAxis, len(layout.axes))
+	for _, axis := range layout.axes {
+		axes[axis.code] = axis
+	}
+	vg := vGamepad{deviceBase: deviceBase{deviceFile: file, report: newReportBuilder(file)}, axes: axes}
+
+	err := vg.LeftStickMove(1, 1)
+	if err != nil {
+		t.Fatalf("Failed to move stick. Last error was: %s\n", err)
+	}
+	err = vg.Close()
+	if err != nil {
+		t.Fatalf("Failed to close device. Last error was: %s\n", err)
+	}
+
+	// the last report centers all axes
+	events := stop()
+	last := events[len(events)-len(axes)-1:]
+	for _, ev := range last[:len(last)-1] {
+		if ev.Type != evAbs || ev.Value != axes[ev.Code].rest() {
+			t.Fatalf("Expected the axes to be centered on close, but got %v", last)
+		}
+	}
+	if end := last[len(last)-1]; end.Type != evSyn || end.Code != synReport {
+		t.Fatalf("Expected the centering report to be completed, but got %v", last)
+	}
+}
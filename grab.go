@@ -0,0 +1,96 @@
+package uinput
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// A DevNodeGrabber grabs the event node (like /dev/input/event5) of a device exclusively, so that other consumers
+// of the node, like the display server, do not receive the events of the device. All devices of this package
+// (except for the noop devices) implement DevNodeGrabber.
+type DevNodeGrabber interface {
+	// GrabDevNode opens the event node of the device and grabs it using EVIOCGRAB. The grab lasts until
+	// UngrabDevNode is called or the device is closed. Grabbing a node that is already grabbed has no effect.
+	GrabDevNode() error
+
+	// UngrabDevNode releases the grab of GrabDevNode.
+	UngrabDevNode() error
+}
+
+// grabs holds the opened event nodes of the devices that have been grabbed, by their device file. Closing an event
+// node releases its grab.
+var grabs struct {
+	sync.Mutex
+	nodes map[*os.File]*os.File
+}
+
+// openDevNode opens an event node. It is a variable in order to be replaced in tests.
+var openDevNode = func(name string) (*os.File, error) {
+	return os.Open(name)
+}
+
+// grabDevNode resolves the event node of the device with the given device file, using the given function to fetch
+// its syspath, and grabs it.
+func grabDevNode(deviceFile *os.File, fetchSyspath func() (string, error)) error {
+	syspath, err := fetchSyspath()
+	if err != nil {
+		return fmt.Errorf("failed to resolve the event node: %w", err)
+	}
+
+	grabs.Lock()
+	defer grabs.Unlock()
+	if grabs.nodes[deviceFile] != nil {
+		return nil
+	}
+
+	name, err := eventNodeOf(strings.TrimRight(syspath, "\x00"))
+	if err != nil {
+		return err
+	}
+	node, err := openDevNode(name)
+	if err != nil {
+		return fmt.Errorf("failed to open event node %s: %w", name, err)
+	}
+	err = ioctl(node, eviocGrab, 1)
+	if err != nil {
+		_ = node.Close()
+		return fmt.Errorf("failed to grab event node %s: %w", name, err)
+	}
+
+	if grabs.nodes == nil {
+		grabs.nodes = make(map[*os.File]*os.File)
+	}
+	grabs.nodes[deviceFile] = node
+	return nil
+}
+
+// ungrabDevNode releases the grab of the event node of the device with the given device file.
+func ungrabDevNode(deviceFile *os.File) error {
+	grabs.Lock()
+	defer grabs.Unlock()
+	node := grabs.nodes[deviceFile]
+	if node == nil {
+		return errors.New("event node is not grabbed")
+	}
+	delete(grabs.nodes, deviceFile)
+
+	err := ioctl(node, eviocGrab, 0)
+	if err != nil {
+		err = fmt.Errorf("failed to release grab of event node %s: %w", node.Name(), err)
+	}
+	return errors.Join(err, node.Close())
+}
+
+// releaseGrab closes the event node of the device with the given device file if it has been grabbed, which
+// releases the grab. It is called when the device is closed.
+func releaseGrab(deviceFile *os.File) {
+	grabs.Lock()
+	defer grabs.Unlock()
+	if node := grabs.nodes[deviceFile]; node != nil {
+		delete(grabs.nodes, deviceFile)
+		_ = node.Close()
+	}
+}
@@ -0,0 +1,100 @@
+package uinput
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var (
+	_ DevNodeGrabber = (*vKeyboard)(nil)
+	_ DevNodeGrabber = vMouse{}
+	_ DevNodeGrabber = (*vTouchPad)(nil)
+	_ DevNodeGrabber = vDial{}
+	_ DevNodeGrabber = vGamepad{}
+	_ DevNodeGrabber = vMultiTouch{}
+	_ DevNodeGrabber = vScrollDevice{}
+	_ DevNodeGrabber = vSpaceMouse{}
+	_ DevNodeGrabber = vStylus{}
+	_ DevNodeGrabber = vHybridPointer{}
+	_ DevNodeGrabber = vTouchScreen{}
+	_ DevNodeGrabber = vButtonPad{}
+	_ DevNodeGrabber = vSlider{}
+	_ DevNodeGrabber = vRelativeDevice{}
+	_ DevNodeGrabber = vDevice{}
+)
+
+// fakeOpenDevNode replaces openDevNode with a function that opens a temporary file in place of the event node and
+// records the names of the opened nodes.
+func fakeOpenDevNode(t *testing.T) (*[]string, func()) {
+	original := openDevNode
+	names := &[]string{}
+	openDevNode = func(name string) (*os.File, error) {
+		*names = append(*names, name)
+		return os.Create(filepath.Join(t.TempDir(), filepath.Base(name)))
+	}
+	return names, func() { openDevNode = original }
+}
+
+func TestGrabDevNodeIssuesGrabOnResolvedNode(t *testing.T) {
+	syspath := fakeSyspath(t, "event3")
+	names, restoreOpen := fakeOpenDevNode(t)
+	defer restoreOpen()
+	calls, restoreIoctl := fakeIoctl(nil)
+	defer restoreIoctl()
+	deviceFile := &os.File{}
+
+	err := grabDevNode(deviceFile, func() (string, error) { return syspath, nil })
+	if err != nil {
+		t.Fatalf("Failed to grab the event node. Last error was: %s\n", err)
+	}
+	// grabbing again has no effect
+	err = grabDevNode(deviceFile, func() (string, error) { return syspath, nil })
+	if err != nil {
+		t.Fatalf("Failed to grab the event node. Last error was: %s\n", err)
+	}
+	err = ungrabDevNode(deviceFile)
+	if err != nil {
+		t.Fatalf("Failed to release the grab. Last error was: %s\n", err)
+	}
+
+	if expected := []string{"/dev/input/event3"}; !reflect.DeepEqual(*names, expected) {
+		t.Fatalf("Expected: %v\nActual: %v", expected, *names)
+	}
+	expected := []ioctlCall{{cmd: eviocGrab, ptr: 1}, {cmd: eviocGrab, ptr: 0}}
+	if !reflect.DeepEqual(*calls, expected) {
+		t.Fatalf("Expected: %v\nActual: %v", expected, *calls)
+	}
+}
+
+func TestUngrabDevNodeFailsIfNotGrabbed(t *testing.T) {
+	err := ungrabDevNode(&os.File{})
+	if err == nil {
+		t.Fatalf("Expected releasing a grab that does not exist to fail, but no error was returned.")
+	}
+}
+
+func TestClosingDeviceReleasesGrab(t *testing.T) {
+	syspath := fakeSyspath(t, "event3")
+	_, restoreOpen := fakeOpenDevNode(t)
+	defer restoreOpen()
+	_, restoreIoctl := fakeIoctl(nil)
+	defer restoreIoctl()
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	deviceFile, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to setup test. Unable to open device file: %v", err)
+	}
+
+	err = grabDevNode(deviceFile, func() (string, error) { return syspath, nil })
+	if err != nil {
+		t.Fatalf("Failed to grab the event node. Last error was: %s\n", err)
+	}
+	_ = closeDevice(deviceFile)
+
+	if err := ungrabDevNode(deviceFile); err == nil {
+		t.Fatalf("Expected the grab to be released when the device is closed")
+	}
+}
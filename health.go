@@ -0,0 +1,50 @@
+package uinput
+
+import "errors"
+
+// A HealthReporter reports whether the writes to a device succeed, which allows supervisors of long-running
+// processes to decide whether the device needs to be recreated (see also ErrDeviceGone). All devices of this
+// package (except for the noop devices) implement HealthReporter.
+type HealthReporter interface {
+	// Healthy reports whether the most recent write to the device succeeded. It is true as long as nothing has
+	// been written yet.
+	Healthy() bool
+
+	// LastError returns the error of the most recent write that failed, or nil if no write has failed so far.
+	// Unlike Healthy, it is not cleared by subsequent writes that succeed.
+	LastError() error
+
+	// IsAlive probes whether the kernel still has the device by writing an empty report (a single SYN_REPORT),
+	// which consumers ignore. Unlike Healthy, it does not depend on previous writes. Only errors that indicate
+	// that the device is gone (see ErrDeviceGone) make it return false, so a device whose buffer is full is
+	// still alive. The probe neither affects Healthy nor the Stats of the device.
+	IsAlive() bool
+}
+
+// Healthy reports whether the most recent write succeeded.
+func (rb *reportBuilder) Healthy() bool {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return rb.healthy
+}
+
+// LastError returns the error of the most recent write that failed.
+func (rb *reportBuilder) LastError() error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return rb.lastErr
+}
+
+// IsAlive writes a single SYN_REPORT directly to the device, bypassing pending and buffered events. This does not
+// interfere with them, since the pending events have not been written yet and buffered reports are complete.
+func (rb *reportBuilder) IsAlive() bool {
+	buf, err := inputEventToBuffer(inputEvent{Type: evSyn, Code: synReport})
+	if err != nil {
+		return false
+	}
+
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	_, err = rb.w.Write(buf)
+	return !errors.Is(classifyWriteError(err), ErrDeviceGone)
+}
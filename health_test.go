@@ -0,0 +1,110 @@
+package uinput
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+)
+
+var (
+	_ HealthReporter = (*vKeyboard)(nil)
+	_ HealthReporter = vMouse{}
+	_ HealthReporter = (*vTouchPad)(nil)
+	_ HealthReporter = vDial{}
+	_ HealthReporter = vGamepad{}
+	_ HealthReporter = vMultiTouch{}
+	_ HealthReporter = vScrollDevice{}
+	_ HealthReporter = vSpaceMouse{}
+	_ HealthReporter = vStylus{}
+	_ HealthReporter = vHybridPointer{}
+	_ HealthReporter = vTouchScreen{}
+	_ HealthReporter = vButtonPad{}
+	_ HealthReporter = vSlider{}
+	_ HealthReporter = vRelativeDevice{}
+	_ HealthReporter = vDevice{}
+)
+
+func TestHealthyBeforeFirstWrite(t *testing.T) {
+	mouse, err := CreateMouseWriter(&writeCounter{}, []byte("Test Mouse"))
+	if err != nil {
+		t.Fatalf("Failed to create the mouse. Last error was: %s\n", err)
+	}
+
+	health := mouse.(HealthReporter)
+	if !health.Healthy() || health.LastError() != nil {
+		t.Fatalf("Expected a new device to be healthy, but got %t and %v", health.Healthy(), health.LastError())
+	}
+}
+
+func TestHealthReflectsFailedWrite(t *testing.T) {
+	w := &blockingWriter{failures: 1}
+	mouse, err := CreateMouseWriter(w, []byte("Test Mouse"))
+	if err != nil {
+		t.Fatalf("Failed to create the mouse. Last error was: %s\n", err)
+	}
+	health := mouse.(HealthReporter)
+
+	writeErr := mouse.MoveRight(1)
+	if writeErr == nil {
+		t.Fatalf("Expected the write to fail, but no error was returned.")
+	}
+	if health.Healthy() {
+		t.Fatalf("Expected the device to be unhealthy after a failed write")
+	}
+	if !errors.Is(health.LastError(), syscall.EAGAIN) {
+		t.Fatalf("Expected the last error to be EAGAIN, but got %v", health.LastError())
+	}
+
+	err = mouse.MoveRight(1)
+	if err != nil {
+		t.Fatalf("Failed to move mouse. Last error was: %s\n", err)
+	}
+	if !health.Healthy() {
+		t.Fatalf("Expected the device to be healthy again after a successful write")
+	}
+	if health.LastError() == nil {
+		t.Fatalf("Expected the last error to be kept after a successful write")
+	}
+}
+
+func TestIsAliveReturnsFalseIfDeviceIsGone(t *testing.T) {
+	mouse, err := CreateMouseWriter(failingWriter{err: syscall.ENODEV}, []byte("Test Mouse"))
+	if err != nil {
+		t.Fatalf("Failed to create the mouse. Last error was: %s\n", err)
+	}
+
+	health := mouse.(HealthReporter)
+	if health.IsAlive() {
+		t.Fatalf("Expected the device not to be alive if the probe fails with ENODEV")
+	}
+	if !health.Healthy() || health.LastError() != nil {
+		t.Fatalf("Expected the probe not to affect the health, but got %t and %v", health.Healthy(), health.LastError())
+	}
+}
+
+func TestIsAliveReturnsTrueIfDeviceIsBusy(t *testing.T) {
+	mouse, err := CreateMouseWriter(failingWriter{err: syscall.EAGAIN}, []byte("Test Mouse"))
+	if err != nil {
+		t.Fatalf("Failed to create the mouse. Last error was: %s\n", err)
+	}
+
+	if !mouse.(HealthReporter).IsAlive() {
+		t.Fatalf("Expected the device to be alive if its buffer is full")
+	}
+}
+
+func TestIsAliveWritesEmptyReport(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse, err := CreateMouseWriter(file, []byte("Test Mouse"))
+	if err != nil {
+		t.Fatalf("Failed to create the mouse. Last error was: %s\n", err)
+	}
+
+	if !mouse.(HealthReporter).IsAlive() {
+		t.Fatalf("Expected the device to be alive")
+	}
+	if stats := mouse.(StatsReporter).Stats(); stats != (Stats{}) {
+		t.Fatalf("Expected the probe not to be counted, but got %+v", stats)
+	}
+	assertEvents(t, []inputEvent{{Type: evSyn, Code: synReport}}, stop())
+}
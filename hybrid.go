@@ -0,0 +1,237 @@
+package uinput
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// A HybridPointer is a pointing device that supports relative as well as absolute movements, e.g. in order to
+// place the cursor at an exact position and nudge it from there.
+//
+// Note that mixing both kinds of axes on a single device is not supported equally well by all drivers: libinput
+// classifies such a device as a pointer and handles both kinds of movements, but some consumers only honor one
+// of them. Since absolute positions refer to the range given upon creation, it is also up to the consumer how
+// they are mapped to the screen. If only one kind of movement is needed, prefer the Mouse or the TouchPad.
+type HybridPointer interface {
+	// MoveRel will move the cursor relative to its current position.
+	MoveRel(dx int32, dy int32) error
+
+	// MoveTo will move the cursor to the given absolute position within the range of the device.
+	MoveTo(x int32, y int32) error
+
+	// MoveToFraction will move the cursor to the given fractions (0.0 to 1.0) of the range of the device, which
+	// allows resolution-independent automation. Fractions outside of this range are clamped.
+	MoveToFraction(fx float64, fy float64) error
+
+	// SetAbsAxis will set a single absolute axis (ABS_X or ABS_Y) to the given value, without reporting the other
+	// axis. The axis policy of the device is applied to the value (see WithAxisPolicy).
+	SetAbsAxis(code uint16, value int32) error
+
+	// LeftClick will issue a single left click.
+	LeftClick() error
+
+	// RightClick will issue a single right click.
+	RightClick() error
+
+	// FetchSyspath will return the syspath to the device file.
+	FetchSyspath() (string, error)
+
+	// FetchSyspathContext works like FetchSyspath, but retries until the syspath is available or the context is
+	// done. In the latter case, the last error is returned along with the error of the context.
+	FetchSyspathContext(ctx context.Context) (string, error)
+
+	// Reset will release all buttons and move the pointer to the center of the absolute axes (see Resetter).
+	Reset() error
+
+	io.Closer
+}
+
+type vHybridPointer struct {
+	deviceBase
+	// the range of the absolute axes
+	minX, maxX, minY, maxY int32
+	axisPolicy             AxisPolicy
+	absAxisOrder           AbsAxisOrder
+	// clickDelay is the time between the press and the release of a click
+	clickDelay time.Duration
+}
+
+// CreateHybridPointer will create a new pointing device that registers the relative axes REL_X and REL_Y as well
+// as the absolute axes ABS_X and ABS_Y, the latter within the given boundaries.
+func CreateHybridPointer(path string, name []byte, minX int32, maxX int32, minY int32, maxY int32, opts ...Option) (HybridPointer, error) {
+	err := validateDevicePath(path)
+	if err != nil {
+		return nil, err
+	}
+	err = validateUinputName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	options := newDeviceOptions(opts)
+	fd, err := createHybridPointer(path, name, minX, maxX, minY, maxY, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return vHybridPointer{
+		deviceBase:   deviceBase{name: name, deviceFile: fd, report: newReportBuilderWithOptions(fd, name, options)},
+		minX:         minX,
+		maxX:         maxX,
+		minY:         minY,
+		maxY:         maxY,
+		axisPolicy:   options.axisPolicy,
+		absAxisOrder: options.absAxisOrder,
+		clickDelay:   options.clickDelay,
+	}, nil
+}
+
+// MoveRel will emit a relative movement along both axes within a single report.
+func (vHybrid vHybridPointer) MoveRel(dx int32, dy int32) error {
+	err := vHybrid.report.send(
+		inputEvent{Type: evRel, Code: relX, Value: dx},
+		inputEvent{Type: evRel, Code: relY, Value: dy})
+	if err != nil {
+		return fmt.Errorf("failed to write rel event to device file: %w", err)
+	}
+	return nil
+}
+
+// MoveTo will emit an absolute position, applying the axis policy of the device (see WithAxisPolicy).
+func (vHybrid vHybridPointer) MoveTo(x int32, y int32) error {
+	x, err := clampAxis(x, vHybrid.minX, vHybrid.maxX, vHybrid.axisPolicy)
+	if err != nil {
+		return fmt.Errorf("failed to move along the x-axis: %w", err)
+	}
+	y, err = clampAxis(y, vHybrid.minY, vHybrid.maxY, vHybrid.axisPolicy)
+	if err != nil {
+		return fmt.Errorf("failed to move along the y-axis: %w", err)
+	}
+	return sendAbsEvent(vHybrid.report, x, y, vHybrid.absAxisOrder)
+}
+
+// MoveToFraction will emit the absolute position at the given fractions of the range of the device, where 0.0 is
+// mapped to the minimum and 1.0 to the maximum of the respective axis.
+func (vHybrid vHybridPointer) MoveToFraction(fx float64, fy float64) error {
+	return vHybrid.MoveTo(scaleFraction(fx, vHybrid.minX, vHybrid.maxX), scaleFraction(fy, vHybrid.minY, vHybrid.maxY))
+}
+
+// SetAbsAxis will emit a single absolute axis event, applying the axis policy of the device.
+func (vHybrid vHybridPointer) SetAbsAxis(code uint16, value int32) error {
+	var err error
+	switch code {
+	case absX:
+		value, err = clampAxis(value, vHybrid.minX, vHybrid.maxX, vHybrid.axisPolicy)
+	case absY:
+		value, err = clampAxis(value, vHybrid.minY, vHybrid.maxY, vHybrid.axisPolicy)
+	default:
+		return fmt.Errorf("absolute axis %d is not registered", code)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set absolute axis %d: %w", code, err)
+	}
+	return sendAbsAxisEvent(vHybrid.report, code, value)
+}
+
+func (vHybrid vHybridPointer) LeftClick() error {
+	return vHybrid.click(evMouseBtnLeft)
+}
+
+func (vHybrid vHybridPointer) RightClick() error {
+	return vHybrid.click(evMouseBtnRight)
+}
+
+func (vHybrid vHybridPointer) click(button int) error {
+	err := sendBtnEvent(vHybrid.report, []int{button}, btnStatePressed)
+	if err != nil {
+		return fmt.Errorf("failed to issue the click event: %w", err)
+	}
+	time.Sleep(vHybrid.clickDelay)
+
+	return sendBtnEvent(vHybrid.report, []int{button}, btnStateReleased)
+}
+
+// Reset releases all buttons and moves the pointer to the center of the absolute axes (see Resetter).
+func (vHybrid vHybridPointer) Reset() error {
+	x, y := axisCenter(vHybrid.minX, vHybrid.maxX), axisCenter(vHybrid.minY, vHybrid.maxY)
+	return vHybrid.report.reset(orderAbsEvents(absEvents(x, y), vHybrid.absAxisOrder)...)
+}
+
+func createHybridPointer(path string, name []byte, minX int32, maxX int32, minY int32, maxY int32, options deviceOptions) (fd *os.File, err error) {
+	err = validateAxisRange("x", minX, maxX)
+	if err != nil {
+		return nil, err
+	}
+	err = validateAxisRange("y", minY, maxY)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceFile, err := createDeviceFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not create hybrid pointer input device: %w", err)
+	}
+
+	err = registerDevice(deviceFile, uintptr(evKey))
+	if err != nil {
+		_ = deviceFile.Close()
+		return nil, fmt.Errorf("failed to register key device: %w", err)
+	}
+	// the buttons are needed for consumers to classify the device as a pointer
+	for _, event := range []int{evMouseBtnLeft, evMouseBtnRight} {
+		err = ioctl(deviceFile, uiSetKeyBit, uintptr(event))
+		if err != nil {
+			_ = deviceFile.Close()
+			return nil, fmt.Errorf("failed to register button event %v: %w", event, err)
+		}
+	}
+
+	err = registerDevice(deviceFile, uintptr(evRel))
+	if err != nil {
+		_ = deviceFile.Close()
+		return nil, fmt.Errorf("failed to register relative axis input device: %w", err)
+	}
+	for _, event := range []int{relX, relY} {
+		err = ioctl(deviceFile, uiSetRelBit, uintptr(event))
+		if err != nil {
+			_ = deviceFile.Close()
+			return nil, fmt.Errorf("failed to register relative event %v: %w", event, err)
+		}
+	}
+
+	err = registerDevice(deviceFile, uintptr(evAbs))
+	if err != nil {
+		_ = deviceFile.Close()
+		return nil, fmt.Errorf("failed to register absolute axis input device: %w", err)
+	}
+	for _, event := range []int{absX, absY} {
+		err = ioctl(deviceFile, uiSetAbsBit, uintptr(event))
+		if err != nil {
+			_ = deviceFile.Close()
+			return nil, fmt.Errorf("failed to register absolute axis event %v: %w", event, err)
+		}
+	}
+
+	var absMin [absSize]int32
+	absMin[absX] = minX
+	absMin[absY] = minY
+
+	var absMax [absSize]int32
+	absMax[absX] = maxX
+	absMax[absY] = maxY
+
+	return createUsbDevice(deviceFile,
+		uinputUserDev{
+			Name: toUinputName(name),
+			ID: inputID{
+				Bustype: busUsb,
+				Vendor:  0x4711,
+				Product: 0x081b,
+				Version: 1},
+			Absmin: absMin,
+			Absmax: absMax},
+		options)
+}
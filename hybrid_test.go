@@ -0,0 +1,133 @@
+package uinput
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestHybridPointerMoves(t *testing.T) {
+	dev, err := CreateHybridPointer("/dev/uinput", []byte("Test Hybrid Pointer"), 0, 1024, 0, 768)
+	if err != nil {
+		t.Fatalf("Failed to create the virtual hybrid pointer. Last error was: %s\n", err)
+	}
+
+	err = dev.MoveTo(100, 100)
+	if err != nil {
+		t.Fatalf("Failed to move to position. Last error was: %s\n", err)
+	}
+	err = dev.MoveRel(10, -10)
+	if err != nil {
+		t.Fatalf("Failed to move relatively. Last error was: %s\n", err)
+	}
+
+	err = dev.Close()
+	if err != nil {
+		t.Fatalf("Failed to close device. Last error was: %s\n", err)
+	}
+}
+
+func TestHybridPointerRegistersRelativeAndAbsoluteAxes(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	dev, err := CreateHybridPointer(path, []byte("Test Hybrid Pointer"), 0, 1024, 0, 768)
+	if err != nil {
+		t.Fatalf("Failed to create the virtual hybrid pointer. Last error was: %s\n", err)
+	}
+	defer dev.Close()
+
+	expected := []uintptr{evKey, evRel, evAbs}
+	if evBits := registeredCodes(*calls, uiSetEvBit); !reflect.DeepEqual(evBits, expected) {
+		t.Fatalf("Expected event types %v to be registered, but got %v", expected, evBits)
+	}
+	expected = []uintptr{relX, relY}
+	if relBits := registeredCodes(*calls, uiSetRelBit); !reflect.DeepEqual(relBits, expected) {
+		t.Fatalf("Expected relative axes %v to be registered, but got %v", expected, relBits)
+	}
+	expected = []uintptr{absX, absY}
+	if absBits := registeredCodes(*calls, uiSetAbsBit); !reflect.DeepEqual(absBits, expected) {
+		t.Fatalf("Expected absolute axes %v to be registered, but got %v", expected, absBits)
+	}
+}
+
+func TestHybridPointerEmitsRelativeAndAbsoluteEvents(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vHybridPointer{deviceBase: deviceBase{report: newReportBuilder(file)}, minX: 0, maxX: 1024, minY: 0, maxY: 768}
+
+	err := dev.MoveTo(100, 200)
+	if err != nil {
+		t.Fatalf("Failed to move to position. Last error was: %s\n", err)
+	}
+	err = dev.MoveRel(-5, 7)
+	if err != nil {
+		t.Fatalf("Failed to move relatively. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absX, Value: 100},
+		{Type: evAbs, Code: absY, Value: 200},
+		{Type: evSyn, Code: synReport},
+		{Type: evRel, Code: relX, Value: -5},
+		{Type: evRel, Code: relY, Value: 7},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestHybridPointerMoveToFraction(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vHybridPointer{deviceBase: deviceBase{report: newReportBuilder(file)}, minX: 0, maxX: 1024, minY: 0, maxY: 768}
+
+	err := dev.MoveToFraction(0.5, 0.5)
+	if err != nil {
+		t.Fatalf("Failed to move to fraction. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absX, Value: 512},
+		{Type: evAbs, Code: absY, Value: 384},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestHybridPointerSetAbsAxis(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vHybridPointer{deviceBase: deviceBase{report: newReportBuilder(file)}, minX: 0, maxX: 1024, minY: 0, maxY: 768, axisPolicy: AxisClamp}
+
+	err := dev.SetAbsAxis(absX, 2000)
+	if err != nil {
+		t.Fatalf("Failed to set absolute axis. Last error was: %s\n", err)
+	}
+
+	// the value is clamped to the range of the axis, and ABS_Y is not reported
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absX, Value: 1024},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestHybridPointerCreationFailsOnNonExistentPathName(t *testing.T) {
+	path := "/some/bogus/path"
+	_, err := CreateHybridPointer(path, []byte("HybridPointer"), 0, 1024, 0, 768)
+	if !os.IsNotExist(err) {
+		t.Fatalf("Expected: os.IsNotExist error\nActual: %s", err)
+	}
+}
+
+func TestHybridPointerResetHonorsAbsAxisOrder(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vHybridPointer{deviceBase: deviceBase{report: newReportBuilder(file)}, maxX: 1024, maxY: 768, absAxisOrder: AbsAxisOrderYX}
+
+	err := dev.Reset()
+	if err != nil {
+		t.Fatalf("Failed to reset. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absY, Value: 384},
+		{Type: evAbs, Code: absX, Value: 512},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
@@ -0,0 +1,116 @@
+package uinput
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// A KeepAliver periodically emits an event, for consumers that consider a device inactive if it does not send any
+// events for a while. All devices of this package (except for the noop devices) implement KeepAliver, but only
+// devices that have been created using WithKeepAlive can emit the keep-alive reports.
+type KeepAliver interface {
+	// KeepAlive emits a report consisting of an MSC_TIMESTAMP event every interval, in the background, until the
+	// returned function is called or the device is closed. The timestamp holds the current time in microseconds,
+	// truncated to 32 bits. Re-emitting the state of the device instead would be pointless, since the kernel drops
+	// events that do not change the state, along with the reports that end up empty. Starting another keep-alive
+	// stops the previous one. Failed writes are reported by the HealthReporter of the device. An error is returned
+	// if the device has not been created using WithKeepAlive.
+	KeepAlive(interval time.Duration) (stop func(), err error)
+}
+
+// KeepAlive emits a timestamp every interval until the returned function is called. A non-positive interval does
+// not emit anything.
+func (rb *reportBuilder) KeepAlive(interval time.Duration) (stop func(), err error) {
+	if !rb.keepAlive {
+		return nil, errors.New("keep-alive is not enabled (see WithKeepAlive)")
+	}
+	if interval <= 0 {
+		return func() {}, nil
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				_ = rb.emitTimestamp()
+			}
+		}
+	}()
+
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+		})
+	}
+
+	rb.mu.Lock()
+	previous := rb.stopKeepAlive
+	rb.stopKeepAlive = stop
+	rb.mu.Unlock()
+	if previous != nil {
+		previous()
+	}
+	return stop, nil
+}
+
+// endKeepAlive stops the keep-alive reports, if any. It must not be called while holding the lock, since the
+// keep-alive may be waiting for it.
+func (rb *reportBuilder) endKeepAlive() {
+	rb.mu.Lock()
+	stop := rb.stopKeepAlive
+	rb.stopKeepAlive = nil
+	rb.mu.Unlock()
+	if stop != nil {
+		stop()
+	}
+}
+
+// emitTimestamp writes a report consisting of an MSC_TIMESTAMP event. Nothing is written while a report is in
+// progress (see RawEventWriter), in order not to complete it prematurely.
+func (rb *reportBuilder) emitTimestamp() error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	if len(rb.events) > 0 {
+		return nil
+	}
+
+	events := []inputEvent{
+		{Type: evMsc, Code: mscTimestamp, Value: int32(time.Now().UnixMicro())},
+		{Type: evSyn, Code: synReport},
+	}
+	err := rb.validateLocked(events)
+	if err != nil {
+		return err
+	}
+	err = rb.bufferLocked(events)
+	if err != nil {
+		return err
+	}
+	return rb.writeBufferLocked()
+}
+
+// registerKeepAlive registers MSC_TIMESTAMP, which the kernel passes on to consumers even if the value has not
+// changed, unlike the events of keys and axes.
+func registerKeepAlive(deviceFile *os.File) error {
+	err := ioctl(deviceFile, uiSetEvBit, evMsc)
+	if err != nil {
+		return fmt.Errorf("failed to register misc events: %w", err)
+	}
+	err = ioctl(deviceFile, uiSetMscBit, mscTimestamp)
+	if err != nil {
+		return fmt.Errorf("failed to register the timestamp event: %w", err)
+	}
+	return nil
+}
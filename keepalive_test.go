@@ -0,0 +1,195 @@
+package uinput
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	_ KeepAliver = (*vKeyboard)(nil)
+	_ KeepAliver = vMouse{}
+	_ KeepAliver = (*vTouchPad)(nil)
+	_ KeepAliver = vDial{}
+	_ KeepAliver = vGamepad{}
+	_ KeepAliver = vMultiTouch{}
+	_ KeepAliver = vScrollDevice{}
+	_ KeepAliver = vSpaceMouse{}
+	_ KeepAliver = vStylus{}
+	_ KeepAliver = vHybridPointer{}
+	_ KeepAliver = vTouchScreen{}
+	_ KeepAliver = vButtonPad{}
+	_ KeepAliver = vSlider{}
+	_ KeepAliver = vRelativeDevice{}
+	_ KeepAliver = vDevice{}
+)
+
+func TestKeepAliveEmitsTimestampsUntilStopped(t *testing.T) {
+	file, stop := recordEvents(t)
+	report := newReportBuilder(file)
+	report.keepAlive = true
+	slider := vSlider{deviceBase: deviceBase{report: report}, min: 0, max: 127}
+
+	stopKeepAlive, err := slider.KeepAlive(10 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to start the keep-alive. Last error was: %s\n", err)
+	}
+	time.Sleep(55 * time.Millisecond)
+	stopKeepAlive()
+	// no reports may follow once the keep-alive has been stopped
+	time.Sleep(30 * time.Millisecond)
+	stopKeepAlive()
+
+	events := stop()
+	if len(events)%2 != 0 {
+		t.Fatalf("Expected only complete reports, but got %v", events)
+	}
+	reports := len(events) / 2
+	// about five keep-alive reports
+	if reports < 2 || reports > 6 {
+		t.Fatalf("Expected between 2 and 6 reports, but got %d: %v", reports, events)
+	}
+	for i := 0; i < reports; i++ {
+		ev := events[2*i]
+		if ev.Type != evMsc || ev.Code != mscTimestamp {
+			t.Fatalf("Expected report %d to consist of MSC_TIMESTAMP, but got %v", i, events[2*i:2*i+2])
+		}
+		assertEvents(t, []inputEvent{{Type: evSyn, Code: synReport}}, events[2*i+1:2*i+2])
+	}
+}
+
+func TestKeepAliveReportsPassKernelFiltering(t *testing.T) {
+	file, stop := recordEvents(t)
+	report := newReportBuilder(file)
+	report.keepAlive = true
+	vk := &vKeyboard{deviceBase: deviceBase{report: report}, composeKey: KeyCompose}
+
+	err := vk.KeyDown(KeyLeftshift)
+	if err != nil {
+		t.Fatalf("Failed to press key. Last error was: %s\n", err)
+	}
+	stopKeepAlive, err := vk.KeepAlive(10 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to start the keep-alive. Last error was: %s\n", err)
+	}
+	time.Sleep(35 * time.Millisecond)
+	stopKeepAlive()
+
+	frames := kernelFilter(stop())
+	if len(frames) < 2 {
+		t.Fatalf("Expected at least one keep-alive report to be delivered, but got %v", frames)
+	}
+	for _, frame := range frames[1:] {
+		if len(frame) != 1 || frame[0].Type != evMsc || frame[0].Code != mscTimestamp {
+			t.Fatalf("Expected the keep-alive report to consist of MSC_TIMESTAMP, but got %v", frame)
+		}
+	}
+}
+
+func TestKernelFilterDropsUnchangedState(t *testing.T) {
+	// re-emitting a held key or the value of an axis does not deliver anything
+	frames := kernelFilter([]recordedEvent{
+		{inputEvent: inputEvent{Type: evKey, Code: KeyA, Value: btnStatePressed}},
+		{inputEvent: inputEvent{Type: evAbs, Code: absX, Value: 10}},
+		{inputEvent: inputEvent{Type: evSyn, Code: synReport}},
+		{inputEvent: inputEvent{Type: evKey, Code: KeyA, Value: btnStatePressed}},
+		{inputEvent: inputEvent{Type: evAbs, Code: absX, Value: 10}},
+		{inputEvent: inputEvent{Type: evSyn, Code: synReport}},
+		{inputEvent: inputEvent{Type: evSyn, Code: synReport}},
+	})
+	if len(frames) != 1 {
+		t.Fatalf("Expected a single report to be delivered, but got %v", frames)
+	}
+}
+
+func TestKeepAliveFailsWithoutWithKeepAlive(t *testing.T) {
+	rb := newReportBuilder(ioutil.Discard)
+
+	_, err := rb.KeepAlive(10 * time.Millisecond)
+	if err == nil {
+		t.Fatalf("Expected KeepAlive to fail without WithKeepAlive, but got no error.")
+	}
+}
+
+func TestWithKeepAliveRegistersTimestamp(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	dev, err := CreateSlider(path, []byte("Test Slider"), 0, 127, WithKeepAlive())
+	if err != nil {
+		t.Fatalf("Failed to create the slider. Last error was: %s\n", err)
+	}
+	defer dev.Close()
+
+	if codes := registeredCodes(*calls, uiSetMscBit); !reflect.DeepEqual(codes, []uintptr{mscTimestamp}) {
+		t.Fatalf("Expected MSC_TIMESTAMP to be registered, but got %v", codes)
+	}
+	evTypes := registeredCodes(*calls, uiSetEvBit)
+	if evTypes[len(evTypes)-1] != evMsc {
+		t.Fatalf("Expected EV_MSC to be registered, but got %v", evTypes)
+	}
+}
+
+func TestKeepAliveStopsOnClose(t *testing.T) {
+	file, stop := recordEvents(t)
+	rb := newReportBuilder(file)
+	rb.keepAlive = true
+
+	_, err := rb.KeepAlive(10 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to start the keep-alive. Last error was: %s\n", err)
+	}
+	time.Sleep(15 * time.Millisecond)
+	err = rb.close()
+	if err != nil {
+		t.Fatalf("Failed to close the report builder. Last error was: %s\n", err)
+	}
+	closed := time.Now()
+	time.Sleep(30 * time.Millisecond)
+
+	for _, ev := range stop() {
+		if ev.received.After(closed) {
+			t.Fatalf("Expected no keep-alive report after close, but got %v", ev)
+		}
+	}
+}
+
+// kernelFilter returns the reports that the kernel would deliver to the consumers of a device for the given events.
+// Like the input core, it drops key events that do not change the state of the key, absolute axis events that do
+// not change the value of the axis and relative axis events without movement, as well as the reports that end up
+// empty. Misc events are assumed to be registered, and are always delivered.
+func kernelFilter(events []recordedEvent) [][]inputEvent {
+	keys := make(map[uint16]int32)
+	abs := make(map[uint16]int32)
+	var frames [][]inputEvent
+	var frame []inputEvent
+	for _, ev := range events {
+		switch ev.Type {
+		case evKey:
+			if ev.Value != 2 && keys[ev.Code] == ev.Value {
+				continue
+			}
+			keys[ev.Code] = ev.Value
+		case evAbs:
+			if abs[ev.Code] == ev.Value {
+				continue
+			}
+			abs[ev.Code] = ev.Value
+		case evRel:
+			if ev.Value == 0 {
+				continue
+			}
+		case evSyn:
+			if ev.Code == synReport && len(frame) > 0 {
+				frames = append(frames, frame)
+				frame = nil
+			}
+			continue
+		}
+		frame = append(frame, ev.inputEvent)
+	}
+	return frames
+}
@@ -1,9 +1,13 @@
 package uinput
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sync"
+	"time"
 )
 
 // A Keyboard is an key event output device. It is used to
@@ -27,14 +31,115 @@ type Keyboard interface {
 	io.Closer
 }
 
+// A StringTyper types text instead of single keys. The keyboards created by this package implement StringTyper.
+type StringTyper interface {
+	// TypeString will type the given string by pressing the keys that produce each of its characters.
+	// A US keyboard layout is assumed. Characters that are not part of this layout, like "é", are entered
+	// as compose sequences, which requires the compose key to be enabled in the receiving environment.
+	TypeString(s string) error
+
+	// SetComposeKey sets the key that is used to start compose sequences in TypeString. This defaults to KeyCompose.
+	SetComposeKey(key int) error
+
+	// TypeStringDelayed works like TypeString, but waits for the given duration after each key stroke before
+	// issuing the next one. This helps applications that can not keep up with input issued at full speed.
+	TypeStringDelayed(s string, perKey time.Duration) error
+
+	// TypeStringDelayedContext works like TypeStringDelayed, but stops typing once the given context is done.
+	// In this case the error of the context is returned.
+	TypeStringDelayedContext(ctx context.Context, s string, perKey time.Duration) error
+
+	// TypeRune will type a single character like TypeString. Characters for which no key mapping exists are
+	// entered by their code point using the Ctrl+Shift+U sequence of IBus instead. Note that this is a best-effort
+	// fallback: environments that don't support the sequence will receive the plain key strokes.
+	TypeRune(char rune) error
+
+	// TypeStringContext works like TypeString, but stops typing once the given context is done. In this case
+	// the error of the context is returned. The key stroke in progress is always completed, so that no key
+	// is left pressed.
+	TypeStringContext(ctx context.Context, s string) error
+}
+
+// A NamedKeyPresser presses keys by their names instead of their codes. The keyboards created by this package
+// implement NamedKeyPresser.
+type NamedKeyPresser interface {
+	// KeyPressByName works like KeyPress, but takes the name of the key (e.g. "KEY_ENTER", see KeyByName).
+	KeyPressByName(name string) error
+
+	// KeyDownByName works like KeyDown, but takes the name of the key (e.g. "KEY_LEFTSHIFT", see KeyByName).
+	KeyDownByName(name string) error
+
+	// KeyUpByName works like KeyUp, but takes the name of the key (e.g. "KEY_LEFTSHIFT", see KeyByName).
+	KeyUpByName(name string) error
+}
+
+// A KeyHolder holds a key down for as long as a context lasts. The keyboards created by this package implement
+// KeyHolder.
+type KeyHolder interface {
+	// HoldKey will press the given key and block until the context is done, then release the key again.
+	// Combined with a timeout or a cancel function, this allows holding a key without having to ensure
+	// the release manually.
+	HoldKey(ctx context.Context, key int) error
+}
+
+// A KeyRepeater emits the repeat events of a key that is held down. The keyboards created by this package implement
+// KeyRepeater.
+type KeyRepeater interface {
+	// KeyHoldRepeat will press the given key and emit the given number of repeat events with the given period in
+	// between, before releasing the key again. This resembles the typematic behavior of a real keyboard.
+	KeyHoldRepeat(key int, count int, period time.Duration) error
+}
+
+// A ModifierLatcher keeps track of the modifier keys that are held down. The keyboards created by this package
+// implement ModifierLatcher.
+type ModifierLatcher interface {
+	// ModifierDown will press the given modifier key (Ctrl, Shift, Alt or Meta) and keep track of it, so that it
+	// is reported by IsModifierActive until it is released using ModifierUp or ClearModifiers.
+	ModifierDown(modifier int) error
+
+	// ModifierUp will release the given modifier key that has been pressed using ModifierDown.
+	ModifierUp(modifier int) error
+
+	// IsModifierActive reports whether the given modifier key has been pressed using ModifierDown and not been
+	// released yet.
+	IsModifierActive(modifier int) bool
+
+	// ClearModifiers will release all modifier keys that have been pressed using ModifierDown, in reverse order.
+	ClearModifiers() error
+}
+
 type vKeyboard struct {
-	name       []byte
-	deviceFile *os.File
+	deviceBase
+	composeKey int
+	scanCodes  bool
+
+	// latched holds the modifiers pressed using ModifierDown, in the order they were pressed
+	mu      sync.Mutex
+	latched []int
+
+	// ledSource is the device file if the LEDs are registered, from which the kernel's EV_LED events are read
+	// (see ReadLEDChanges). ledState is the last known state of the LEDs.
+	ledSource io.Reader
+	ledState  LEDState
+	ledsOnce  sync.Once
+	leds      chan LEDState
+}
+
+// modifierKeys are the keys that can be pressed using ModifierDown.
+var modifierKeys = map[int]bool{
+	KeyLeftctrl:   true,
+	KeyRightctrl:  true,
+	KeyLeftshift:  true,
+	KeyRightshift: true,
+	KeyLeftalt:    true,
+	KeyRightalt:   true,
+	KeyLeftmeta:   true,
+	KeyRightmeta:  true,
 }
 
 // CreateKeyboard will create a new keyboard using the given uinput
-// device path of the uinput device.
-func CreateKeyboard(path string, name []byte) (Keyboard, error) {
+// device path of the uinput device. By default, all keys are registered (see WithKeys).
+func CreateKeyboard(path string, name []byte, opts ...Option) (Keyboard, error) {
 	err := validateDevicePath(path)
 	if err != nil {
 		return nil, err
@@ -43,73 +148,422 @@ func CreateKeyboard(path string, name []byte) (Keyboard, error) {
 	if err != nil {
 		return nil, err
 	}
+	options := newDeviceOptions(opts)
+	for _, key := range options.keys {
+		if !keyCodeInRange(key) {
+			return nil, fmt.Errorf("failed to register key. Code %d is not in range", key)
+		}
+	}
 
-	fd, err := createVKeyboardDevice(path, name)
+	fd, err := createVKeyboardDevice(path, name, options)
 	if err != nil {
 		return nil, err
 	}
 
-	return vKeyboard{name: name, deviceFile: fd}, nil
+	vk := &vKeyboard{
+		deviceBase: deviceBase{
+			name:       name,
+			deviceFile: fd,
+			report:     newReportBuilderWithOptions(fd, name, options.withoutReportInterval()),
+		},
+		composeKey: KeyCompose,
+		scanCodes:  options.scanCodes,
+	}
+	if options.leds {
+		vk.ledSource = fd
+	}
+	if options.initialLEDs != nil {
+		vk.ledState = *options.initialLEDs
+		err = vk.setLEDs(*options.initialLEDs)
+		if err != nil {
+			vk.Close()
+			return nil, err
+		}
+	}
+	return vk, nil
 }
 
 // KeyPress will issue a single key press (push down a key and then immediately release it).
-func (vk vKeyboard) KeyPress(key int) error {
+func (vk *vKeyboard) KeyPress(key int) error {
 	if !keyCodeInRange(key) {
 		return fmt.Errorf("failed to perform KeyPress. Code %d is not in range", key)
 	}
-	err := sendBtnEvent(vk.deviceFile, []int{key}, btnStatePressed)
+	err := vk.sendKey(key, btnStatePressed)
 	if err != nil {
-		return fmt.Errorf("failed to issue the KeyDown event: %v", err)
+		return fmt.Errorf("failed to issue the KeyDown event: %w", err)
 	}
 
-	return sendBtnEvent(vk.deviceFile, []int{key}, btnStateReleased)
+	return vk.sendKey(key, btnStateReleased)
 }
 
 // KeyDown will send the key code passed (see keycodes.go for available keycodes). Note that unless a key release
 // event is sent to the device, the key will remain pressed and therefore input will continuously be generated. Therefore,
 // do not forget to call "KeyUp" afterwards.
-func (vk vKeyboard) KeyDown(key int) error {
+func (vk *vKeyboard) KeyDown(key int) error {
 	if !keyCodeInRange(key) {
 		return fmt.Errorf("failed to perform KeyDown. Code %d is not in range", key)
 	}
-	return sendBtnEvent(vk.deviceFile, []int{key}, btnStatePressed)
+	return vk.sendKey(key, btnStatePressed)
 }
 
 // KeyUp will release the given key passed as a parameter (see keycodes.go for available keycodes). In most
 // cases it is recommended to call this function immediately after the "KeyDown" function in order to only issue a
 // single key press.
-func (vk vKeyboard) KeyUp(key int) error {
+func (vk *vKeyboard) KeyUp(key int) error {
 	if !keyCodeInRange(key) {
 		return fmt.Errorf("failed to perform KeyUp. Code %d is not in range", key)
 	}
 
-	return sendBtnEvent(vk.deviceFile, []int{key}, btnStateReleased)
+	return vk.sendKey(key, btnStateReleased)
+}
+
+// sendKey issues a single key event. If scan codes are enabled, the scan code of the key precedes the press and the
+// release of the key within the same report, just like it does for real hardware. Repeat events are generated by
+// the keyboard itself and therefore do not carry a scan code.
+func (vk *vKeyboard) sendKey(key int, btnState int) error {
+	scanCode, ok := keyScanCodes[key]
+	if !vk.scanCodes || !ok || btnState == btnStateRepeated {
+		return sendBtnEvent(vk.report, []int{key}, btnState)
+	}
+
+	err := vk.report.send(
+		inputEvent{
+			Type:  evMsc,
+			Code:  mscScan,
+			Value: scanCode,
+		},
+		inputEvent{
+			Type:  evKey,
+			Code:  uint16(key),
+			Value: int32(btnState),
+		})
+	if err != nil {
+		return fmt.Errorf("writing btnEvent structure to the device file failed: %w", err)
+	}
+	return nil
+}
+
+// KeyPressByName will issue a single key press for the key with the given name.
+func (vk *vKeyboard) KeyPressByName(name string) error {
+	key, err := KeyByName(name)
+	if err != nil {
+		return fmt.Errorf("failed to perform KeyPress: %w", err)
+	}
+	return vk.KeyPress(key)
+}
+
+// KeyDownByName will press the key with the given name.
+func (vk *vKeyboard) KeyDownByName(name string) error {
+	key, err := KeyByName(name)
+	if err != nil {
+		return fmt.Errorf("failed to perform KeyDown: %w", err)
+	}
+	return vk.KeyDown(key)
+}
+
+// KeyUpByName will release the key with the given name.
+func (vk *vKeyboard) KeyUpByName(name string) error {
+	key, err := KeyByName(name)
+	if err != nil {
+		return fmt.Errorf("failed to perform KeyUp: %w", err)
+	}
+	return vk.KeyUp(key)
+}
+
+// KeyHoldRepeat will hold down the given key, issuing repeat events (value 2) in the given period, like real
+// keyboards do while a key is held. The key is released even if a repeat event fails.
+func (vk *vKeyboard) KeyHoldRepeat(key int, count int, period time.Duration) (err error) {
+	if count < 0 {
+		return fmt.Errorf("%d is out of range. Expected a positive or zero number of repeats", count)
+	}
+	err = vk.KeyDown(key)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, vk.KeyUp(key))
+	}()
+
+	for i := 0; i < count; i++ {
+		time.Sleep(period)
+		err = sendBtnEvent(vk.report, []int{key}, btnStateRepeated)
+		if err != nil {
+			return fmt.Errorf("failed to issue repeat event: %w", err)
+		}
+	}
+
+	time.Sleep(period)
+	return nil
+}
+
+// HoldKey will press the given key until the context is done. The key is released exactly once, no matter
+// how often the context is canceled.
+func (vk *vKeyboard) HoldKey(ctx context.Context, key int) error {
+	err := vk.KeyDown(key)
+	if err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return vk.KeyUp(key)
+}
+
+// TypeString will type the given string, one character at a time. Uppercase letters and symbols are typed
+// using the left shift key, characters that require a compose sequence are preceded by the compose key.
+// An error is returned if the string contains a character for which no key mapping exists. In this case
+// none of the characters will be typed.
+func (vk *vKeyboard) TypeString(s string) error {
+	return vk.typeString(context.Background(), s, 0)
+}
+
+// TypeStringContext will type the given string like TypeString, until the context is done.
+// Keys are always released before returning, so that cancellation will not leave a key pressed.
+func (vk *vKeyboard) TypeStringContext(ctx context.Context, s string) error {
+	return vk.typeString(ctx, s, 0)
+}
+
+// TypeStringDelayed will type the given string, waiting for the given duration between the release of a key
+// and the press of the next one.
+func (vk *vKeyboard) TypeStringDelayed(s string, perKey time.Duration) error {
+	return vk.typeString(context.Background(), s, perKey)
+}
+
+// TypeStringDelayedContext will type the given string like TypeStringDelayed, until the context is done.
+// Keys are always released before returning, so that cancellation will not leave a key pressed.
+func (vk *vKeyboard) TypeStringDelayedContext(ctx context.Context, s string, perKey time.Duration) error {
+	return vk.typeString(ctx, s, perKey)
+}
+
+func (vk *vKeyboard) typeString(ctx context.Context, s string, perKey time.Duration) error {
+	var strokes []keyStroke
+	for _, char := range s {
+		charStrokes, err := keyStrokesFor(char, vk.composeKey)
+		if err != nil {
+			return fmt.Errorf("failed to type string: %w", err)
+		}
+		strokes = append(strokes, charStrokes...)
+	}
+
+	for i, stroke := range strokes {
+		if i > 0 && perKey > 0 {
+			timer := time.NewTimer(perKey)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := vk.typeKeyStroke(stroke)
+		if err != nil {
+			return fmt.Errorf("failed to type string: %w", err)
+		}
+	}
+	return nil
+}
+
+// TypeRune will type the given character using the keyboard layout or a compose sequence, falling back to the
+// hexadecimal entry of its code point (Ctrl+Shift+U).
+func (vk *vKeyboard) TypeRune(char rune) error {
+	strokes, err := keyStrokesFor(char, vk.composeKey)
+	if err != nil {
+		strokes = hexEntryStrokes(char)
+	}
+
+	for _, stroke := range strokes {
+		err = vk.typeKeyStroke(stroke)
+		if err != nil {
+			return fmt.Errorf("failed to type rune %q: %w", char, err)
+		}
+	}
+	return nil
+}
+
+// SetComposeKey sets the key that is used to start compose sequences in TypeString.
+func (vk *vKeyboard) SetComposeKey(key int) error {
+	if !keyCodeInRange(key) {
+		return fmt.Errorf("failed to set compose key. Code %d is not in range", key)
+	}
+	vk.composeKey = key
+	return nil
+}
+
+// ModifierDown will press the given modifier key and latch it until it is released.
+func (vk *vKeyboard) ModifierDown(modifier int) error {
+	if !modifierKeys[modifier] {
+		return fmt.Errorf("failed to press modifier. Code %d is not a modifier key", modifier)
+	}
+	vk.mu.Lock()
+	defer vk.mu.Unlock()
+
+	err := vk.KeyDown(modifier)
+	if err != nil {
+		return err
+	}
+	if indexOfKey(vk.latched, modifier) < 0 {
+		vk.latched = append(vk.latched, modifier)
+	}
+	return nil
+}
+
+// ModifierUp will release the given modifier key. Modifiers that are not active are released anyway.
+func (vk *vKeyboard) ModifierUp(modifier int) error {
+	if !modifierKeys[modifier] {
+		return fmt.Errorf("failed to release modifier. Code %d is not a modifier key", modifier)
+	}
+	vk.mu.Lock()
+	defer vk.mu.Unlock()
+
+	err := vk.KeyUp(modifier)
+	if err != nil {
+		return err
+	}
+	if i := indexOfKey(vk.latched, modifier); i >= 0 {
+		vk.latched = append(vk.latched[:i], vk.latched[i+1:]...)
+	}
+	return nil
+}
+
+// IsModifierActive reports whether the given modifier key is latched.
+func (vk *vKeyboard) IsModifierActive(modifier int) bool {
+	vk.mu.Lock()
+	defer vk.mu.Unlock()
+	return indexOfKey(vk.latched, modifier) >= 0
+}
+
+// ClearModifiers will release all latched modifier keys in reverse order. Modifiers that fail to be released
+// remain latched.
+func (vk *vKeyboard) ClearModifiers() error {
+	vk.mu.Lock()
+	defer vk.mu.Unlock()
+
+	var err error
+	var remaining []int
+	for i := len(vk.latched) - 1; i >= 0; i-- {
+		releaseErr := vk.KeyUp(vk.latched[i])
+		if releaseErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to release modifier %d: %w", vk.latched[i], releaseErr))
+			remaining = append([]int{vk.latched[i]}, remaining...)
+		}
+	}
+	vk.latched = remaining
+	return err
+}
+
+func indexOfKey(keys []int, key int) int {
+	for i, k := range keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (vk *vKeyboard) typeKeyStroke(stroke keyStroke) error {
+	var modifiers []int
+	if stroke.ctrl {
+		modifiers = append(modifiers, KeyLeftctrl)
+	}
+	if stroke.shift {
+		modifiers = append(modifiers, KeyLeftshift)
+	}
+
+	for i, modifier := range modifiers {
+		err := vk.KeyDown(modifier)
+		if err != nil {
+			vk.releaseModifiers(modifiers[:i])
+			return err
+		}
+	}
+	err := vk.KeyPress(stroke.key)
+	if err != nil {
+		vk.releaseModifiers(modifiers)
+		return err
+	}
+	for i := len(modifiers) - 1; i >= 0; i-- {
+		err = vk.KeyUp(modifiers[i])
+		if err != nil {
+			vk.releaseModifiers(modifiers[:i])
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseModifiers releases the given modifiers in reverse order, ignoring any errors.
+func (vk *vKeyboard) releaseModifiers(modifiers []int) {
+	for i := len(modifiers) - 1; i >= 0; i-- {
+		_ = vk.KeyUp(modifiers[i])
+	}
 }
 
-// Close will close the device and free resources.
-// It's usually a good idea to use defer to call this function.
-func (vk vKeyboard) Close() error {
-	return closeDevice(vk.deviceFile)
+// Reset releases all keys that are held down (see Resetter).
+func (vk *vKeyboard) Reset() error {
+	return vk.report.reset()
 }
 
-func createVKeyboardDevice(path string, name []byte) (fd *os.File, err error) {
-	deviceFile, err := createDeviceFile(path)
+// createVKeyboardDevice creates a keyboard that supports the keys of the options, or all keys if none are given.
+func createVKeyboardDevice(path string, name []byte, options deviceOptions) (fd *os.File, err error) {
+	var deviceFile *os.File
+	if options.leds {
+		deviceFile, err = createReadableDeviceFile(path)
+	} else {
+		deviceFile, err = createDeviceFile(path)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create virtual keyboard device: %v", err)
+		return nil, fmt.Errorf("failed to create virtual keyboard device: %w", err)
 	}
 
 	err = registerDevice(deviceFile, uintptr(evKey))
 	if err != nil {
 		deviceFile.Close()
-		return nil, fmt.Errorf("failed to register virtual keyboard device: %v", err)
+		return nil, fmt.Errorf("failed to register virtual keyboard device: %w", err)
+	}
+
+	keys := options.keys
+	if len(keys) == 0 {
+		for i := 0; i <= keyMax; i++ {
+			keys = append(keys, i)
+		}
 	}
 
 	// register key events
-	for i := 0; i <= keyMax; i++ {
-		err = ioctl(deviceFile, uiSetKeyBit, uintptr(i))
+	for _, key := range keys {
+		err = ioctl(deviceFile, uiSetKeyBit, uintptr(key))
 		if err != nil {
 			deviceFile.Close()
-			return nil, fmt.Errorf("failed to register key number %d: %v", i, err)
+			return nil, fmt.Errorf("failed to register key number %d: %w", key, err)
+		}
+	}
+
+	if options.scanCodes {
+		err = registerDevice(deviceFile, uintptr(evMsc))
+		if err != nil {
+			deviceFile.Close()
+			return nil, fmt.Errorf("failed to register misc device: %w", err)
+		}
+		err = ioctl(deviceFile, uiSetMscBit, uintptr(mscScan))
+		if err != nil {
+			deviceFile.Close()
+			return nil, fmt.Errorf("failed to register scan code event: %w", err)
+		}
+	}
+
+	if options.leds {
+		err = registerDevice(deviceFile, uintptr(evLed))
+		if err != nil {
+			deviceFile.Close()
+			return nil, fmt.Errorf("failed to register led device: %w", err)
+		}
+		for _, led := range []uintptr{ledNumL, ledCapsL, ledScrollL} {
+			err = ioctl(deviceFile, uiSetLedBit, led)
+			if err != nil {
+				deviceFile.Close()
+				return nil, fmt.Errorf("failed to register led %d: %w", led, err)
+			}
 		}
 	}
 
@@ -120,13 +574,10 @@ func createVKeyboardDevice(path string, name []byte) (fd *os.File, err error) {
 				Bustype: busUsb,
 				Vendor:  0x4711,
 				Product: 0x0815,
-				Version: 1}})
+				Version: 1}},
+		options)
 }
 
 func keyCodeInRange(key int) bool {
 	return key >= keyReserved && key <= keyMax
 }
-
-func (vk vKeyboard) FetchSyspath() (string, error) {
-	return fetchSyspath(vk.deviceFile)
-}
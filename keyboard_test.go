@@ -1,10 +1,30 @@
 package uinput
 
 import (
+	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"reflect"
+	"syscall"
 	"testing"
+	"time"
+)
+
+var (
+	_ StringTyper     = (*vKeyboard)(nil)
+	_ StringTyper     = noopKeyboard{}
+	_ NamedKeyPresser = (*vKeyboard)(nil)
+	_ NamedKeyPresser = noopKeyboard{}
+	_ KeyHolder       = (*vKeyboard)(nil)
+	_ KeyHolder       = noopKeyboard{}
+	_ KeyRepeater     = (*vKeyboard)(nil)
+	_ KeyRepeater     = noopKeyboard{}
+	_ ModifierLatcher = (*vKeyboard)(nil)
+	_ ModifierLatcher = noopKeyboard{}
 )
 
 // This test will confirm that basic key events are working.
@@ -192,3 +212,528 @@ func TestKeyboardSyspath(t *testing.T) {
 	}
 	t.Logf("Syspath: %s", sysPath)
 }
+
+func TestTypeString(t *testing.T) {
+	vk, err := CreateKeyboard("/dev/uinput", []byte("Test Basic Keyboard"))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual keyboard. Last error was: %s\n", err)
+	}
+	defer vk.Close()
+
+	err = vk.(StringTyper).TypeString("Hello, Café!")
+	if err != nil {
+		t.Fatalf("Failed to type string. Last error was: %s\n", err)
+	}
+}
+
+func TestSetComposeKeyFailsOutsideOfRange(t *testing.T) {
+	vk, err := CreateKeyboard("/dev/uinput", []byte("Test Basic Keyboard"))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual keyboard. Last error was: %s\n", err)
+	}
+	defer vk.Close()
+
+	err = vk.(StringTyper).SetComposeKey(keyMax + 1)
+	if err == nil {
+		t.Fatalf("Expected setting the compose key to fail due to invalid key code, but got no error.")
+	}
+}
+
+func TestTypeStringTypesLineBreaksAsSeparateReports(t *testing.T) {
+	file, stop := recordEvents(t)
+	vk := &vKeyboard{deviceBase: deviceBase{report: newReportBuilder(file)}, composeKey: KeyCompose}
+
+	err := vk.TypeString("a\n\tb\n")
+	if err != nil {
+		t.Fatalf("Failed to type string. Last error was: %s\n", err)
+	}
+
+	var expected []inputEvent
+	for _, key := range []uint16{KeyA, KeyEnter, KeyTab, KeyB, KeyEnter} {
+		expected = append(expected,
+			inputEvent{Type: evKey, Code: key, Value: btnStatePressed},
+			inputEvent{Type: evSyn, Code: synReport},
+			inputEvent{Type: evKey, Code: key, Value: btnStateReleased},
+			inputEvent{Type: evSyn, Code: synReport},
+		)
+	}
+	assertEvents(t, expected, stop())
+}
+
+func TestTypeStringDelayedWaitsBetweenKeys(t *testing.T) {
+	file, stop := recordEvents(t)
+	vk := &vKeyboard{deviceBase: deviceBase{report: newReportBuilder(file)}, composeKey: KeyCompose}
+
+	start := time.Now()
+	err := vk.TypeStringDelayed("abcde", 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to type string. Last error was: %s\n", err)
+	}
+	elapsed := time.Since(start)
+
+	// five key strokes, separated by four delays
+	if elapsed < 40*time.Millisecond {
+		t.Fatalf("Expected typing to take at least 40ms, but it took %v", elapsed)
+	}
+	if presses := countKeyEvents(stop(), btnStatePressed); presses != 5 {
+		t.Fatalf("Expected 5 key presses, but got %d", presses)
+	}
+}
+
+func TestTypeStringDelayedContextStopsOnCancellation(t *testing.T) {
+	file, stop := recordEvents(t)
+	vk := &vKeyboard{deviceBase: deviceBase{report: newReportBuilder(file)}, composeKey: KeyCompose}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 25*time.Millisecond)
+	defer cancel()
+	err := vk.TypeStringDelayedContext(ctx, "abcdefghij", 10*time.Millisecond)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Expected: %v\nActual: %v", context.DeadlineExceeded, err)
+	}
+
+	events := stop()
+	presses := countKeyEvents(events, btnStatePressed)
+	if presses == 0 || presses >= 10 {
+		t.Fatalf("Expected typing to be interrupted, but got %d key presses", presses)
+	}
+	if releases := countKeyEvents(events, btnStateReleased); releases != presses {
+		t.Fatalf("Expected every pressed key to be released, but got %d presses and %d releases", presses, releases)
+	}
+}
+
+// cancelingWriter cancels a context once the given number of writes has been passed on to the underlying writer.
+type cancelingWriter struct {
+	w      io.Writer
+	after  int
+	writes int
+	cancel context.CancelFunc
+}
+
+func (w *cancelingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.writes++
+	if w.writes == w.after {
+		w.cancel()
+	}
+	return n, err
+}
+
+func TestTypeStringContextReleasesKeysOnCancellation(t *testing.T) {
+	file, stop := recordEvents(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// cancel while the shift key and the key of the first character are held down
+	vk := &vKeyboard{deviceBase: deviceBase{report: newReportBuilder(&cancelingWriter{w: file, after: 2, cancel: cancel})}, composeKey: KeyCompose}
+
+	err := vk.TypeStringContext(ctx, "ABCDE")
+	if err != context.Canceled {
+		t.Fatalf("Expected: %v\nActual: %v", context.Canceled, err)
+	}
+
+	held := make(map[uint16]bool)
+	for _, ev := range stop() {
+		if ev.Type == evKey {
+			held[ev.Code] = ev.Value != btnStateReleased
+		}
+	}
+	for code, pressed := range held {
+		if pressed {
+			t.Fatalf("Expected all keys to be released after cancellation, but key %d is still held", code)
+		}
+	}
+	if len(held) != 2 {
+		t.Fatalf("Expected typing to stop after the first character, but got events of %d keys", len(held))
+	}
+}
+
+func countKeyEvents(events []recordedEvent, value int32) int {
+	count := 0
+	for _, ev := range events {
+		if ev.Type == evKey && ev.Value == value {
+			count++
+		}
+	}
+	return count
+}
+
+func TestHoldKeyReleasesKeyOnCancel(t *testing.T) {
+	file, stop := recordEvents(t)
+	vk := &vKeyboard{deviceBase: deviceBase{report: newReportBuilder(file)}, composeKey: KeyCompose}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- vk.HoldKey(ctx, KeyA)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	cancel()
+	err := <-done
+	if err != nil {
+		t.Fatalf("Failed to hold key. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: KeyA, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: KeyA, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestHoldKeyReleasesKeyOnTimeout(t *testing.T) {
+	file, stop := recordEvents(t)
+	vk := &vKeyboard{deviceBase: deviceBase{report: newReportBuilder(file)}, composeKey: KeyCompose}
+
+	hold := 50 * time.Millisecond
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), hold)
+	defer cancel()
+
+	err := vk.HoldKey(ctx, KeyB)
+	if err != nil {
+		t.Fatalf("Failed to hold key. Last error was: %s\n", err)
+	}
+
+	events := stop()
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: KeyB, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: KeyB, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, events)
+	if held := events[2].received.Sub(start); held < hold {
+		t.Fatalf("Expected key to be held for at least %v, but it was released after %v", hold, held)
+	}
+}
+
+func TestKeyHoldRepeatEmitsRepeatEvents(t *testing.T) {
+	file, stop := recordEvents(t)
+	vk := &vKeyboard{deviceBase: deviceBase{report: newReportBuilder(file)}, composeKey: KeyCompose}
+
+	err := vk.KeyHoldRepeat(KeyA, 2, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to hold key with repeats. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: KeyA, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: KeyA, Value: btnStateRepeated},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: KeyA, Value: btnStateRepeated},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: KeyA, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+// failingOnceWriter fails the write with the given number with EIO, and passes all others on to the underlying writer.
+type failingOnceWriter struct {
+	w      io.Writer
+	fail   int
+	writes int
+}
+
+func (w *failingOnceWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if w.writes == w.fail {
+		return 0, syscall.EIO
+	}
+	return w.w.Write(p)
+}
+
+func TestKeyHoldRepeatReleasesKeyIfRepeatFails(t *testing.T) {
+	file, stop := recordEvents(t)
+	// the press succeeds, while the first repeat fails
+	vk := &vKeyboard{deviceBase: deviceBase{report: newReportBuilder(&failingOnceWriter{w: file, fail: 2})}, composeKey: KeyCompose}
+
+	err := vk.KeyHoldRepeat(KeyA, 2, time.Millisecond)
+	if !errors.Is(err, syscall.EIO) {
+		t.Fatalf("Expected the repeat to fail with EIO, but got %v", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: KeyA, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: KeyA, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestKeyHoldRepeatFailsOnNegativeCount(t *testing.T) {
+	vk := &vKeyboard{composeKey: KeyCompose}
+
+	err := vk.KeyHoldRepeat(KeyA, -1, time.Millisecond)
+	if err == nil {
+		t.Fatalf("Expected KeyHoldRepeat to fail for a negative count, but got no error.")
+	}
+}
+
+func TestKeyboardRegistersOnlyRequestedKeys(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	keyboard, err := CreateKeyboard(path, []byte("Test Keypad"), WithKeys(Key1, Key2, KeyEnter))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual keyboard. Last error was: %s\n", err)
+	}
+	defer keyboard.Close()
+
+	if evBits := registeredCodes(*calls, uiSetEvBit); !reflect.DeepEqual(evBits, []uintptr{evKey}) {
+		t.Fatalf("Expected only EV_KEY to be registered, but got %v", evBits)
+	}
+	if keyBits := registeredCodes(*calls, uiSetKeyBit); !reflect.DeepEqual(keyBits, []uintptr{Key1, Key2, KeyEnter}) {
+		t.Fatalf("Expected only the requested keys to be registered, but got %v", keyBits)
+	}
+}
+
+func TestKeyboardRegistersAllKeysByDefault(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	keyboard, err := CreateKeyboard(path, []byte("Test Keyboard"))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual keyboard. Last error was: %s\n", err)
+	}
+	defer keyboard.Close()
+
+	if n := len(registeredCodes(*calls, uiSetKeyBit)); n != keyMax+1 {
+		t.Fatalf("Expected %d keys to be registered, but got %d", keyMax+1, n)
+	}
+}
+
+func TestKeyboardCreationFailsForKeyOutOfRange(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+
+	expected := "failed to register key. Code 1024 is not in range"
+	_, err := CreateKeyboard(path, []byte("Test Keyboard"), WithKeys(KeyA, 1024))
+	if err == nil || err.Error() != expected {
+		t.Fatalf("Expected: %s\nActual: %v", expected, err)
+	}
+}
+
+func TestKeyPressEmitsKeyCode(t *testing.T) {
+	file, stop := recordEvents(t)
+	vk := &vKeyboard{deviceBase: deviceBase{report: newReportBuilder(file)}, composeKey: KeyCompose}
+
+	err := vk.KeyPress(KeyEnter)
+	if err != nil {
+		t.Fatalf("Failed to send key press. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: KeyEnter, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: KeyEnter, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestTypeRuneFallsBackToHexEntry(t *testing.T) {
+	file, stop := recordEvents(t)
+	vk := &vKeyboard{deviceBase: deviceBase{report: newReportBuilder(file)}, composeKey: KeyCompose}
+
+	err := vk.TypeRune('∑')
+	if err != nil {
+		t.Fatalf("Failed to type rune. Last error was: %s\n", err)
+	}
+
+	var expected []inputEvent
+	keyEvents := func(key int, state int32) {
+		expected = append(expected,
+			inputEvent{Type: evKey, Code: uint16(key), Value: state},
+			inputEvent{Type: evSyn, Code: synReport})
+	}
+	keyEvents(KeyLeftctrl, btnStatePressed)
+	keyEvents(KeyLeftshift, btnStatePressed)
+	keyEvents(KeyU, btnStatePressed)
+	keyEvents(KeyU, btnStateReleased)
+	keyEvents(KeyLeftshift, btnStateReleased)
+	keyEvents(KeyLeftctrl, btnStateReleased)
+	// U+2211
+	for _, key := range []int{Key2, Key2, Key1, Key1, KeySpace} {
+		keyEvents(key, btnStatePressed)
+		keyEvents(key, btnStateReleased)
+	}
+	assertEvents(t, expected, stop())
+}
+
+func TestTypeRuneUsesLayoutIfPossible(t *testing.T) {
+	file, stop := recordEvents(t)
+	vk := &vKeyboard{deviceBase: deviceBase{report: newReportBuilder(file)}, composeKey: KeyCompose}
+
+	err := vk.TypeRune('a')
+	if err != nil {
+		t.Fatalf("Failed to type rune. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: KeyA, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: KeyA, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestClearModifiersReleasesLatchedModifiers(t *testing.T) {
+	file, stop := recordEvents(t)
+	vk := &vKeyboard{deviceBase: deviceBase{report: newReportBuilder(file)}, composeKey: KeyCompose}
+
+	err := vk.ModifierDown(KeyLeftshift)
+	if err != nil {
+		t.Fatalf("Failed to press modifier. Last error was: %s\n", err)
+	}
+	if !vk.IsModifierActive(KeyLeftshift) {
+		t.Fatalf("Expected shift to be active after pressing it")
+	}
+
+	err = vk.ClearModifiers()
+	if err != nil {
+		t.Fatalf("Failed to clear modifiers. Last error was: %s\n", err)
+	}
+	if vk.IsModifierActive(KeyLeftshift) {
+		t.Fatalf("Expected shift to be inactive after clearing the modifiers")
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: KeyLeftshift, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: KeyLeftshift, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestModifierDownRejectsRegularKeys(t *testing.T) {
+	vk := &vKeyboard{deviceBase: deviceBase{report: newReportBuilder(ioutil.Discard)}, composeKey: KeyCompose}
+
+	err := vk.ModifierDown(KeyA)
+	if err == nil {
+		t.Fatalf("Expected pressing a regular key as modifier to fail, but got no error")
+	}
+	if vk.IsModifierActive(KeyA) {
+		t.Fatalf("Expected the rejected key not to be latched")
+	}
+}
+
+func TestKeyPressEmitsScanCodeBeforeDownAndUp(t *testing.T) {
+	file, stop := recordEvents(t)
+	vk := &vKeyboard{deviceBase: deviceBase{report: newReportBuilder(file)}, composeKey: KeyCompose, scanCodes: true}
+
+	err := vk.KeyPress(KeyA)
+	if err != nil {
+		t.Fatalf("Failed to press key. Last error was: %s\n", err)
+	}
+	err = vk.KeyHoldRepeat(KeyEnter, 1, 0)
+	if err != nil {
+		t.Fatalf("Failed to hold key. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evMsc, Code: mscScan, Value: 0x70004},
+		{Type: evKey, Code: KeyA, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evMsc, Code: mscScan, Value: 0x70004},
+		{Type: evKey, Code: KeyA, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+		{Type: evMsc, Code: mscScan, Value: 0x70028},
+		{Type: evKey, Code: KeyEnter, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		// repeats carry no scan code
+		{Type: evKey, Code: KeyEnter, Value: btnStateRepeated},
+		{Type: evSyn, Code: synReport},
+		{Type: evMsc, Code: mscScan, Value: 0x70028},
+		{Type: evKey, Code: KeyEnter, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestKeyboardRegistersScanCodesIfEnabled(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	keyboard, err := CreateKeyboard(path, []byte("Test Keyboard"), WithKeys(KeyA), WithScanCodes())
+	if err != nil {
+		t.Fatalf("Failed to create the virtual keyboard. Last error was: %s\n", err)
+	}
+	defer keyboard.Close()
+
+	if evBits := registeredCodes(*calls, uiSetEvBit); !reflect.DeepEqual(evBits, []uintptr{evKey, evMsc}) {
+		t.Fatalf("Expected EV_KEY and EV_MSC to be registered, but got %v", evBits)
+	}
+	if mscBits := registeredCodes(*calls, uiSetMscBit); !reflect.DeepEqual(mscBits, []uintptr{mscScan}) {
+		t.Fatalf("Expected MSC_SCAN to be registered, but got %v", mscBits)
+	}
+}
+
+func TestKeyboardSetsInitialLEDs(t *testing.T) {
+	file, stop := recordEvents(t)
+	vk := &vKeyboard{deviceBase: deviceBase{report: newReportBuilder(file)}, composeKey: KeyCompose}
+
+	err := vk.setLEDs(LEDState{CapsLock: true, NumLock: false, ScrollLock: true})
+	if err != nil {
+		t.Fatalf("Failed to set LEDs. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evLed, Code: ledCapsL, Value: 1},
+		{Type: evLed, Code: ledNumL, Value: 0},
+		{Type: evLed, Code: ledScrollL, Value: 1},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestKeyboardEmitsInitialLEDsAfterCreation(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	keyboard, err := CreateKeyboard(path, []byte("Test Keyboard"), WithKeys(KeyA), WithInitialLEDs(false, true, false))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual keyboard. Last error was: %s\n", err)
+	}
+	defer keyboard.Close()
+
+	if evBits := registeredCodes(*calls, uiSetEvBit); !reflect.DeepEqual(evBits, []uintptr{evKey, evLed}) {
+		t.Fatalf("Expected EV_KEY and EV_LED to be registered, but got %v", evBits)
+	}
+	if ledBits := registeredCodes(*calls, uiSetLedBit); !reflect.DeepEqual(ledBits, []uintptr{ledNumL, ledCapsL, ledScrollL}) {
+		t.Fatalf("Expected the lock LEDs to be registered, but got %v", ledBits)
+	}
+
+	// the LED events are written to the fake device file right after the device setup
+	deviceFile, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open the fake device file. Last error was: %s\n", err)
+	}
+	defer deviceFile.Close()
+	var userDev uinputUserDev
+	var events []inputEvent
+	err = binary.Read(deviceFile, binary.LittleEndian, &userDev)
+	for err == nil {
+		var ev inputEvent
+		err = binary.Read(deviceFile, binary.LittleEndian, &ev)
+		if err == nil {
+			events = append(events, ev)
+		}
+	}
+
+	expected := []inputEvent{
+		{Type: evLed, Code: ledCapsL, Value: 0},
+		{Type: evLed, Code: ledNumL, Value: 1},
+		{Type: evLed, Code: ledScrollL, Value: 0},
+		{Type: evSyn, Code: synReport},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("Expected the initial LED events %v, but got %v", expected, events)
+	}
+}
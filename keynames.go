@@ -0,0 +1,311 @@
+package uinput
+
+import (
+	"fmt"
+	"strings"
+)
+
+// keyNames maps the names of the key codes, as they are defined in input-event-codes.h, to the key codes
+// of this package.
+var keyNames = map[string]int{
+	"KEY_ESC":              KeyEsc,
+	"KEY_1":                Key1,
+	"KEY_2":                Key2,
+	"KEY_3":                Key3,
+	"KEY_4":                Key4,
+	"KEY_5":                Key5,
+	"KEY_6":                Key6,
+	"KEY_7":                Key7,
+	"KEY_8":                Key8,
+	"KEY_9":                Key9,
+	"KEY_0":                Key0,
+	"KEY_MINUS":            KeyMinus,
+	"KEY_EQUAL":            KeyEqual,
+	"KEY_BACKSPACE":        KeyBackspace,
+	"KEY_TAB":              KeyTab,
+	"KEY_Q":                KeyQ,
+	"KEY_W":                KeyW,
+	"KEY_E":                KeyE,
+	"KEY_R":                KeyR,
+	"KEY_T":                KeyT,
+	"KEY_Y":                KeyY,
+	"KEY_U":                KeyU,
+	"KEY_I":                KeyI,
+	"KEY_O":                KeyO,
+	"KEY_P":                KeyP,
+	"KEY_LEFTBRACE":        KeyLeftbrace,
+	"KEY_RIGHTBRACE":       KeyRightbrace,
+	"KEY_ENTER":            KeyEnter,
+	"KEY_LEFTCTRL":         KeyLeftctrl,
+	"KEY_A":                KeyA,
+	"KEY_S":                KeyS,
+	"KEY_D":                KeyD,
+	"KEY_F":                KeyF,
+	"KEY_G":                KeyG,
+	"KEY_H":                KeyH,
+	"KEY_J":                KeyJ,
+	"KEY_K":                KeyK,
+	"KEY_L":                KeyL,
+	"KEY_SEMICOLON":        KeySemicolon,
+	"KEY_APOSTROPHE":       KeyApostrophe,
+	"KEY_GRAVE":            KeyGrave,
+	"KEY_LEFTSHIFT":        KeyLeftshift,
+	"KEY_BACKSLASH":        KeyBackslash,
+	"KEY_Z":                KeyZ,
+	"KEY_X":                KeyX,
+	"KEY_C":                KeyC,
+	"KEY_V":                KeyV,
+	"KEY_B":                KeyB,
+	"KEY_N":                KeyN,
+	"KEY_M":                KeyM,
+	"KEY_COMMA":            KeyComma,
+	"KEY_DOT":              KeyDot,
+	"KEY_SLASH":            KeySlash,
+	"KEY_RIGHTSHIFT":       KeyRightshift,
+	"KEY_KPASTERISK":       KeyKpasterisk,
+	"KEY_LEFTALT":          KeyLeftalt,
+	"KEY_SPACE":            KeySpace,
+	"KEY_CAPSLOCK":         KeyCapslock,
+	"KEY_F1":               KeyF1,
+	"KEY_F2":               KeyF2,
+	"KEY_F3":               KeyF3,
+	"KEY_F4":               KeyF4,
+	"KEY_F5":               KeyF5,
+	"KEY_F6":               KeyF6,
+	"KEY_F7":               KeyF7,
+	"KEY_F8":               KeyF8,
+	"KEY_F9":               KeyF9,
+	"KEY_F10":              KeyF10,
+	"KEY_NUMLOCK":          KeyNumlock,
+	"KEY_SCROLLLOCK":       KeyScrolllock,
+	"KEY_KP7":              KeyKp7,
+	"KEY_KP8":              KeyKp8,
+	"KEY_KP9":              KeyKp9,
+	"KEY_KPMINUS":          KeyKpminus,
+	"KEY_KP4":              KeyKp4,
+	"KEY_KP5":              KeyKp5,
+	"KEY_KP6":              KeyKp6,
+	"KEY_KPPLUS":           KeyKpplus,
+	"KEY_KP1":              KeyKp1,
+	"KEY_KP2":              KeyKp2,
+	"KEY_KP3":              KeyKp3,
+	"KEY_KP0":              KeyKp0,
+	"KEY_KPDOT":            KeyKpdot,
+	"KEY_ZENKAKUHANKAKU":   KeyZenkakuhankaku,
+	"KEY_102ND":            Key102Nd,
+	"KEY_F11":              KeyF11,
+	"KEY_F12":              KeyF12,
+	"KEY_RO":               KeyRo,
+	"KEY_KATAKANA":         KeyKatakana,
+	"KEY_HIRAGANA":         KeyHiragana,
+	"KEY_HENKAN":           KeyHenkan,
+	"KEY_KATAKANAHIRAGANA": KeyKatakanahiragana,
+	"KEY_MUHENKAN":         KeyMuhenkan,
+	"KEY_KPJPCOMMA":        KeyKpjpcomma,
+	"KEY_KPENTER":          KeyKpenter,
+	"KEY_RIGHTCTRL":        KeyRightctrl,
+	"KEY_KPSLASH":          KeyKpslash,
+	"KEY_SYSRQ":            KeySysrq,
+	"KEY_RIGHTALT":         KeyRightalt,
+	"KEY_LINEFEED":         KeyLinefeed,
+	"KEY_HOME":             KeyHome,
+	"KEY_UP":               KeyUp,
+	"KEY_PAGEUP":           KeyPageup,
+	"KEY_LEFT":             KeyLeft,
+	"KEY_RIGHT":            KeyRight,
+	"KEY_END":              KeyEnd,
+	"KEY_DOWN":             KeyDown,
+	"KEY_PAGEDOWN":         KeyPagedown,
+	"KEY_INSERT":           KeyInsert,
+	"KEY_DELETE":           KeyDelete,
+	"KEY_MACRO":            KeyMacro,
+	"KEY_MUTE":             KeyMute,
+	"KEY_VOLUMEDOWN":       KeyVolumedown,
+	"KEY_VOLUMEUP":         KeyVolumeup,
+	"KEY_POWER":            KeyPower,
+	"KEY_KPEQUAL":          KeyKpequal,
+	"KEY_KPPLUSMINUS":      KeyKpplusminus,
+	"KEY_PAUSE":            KeyPause,
+	"KEY_SCALE":            KeyScale,
+	"KEY_KPCOMMA":          KeyKpcomma,
+	"KEY_HANGEUL":          KeyHangeul,
+	"KEY_HANJA":            KeyHanja,
+	"KEY_YEN":              KeyYen,
+	"KEY_LEFTMETA":         KeyLeftmeta,
+	"KEY_RIGHTMETA":        KeyRightmeta,
+	"KEY_COMPOSE":          KeyCompose,
+	"KEY_STOP":             KeyStop,
+	"KEY_AGAIN":            KeyAgain,
+	"KEY_PROPS":            KeyProps,
+	"KEY_UNDO":             KeyUndo,
+	"KEY_FRONT":            KeyFront,
+	"KEY_COPY":             KeyCopy,
+	"KEY_OPEN":             KeyOpen,
+	"KEY_PASTE":            KeyPaste,
+	"KEY_FIND":             KeyFind,
+	"KEY_CUT":              KeyCut,
+	"KEY_HELP":             KeyHelp,
+	"KEY_MENU":             KeyMenu,
+	"KEY_CALC":             KeyCalc,
+	"KEY_SETUP":            KeySetup,
+	"KEY_SLEEP":            KeySleep,
+	"KEY_WAKEUP":           KeyWakeup,
+	"KEY_FILE":             KeyFile,
+	"KEY_SENDFILE":         KeySendfile,
+	"KEY_DELETEFILE":       KeyDeletefile,
+	"KEY_XFER":             KeyXfer,
+	"KEY_PROG1":            KeyProg1,
+	"KEY_PROG2":            KeyProg2,
+	"KEY_WWW":              KeyWww,
+	"KEY_MSDOS":            KeyMsdos,
+	"KEY_COFFEE":           KeyCoffee,
+	"KEY_DIRECTION":        KeyDirection,
+	"KEY_CYCLEWINDOWS":     KeyCyclewindows,
+	"KEY_MAIL":             KeyMail,
+	"KEY_BOOKMARKS":        KeyBookmarks,
+	"KEY_COMPUTER":         KeyComputer,
+	"KEY_BACK":             KeyBack,
+	"KEY_FORWARD":          KeyForward,
+	"KEY_CLOSECD":          KeyClosecd,
+	"KEY_EJECTCD":          KeyEjectcd,
+	"KEY_EJECTCLOSECD":     KeyEjectclosecd,
+	"KEY_NEXTSONG":         KeyNextsong,
+	"KEY_PLAYPAUSE":        KeyPlaypause,
+	"KEY_PREVIOUSSONG":     KeyPrevioussong,
+	"KEY_STOPCD":           KeyStopcd,
+	"KEY_RECORD":           KeyRecord,
+	"KEY_REWIND":           KeyRewind,
+	"KEY_PHONE":            KeyPhone,
+	"KEY_ISO":              KeyIso,
+	"KEY_CONFIG":           KeyConfig,
+	"KEY_HOMEPAGE":         KeyHomepage,
+	"KEY_REFRESH":          KeyRefresh,
+	"KEY_EXIT":             KeyExit,
+	"KEY_MOVE":             KeyMove,
+	"KEY_EDIT":             KeyEdit,
+	"KEY_SCROLLUP":         KeyScrollup,
+	"KEY_SCROLLDOWN":       KeyScrolldown,
+	"KEY_KPLEFTPAREN":      KeyKpleftparen,
+	"KEY_KPRIGHTPAREN":     KeyKprightparen,
+	"KEY_NEW":              KeyNew,
+	"KEY_REDO":             KeyRedo,
+	"KEY_F13":              KeyF13,
+	"KEY_F14":              KeyF14,
+	"KEY_F15":              KeyF15,
+	"KEY_F16":              KeyF16,
+	"KEY_F17":              KeyF17,
+	"KEY_F18":              KeyF18,
+	"KEY_F19":              KeyF19,
+	"KEY_F20":              KeyF20,
+	"KEY_F21":              KeyF21,
+	"KEY_F22":              KeyF22,
+	"KEY_F23":              KeyF23,
+	"KEY_F24":              KeyF24,
+	"KEY_PLAYCD":           KeyPlaycd,
+	"KEY_PAUSECD":          KeyPausecd,
+	"KEY_PROG3":            KeyProg3,
+	"KEY_PROG4":            KeyProg4,
+	"KEY_DASHBOARD":        KeyDashboard,
+	"KEY_SUSPEND":          KeySuspend,
+	"KEY_CLOSE":            KeyClose,
+	"KEY_PLAY":             KeyPlay,
+	"KEY_FASTFORWARD":      KeyFastforward,
+	"KEY_BASSBOOST":        KeyBassboost,
+	"KEY_PRINT":            KeyPrint,
+	"KEY_HP":               KeyHp,
+	"KEY_CAMERA":           KeyCamera,
+	"KEY_SOUND":            KeySound,
+	"KEY_QUESTION":         KeyQuestion,
+	"KEY_EMAIL":            KeyEmail,
+	"KEY_CHAT":             KeyChat,
+	"KEY_SEARCH":           KeySearch,
+	"KEY_CONNECT":          KeyConnect,
+	"KEY_FINANCE":          KeyFinance,
+	"KEY_SPORT":            KeySport,
+	"KEY_SHOP":             KeyShop,
+	"KEY_ALTERASE":         KeyAlterase,
+	"KEY_CANCEL":           KeyCancel,
+	"KEY_BRIGHTNESSDOWN":   KeyBrightnessdown,
+	"KEY_BRIGHTNESSUP":     KeyBrightnessup,
+	"KEY_MEDIA":            KeyMedia,
+	"KEY_SWITCHVIDEOMODE":  KeySwitchvideomode,
+	"KEY_KBDILLUMTOGGLE":   KeyKbdillumtoggle,
+	"KEY_KBDILLUMDOWN":     KeyKbdillumdown,
+	"KEY_KBDILLUMUP":       KeyKbdillumup,
+	"KEY_SEND":             KeySend,
+	"KEY_REPLY":            KeyReply,
+	"KEY_FORWARDMAIL":      KeyForwardmail,
+	"KEY_SAVE":             KeySave,
+	"KEY_DOCUMENTS":        KeyDocuments,
+	"KEY_BATTERY":          KeyBattery,
+	"KEY_BLUETOOTH":        KeyBluetooth,
+	"KEY_WLAN":             KeyWlan,
+	"KEY_UWB":              KeyUwb,
+	"KEY_UNKNOWN":          KeyUnknown,
+	"KEY_VIDEO_NEXT":       KeyVideoNext,
+	"KEY_VIDEO_PREV":       KeyVideoPrev,
+	"KEY_BRIGHTNESS_CYCLE": KeyBrightnessCycle,
+	"KEY_BRIGHTNESS_ZERO":  KeyBrightnessZero,
+	"KEY_DISPLAY_OFF":      KeyDisplayOff,
+	"KEY_WIMAX":            KeyWimax,
+	"KEY_RFKILL":           KeyRfkill,
+	"KEY_MICMUTE":          KeyMicmute,
+}
+
+// KeyByName returns the key code for the given key name, as it is defined in input-event-codes.h
+// (for example "KEY_ENTER"). The lookup is case-insensitive and the "KEY_" prefix may be omitted.
+// If no key with the given name exists, the returned error names the closest match.
+func KeyByName(name string) (int, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(name))
+	if !strings.HasPrefix(normalized, "KEY_") {
+		normalized = "KEY_" + normalized
+	}
+
+	if key, ok := keyNames[normalized]; ok {
+		return key, nil
+	}
+	return 0, fmt.Errorf("unknown key name %q, did you mean %q?", name, nearestKeyName(normalized))
+}
+
+// nearestKeyName returns the known key name with the smallest edit distance to the given name.
+func nearestKeyName(name string) string {
+	nearest := ""
+	nearestDistance := -1
+	for candidate := range keyNames {
+		distance := editDistance(name, candidate)
+		// prefer the lexically smaller name on ties, so that the result does not depend on the map order
+		if nearestDistance < 0 || distance < nearestDistance || (distance == nearestDistance && candidate < nearest) {
+			nearest = candidate
+			nearestDistance = distance
+		}
+	}
+	return nearest
+}
+
+// editDistance returns the Levenshtein distance between the given strings.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
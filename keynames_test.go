@@ -0,0 +1,66 @@
+package uinput
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeyByName(t *testing.T) {
+	for name, expected := range map[string]int{
+		"KEY_ENTER":      KeyEnter,
+		"KEY_A":          KeyA,
+		"KEY_LEFTSHIFT":  KeyLeftshift,
+		"KEY_102ND":      Key102Nd,
+		"KEY_VIDEO_NEXT": KeyVideoNext,
+		"key_esc":        KeyEsc,
+		"F12":            KeyF12,
+	} {
+		key, err := KeyByName(name)
+		if err != nil {
+			t.Fatalf("Failed to look up key %s. Last error was: %s\n", name, err)
+		}
+		if key != expected {
+			t.Fatalf("Expected %s to map to %d, but got %d", name, expected, key)
+		}
+	}
+}
+
+func TestKeyByNameSuggestsNearestMatch(t *testing.T) {
+	_, err := KeyByName("KEY_ENTR")
+	if err == nil {
+		t.Fatalf("Expected lookup of an unknown key name to fail, but got no error.")
+	}
+	if !strings.Contains(err.Error(), `did you mean "KEY_ENTER"?`) {
+		t.Fatalf("Expected error to suggest KEY_ENTER, but got: %s", err)
+	}
+}
+
+func TestKeyPressByNameFailsOnUnknownName(t *testing.T) {
+	file, stop := recordEvents(t)
+	vk := &vKeyboard{deviceBase: deviceBase{report: newReportBuilder(file)}, composeKey: KeyCompose}
+
+	err := vk.KeyPressByName("KEY_DOES_NOT_EXIST")
+	if err == nil {
+		t.Fatalf("Expected key press to fail due to unknown key name, but got no error.")
+	}
+	if events := stop(); len(events) != 0 {
+		t.Fatalf("Expected no events to be emitted, but got %d", len(events))
+	}
+}
+
+func TestKeyPressByNameEmitsKeyCode(t *testing.T) {
+	file, stop := recordEvents(t)
+	vk := &vKeyboard{deviceBase: deviceBase{report: newReportBuilder(file)}, composeKey: KeyCompose}
+
+	err := vk.KeyPressByName("KEY_ENTER")
+	if err != nil {
+		t.Fatalf("Failed to send key press. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: KeyEnter, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: KeyEnter, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
@@ -0,0 +1,131 @@
+package uinput
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// A keyStroke describes a single key press that is needed to produce a character, optionally
+// while holding down the shift and/or the control key.
+type keyStroke struct {
+	key   int
+	shift bool
+	ctrl  bool
+}
+
+// usLayout maps the printable ASCII characters to the key strokes that produce them on a US keyboard layout. Line
+// breaks and tabs are typed using the enter and the tab key, so that multi-line strings can be typed as well.
+var usLayout = map[rune]keyStroke{
+	'a': {key: KeyA}, 'b': {key: KeyB}, 'c': {key: KeyC}, 'd': {key: KeyD}, 'e': {key: KeyE},
+	'f': {key: KeyF}, 'g': {key: KeyG}, 'h': {key: KeyH}, 'i': {key: KeyI}, 'j': {key: KeyJ},
+	'k': {key: KeyK}, 'l': {key: KeyL}, 'm': {key: KeyM}, 'n': {key: KeyN}, 'o': {key: KeyO},
+	'p': {key: KeyP}, 'q': {key: KeyQ}, 'r': {key: KeyR}, 's': {key: KeyS}, 't': {key: KeyT},
+	'u': {key: KeyU}, 'v': {key: KeyV}, 'w': {key: KeyW}, 'x': {key: KeyX}, 'y': {key: KeyY},
+	'z': {key: KeyZ},
+
+	'A': {key: KeyA, shift: true}, 'B': {key: KeyB, shift: true}, 'C': {key: KeyC, shift: true}, 'D': {key: KeyD, shift: true},
+	'E': {key: KeyE, shift: true}, 'F': {key: KeyF, shift: true}, 'G': {key: KeyG, shift: true}, 'H': {key: KeyH, shift: true},
+	'I': {key: KeyI, shift: true}, 'J': {key: KeyJ, shift: true}, 'K': {key: KeyK, shift: true}, 'L': {key: KeyL, shift: true},
+	'M': {key: KeyM, shift: true}, 'N': {key: KeyN, shift: true}, 'O': {key: KeyO, shift: true}, 'P': {key: KeyP, shift: true},
+	'Q': {key: KeyQ, shift: true}, 'R': {key: KeyR, shift: true}, 'S': {key: KeyS, shift: true}, 'T': {key: KeyT, shift: true},
+	'U': {key: KeyU, shift: true}, 'V': {key: KeyV, shift: true}, 'W': {key: KeyW, shift: true}, 'X': {key: KeyX, shift: true},
+	'Y': {key: KeyY, shift: true}, 'Z': {key: KeyZ, shift: true},
+
+	'1': {key: Key1}, '2': {key: Key2}, '3': {key: Key3}, '4': {key: Key4}, '5': {key: Key5},
+	'6': {key: Key6}, '7': {key: Key7}, '8': {key: Key8}, '9': {key: Key9}, '0': {key: Key0},
+
+	'!': {key: Key1, shift: true}, '@': {key: Key2, shift: true}, '#': {key: Key3, shift: true}, '$': {key: Key4, shift: true},
+	'%': {key: Key5, shift: true}, '^': {key: Key6, shift: true}, '&': {key: Key7, shift: true}, '*': {key: Key8, shift: true},
+	'(': {key: Key9, shift: true}, ')': {key: Key0, shift: true},
+
+	' ':  {key: KeySpace},
+	'\n': {key: KeyEnter},
+	'\t': {key: KeyTab},
+	'-':  {key: KeyMinus},
+	'_':  {key: KeyMinus, shift: true},
+	'=':  {key: KeyEqual},
+	'+':  {key: KeyEqual, shift: true},
+	'[':  {key: KeyLeftbrace},
+	'{':  {key: KeyLeftbrace, shift: true},
+	']':  {key: KeyRightbrace},
+	'}':  {key: KeyRightbrace, shift: true},
+	'\\': {key: KeyBackslash},
+	'|':  {key: KeyBackslash, shift: true},
+	';':  {key: KeySemicolon},
+	':':  {key: KeySemicolon, shift: true},
+	'\'': {key: KeyApostrophe},
+	'"':  {key: KeyApostrophe, shift: true},
+	'`':  {key: KeyGrave},
+	'~':  {key: KeyGrave, shift: true},
+	',':  {key: KeyComma},
+	'<':  {key: KeyComma, shift: true},
+	'.':  {key: KeyDot},
+	'>':  {key: KeyDot, shift: true},
+	'/':  {key: KeySlash},
+	'?':  {key: KeySlash, shift: true},
+}
+
+// composeSequences maps characters that can not be typed directly on a US keyboard layout to the characters
+// that need to be typed after the compose key in order to produce them. The sequences are taken from the
+// default X11 compose table (see Compose(5)), which is used by most desktop environments.
+var composeSequences = map[rune]string{
+	'à': "`a", 'á': "'a", 'â': "^a", 'ã': "~a", 'ä': "\"a", 'å': "oa", 'æ': "ae",
+	'À': "`A", 'Á': "'A", 'Â': "^A", 'Ã': "~A", 'Ä': "\"A", 'Å': "oA", 'Æ': "AE",
+	'è': "`e", 'é': "'e", 'ê': "^e", 'ë': "\"e",
+	'È': "`E", 'É': "'E", 'Ê': "^E", 'Ë': "\"E",
+	'ì': "`i", 'í': "'i", 'î': "^i", 'ï': "\"i",
+	'Ì': "`I", 'Í': "'I", 'Î': "^I", 'Ï': "\"I",
+	'ò': "`o", 'ó': "'o", 'ô': "^o", 'õ': "~o", 'ö': "\"o", 'ø': "/o",
+	'Ò': "`O", 'Ó': "'O", 'Ô': "^O", 'Õ': "~O", 'Ö': "\"O", 'Ø': "/O",
+	'ù': "`u", 'ú': "'u", 'û': "^u", 'ü': "\"u",
+	'Ù': "`U", 'Ú': "'U", 'Û': "^U", 'Ü': "\"U",
+	'ý': "'y", 'ÿ': "\"y", 'Ý': "'Y",
+	'ç': ",c", 'Ç': ",C",
+	'ñ': "~n", 'Ñ': "~N",
+	'ß': "ss",
+	'€': "=e",
+	'£': "-L",
+	'¥': "=Y",
+	'¢': "|c",
+	'©': "oc",
+	'®': "or",
+	'°': "oo",
+	'¿': "??",
+	'¡': "!!",
+	'«': "<<",
+	'»': ">>",
+}
+
+// keyStrokesFor returns the key strokes that will produce the given character. Characters that are not part
+// of the keyboard layout are looked up in the compose table and will be prefixed with the given compose key.
+func keyStrokesFor(char rune, composeKey int) ([]keyStroke, error) {
+	if stroke, ok := usLayout[char]; ok {
+		return []keyStroke{stroke}, nil
+	}
+
+	sequence, ok := composeSequences[char]
+	if !ok {
+		return nil, fmt.Errorf("no key mapping found for character %q", char)
+	}
+
+	strokes := []keyStroke{{key: composeKey}}
+	for _, c := range sequence {
+		stroke, ok := usLayout[c]
+		if !ok {
+			return nil, fmt.Errorf("compose sequence for character %q contains unmapped character %q", char, c)
+		}
+		strokes = append(strokes, stroke)
+	}
+	return strokes, nil
+}
+
+// hexEntryStrokes returns the key strokes that enter the given character by its code point: Ctrl+Shift+U starts
+// the entry, followed by the hexadecimal digits of the code point and a space that commits the character. This
+// is supported by IBus and GTK applications, but not universally.
+func hexEntryStrokes(char rune) []keyStroke {
+	strokes := []keyStroke{{key: KeyU, shift: true, ctrl: true}}
+	for _, digit := range strconv.FormatInt(int64(char), 16) {
+		strokes = append(strokes, usLayout[digit])
+	}
+	return append(strokes, keyStroke{key: KeySpace})
+}
@@ -0,0 +1,58 @@
+package uinput
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAccentedCharacterExpandsToComposeSequence(t *testing.T) {
+	expected := []keyStroke{{key: KeyCompose}, {key: KeyApostrophe}, {key: KeyE}}
+	strokes, err := keyStrokesFor('é', KeyCompose)
+	if err != nil {
+		t.Fatalf("Failed to look up key strokes. Last error was: %s\n", err)
+	}
+	if !reflect.DeepEqual(strokes, expected) {
+		t.Fatalf("Expected: %v\nActual: %v", expected, strokes)
+	}
+}
+
+func TestComposeSequenceUsesConfiguredComposeKey(t *testing.T) {
+	expected := []keyStroke{{key: KeyRightalt}, {key: KeyApostrophe, shift: true}, {key: KeyU, shift: true}}
+	strokes, err := keyStrokesFor('Ü', KeyRightalt)
+	if err != nil {
+		t.Fatalf("Failed to look up key strokes. Last error was: %s\n", err)
+	}
+	if !reflect.DeepEqual(strokes, expected) {
+		t.Fatalf("Expected: %v\nActual: %v", expected, strokes)
+	}
+}
+
+func TestLayoutCharacterDoesNotUseComposeKey(t *testing.T) {
+	expected := []keyStroke{{key: KeyA, shift: true}}
+	strokes, err := keyStrokesFor('A', KeyCompose)
+	if err != nil {
+		t.Fatalf("Failed to look up key strokes. Last error was: %s\n", err)
+	}
+	if !reflect.DeepEqual(strokes, expected) {
+		t.Fatalf("Expected: %v\nActual: %v", expected, strokes)
+	}
+}
+
+func TestHexEntryStrokesEncodeCodePoint(t *testing.T) {
+	expected := []keyStroke{
+		{key: KeyU, shift: true, ctrl: true},
+		{key: Key2}, {key: Key6}, {key: Key0}, {key: Key3},
+		{key: KeySpace},
+	}
+	strokes := hexEntryStrokes('☃')
+	if !reflect.DeepEqual(strokes, expected) {
+		t.Fatalf("Expected: %v\nActual: %v", expected, strokes)
+	}
+}
+
+func TestUnmappedCharacterFails(t *testing.T) {
+	_, err := keyStrokesFor('☃', KeyCompose)
+	if err == nil {
+		t.Fatalf("Expected key stroke lookup to fail for unmapped character, but got no error.")
+	}
+}
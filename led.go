@@ -0,0 +1,110 @@
+package uinput
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// LEDState holds the states of the lock LEDs of a keyboard (see ReadLEDChanges).
+type LEDState struct {
+	CapsLock   bool
+	NumLock    bool
+	ScrollLock bool
+}
+
+// An LEDReader receives the states of the lock LEDs that the host sets. The keyboards created by this package
+// implement LEDReader.
+type LEDReader interface {
+	// ReadLEDChanges returns a channel that delivers the state of the lock LEDs whenever the host changes it, e.g.
+	// because Caps Lock has been toggled. The LEDs need to be registered using WithLEDs or WithInitialLEDs,
+	// otherwise the channel is closed right away.
+	ReadLEDChanges() <-chan LEDState
+}
+
+// setLEDs reports the given states of the lock LEDs as a single report.
+func (vk *vKeyboard) setLEDs(state LEDState) error {
+	err := vk.report.send(
+		inputEvent{Type: evLed, Code: ledCapsL, Value: ledValue(state.CapsLock)},
+		inputEvent{Type: evLed, Code: ledNumL, Value: ledValue(state.NumLock)},
+		inputEvent{Type: evLed, Code: ledScrollL, Value: ledValue(state.ScrollLock)},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set the initial LED states: %w", err)
+	}
+	return nil
+}
+
+// ledValue returns the value of an EV_LED event for the given state.
+func ledValue(on bool) int32 {
+	if on {
+		return 1
+	}
+	return 0
+}
+
+// ReadLEDChanges returns a channel that delivers the state of the lock LEDs whenever the host changes it. The
+// events are read from the device file in the background, starting with the first call; all calls return the
+// same channel, which is closed once the device is closed. Only the latest state is kept if the caller does not
+// keep up with the changes.
+func (vk *vKeyboard) ReadLEDChanges() <-chan LEDState {
+	vk.ledsOnce.Do(func() {
+		if vk.ledSource == nil {
+			vk.leds = closedLEDChanges()
+			return
+		}
+		vk.leds = make(chan LEDState, 1)
+		go vk.readLEDs(vk.ledState)
+	})
+	return vk.leds
+}
+
+// closedLEDChanges returns a closed channel, which is returned by ReadLEDChanges if the LEDs are not registered.
+func closedLEDChanges() chan LEDState {
+	leds := make(chan LEDState)
+	close(leds)
+	return leds
+}
+
+// readLEDs decodes the EV_LED events that the kernel sends to the device until reading fails, which happens once
+// the device file is closed. Events of other types, like force feedback requests, are skipped.
+func (vk *vKeyboard) readLEDs(state LEDState) {
+	defer close(vk.leds)
+	for {
+		var ev inputEvent
+		err := binary.Read(vk.ledSource, binary.LittleEndian, &ev)
+		if err != nil {
+			return
+		}
+		if !updateLEDState(&state, ev) {
+			continue
+		}
+		// the reader is the only sender, so the send can not block once the stale state has been dropped
+		select {
+		case vk.leds <- state:
+		default:
+			select {
+			case <-vk.leds:
+			default:
+			}
+			vk.leds <- state
+		}
+	}
+}
+
+// updateLEDState applies the given event to the state and reports whether it is an event of one of the lock LEDs.
+func updateLEDState(state *LEDState, ev inputEvent) bool {
+	if ev.Type != evLed {
+		return false
+	}
+	switch ev.Code {
+	case ledCapsL:
+		state.CapsLock = ev.Value != 0
+	case ledNumL:
+		state.NumLock = ev.Value != 0
+	case ledScrollL:
+		state.ScrollLock = ev.Value != 0
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package uinput
+
+import (
+	"encoding/binary"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	_ LEDReader = (*vKeyboard)(nil)
+	_ LEDReader = noopKeyboard{}
+)
+
+func writeRequest(t *testing.T, w *os.File, ev inputEvent) {
+	err := binary.Write(w, binary.LittleEndian, ev)
+	if err != nil {
+		t.Fatalf("Failed to write request. Last error was: %s\n", err)
+	}
+}
+
+func nextLEDState(t *testing.T, leds <-chan LEDState) LEDState {
+	t.Helper()
+	select {
+	case state, ok := <-leds:
+		if !ok {
+			t.Fatalf("Expected an LED state, but the channel was closed.")
+		}
+		return state
+	case <-time.After(time.Second):
+		t.Fatalf("Expected an LED state, but none was delivered.")
+	}
+	return LEDState{}
+}
+
+func TestReadLEDChangesDeliversNewState(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to setup test. Unable to create pipe: %v", err)
+	}
+	defer r.Close()
+	vk := &vKeyboard{ledSource: r, ledState: LEDState{ScrollLock: true}}
+	leds := vk.ReadLEDChanges()
+
+	writeRequest(t, w, inputEvent{Type: evLed, Code: ledCapsL, Value: 1})
+	if state := nextLEDState(t, leds); state != (LEDState{CapsLock: true, ScrollLock: true}) {
+		t.Fatalf("Expected caps lock to be on, but got %+v", state)
+	}
+
+	// events other than those of the lock LEDs are skipped
+	writeRequest(t, w, inputEvent{Type: evUinput, Code: uiFFUpload, Value: 1})
+	writeRequest(t, w, inputEvent{Type: evLed, Code: ledNumL, Value: 1})
+	if state := nextLEDState(t, leds); state != (LEDState{CapsLock: true, NumLock: true, ScrollLock: true}) {
+		t.Fatalf("Expected num lock to be on, but got %+v", state)
+	}
+
+	w.Close()
+	select {
+	case _, ok := <-leds:
+		if ok {
+			t.Fatalf("Expected the channel to be closed once reading stops.")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the channel to be closed once reading stops, but it is still open.")
+	}
+}
+
+func TestReadLEDChangesIsClosedWithoutLEDs(t *testing.T) {
+	vk := &vKeyboard{}
+	if _, ok := <-vk.ReadLEDChanges(); ok {
+		t.Fatalf("Expected the channel of a keyboard without LEDs to be closed.")
+	}
+}
+
+func TestKeyboardRegistersLEDs(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	keyboard, err := CreateKeyboard(path, []byte("Test Keyboard"), WithKeys(KeyA), WithLEDs())
+	if err != nil {
+		t.Fatalf("Failed to create the virtual keyboard. Last error was: %s\n", err)
+	}
+	defer keyboard.Close()
+
+	if evBits := registeredCodes(*calls, uiSetEvBit); !reflect.DeepEqual(evBits, []uintptr{evKey, evLed}) {
+		t.Fatalf("Expected EV_KEY and EV_LED to be registered, but got %v", evBits)
+	}
+	if ledBits := registeredCodes(*calls, uiSetLedBit); !reflect.DeepEqual(ledBits, []uintptr{ledNumL, ledCapsL, ledScrollL}) {
+		t.Fatalf("Expected the lock LEDs to be registered, but got %v", ledBits)
+	}
+}
@@ -0,0 +1,31 @@
+//go:build go1.21
+
+package uinput
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+var _ Logger = (*slog.Logger)(nil)
+
+func TestSlogLoggerReceivesCreation(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	_, restore := fakeIoctl(nil)
+	defer restore()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	dial, err := CreateDial(path, []byte("Test Dial"), WithLogger(logger))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual dial. Last error was: %s\n", err)
+	}
+	defer dial.Close()
+
+	if !strings.Contains(buf.String(), `level=INFO msg="created input device" device="Test Dial"`) {
+		t.Fatalf("Expected the creation to be logged, but got %q", buf.String())
+	}
+}
@@ -1,12 +1,72 @@
 package uinput
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"sync"
 	"syscall"
+	"time"
 )
 
+// MouseButton identifies one of the buttons of a mouse.
+type MouseButton int
+
+const (
+	MouseButtonLeft   MouseButton = evMouseBtnLeft
+	MouseButtonRight  MouseButton = evMouseBtnRight
+	MouseButtonMiddle MouseButton = evMouseBtnMiddle
+)
+
+// defaultMouseButtons are the buttons that a mouse registers unless WithMouseButtons is used.
+var defaultMouseButtons = []MouseButton{MouseButtonLeft, MouseButtonRight, MouseButtonMiddle}
+
+// String returns the name of the button as it is defined in input-event-codes.h (e.g. BTN_LEFT).
+func (b MouseButton) String() string {
+	switch b {
+	case MouseButtonLeft:
+		return "BTN_LEFT"
+	case MouseButtonRight:
+		return "BTN_RIGHT"
+	case MouseButtonMiddle:
+		return "BTN_MIDDLE"
+	}
+	return fmt.Sprintf("button %d", int(b))
+}
+
+// WheelMode determines how the wheel movements of Wheel are emitted (see WithWheelMode).
+type WheelMode int
+
+const (
+	// WheelAccumulated emits a wheel movement of N notches as a single event with the value N. This is the default.
+	WheelAccumulated WheelMode = iota
+	// WheelNotched emits a wheel movement of N notches as N reports with the value 1 (or -1) each, for consumers
+	// that only count wheel events instead of evaluating their value. Movements of more than 4096 notches are
+	// rejected.
+	WheelNotched
+)
+
+// velocityInterval is the time between two movements of MoveAtVelocity.
+const velocityInterval = 8 * time.Millisecond
+
+// inertiaInterval is the time between two wheel movements of WheelInertia.
+const inertiaInterval = 8 * time.Millisecond
+
+// validateVelocity checks that the given velocity is finite, and that the distance it covers within a single report
+// fits into an event, given the number of units that a velocity of one covers per report.
+func validateVelocity(velocity float64, unitsPerReport float64) error {
+	if math.IsNaN(velocity) || math.IsInf(velocity, 0) {
+		return fmt.Errorf("%v is out of range. Expected a finite velocity", velocity)
+	}
+	if math.Abs(velocity)*unitsPerReport >= math.MaxInt32 {
+		return fmt.Errorf("%v is out of range. A single report may cover at most %d units", velocity, math.MaxInt32)
+	}
+	return nil
+}
+
 // A Mouse is a device that will trigger an absolute change event.
 // For details see: https://www.kernel.org/doc/Documentation/input/event-codes.txt
 type Mouse interface {
@@ -70,14 +130,157 @@ type Mouse interface {
 	io.Closer
 }
 
+// A SmoothScroller splits a wheel movement into several steps that are issued over time. The mice created by this
+// package implement SmoothScroller.
+type SmoothScroller interface {
+	// ScrollSmooth will split the given high-resolution vertical wheel movement into the given number of steps,
+	// which are issued with the given interval in between, resulting in a momentum-like scroll.
+	ScrollSmooth(delta int32, steps int, interval time.Duration) error
+
+	// ScrollSmoothContext works like ScrollSmooth, but stops scrolling once the given context is done.
+	// In this case the error of the context is returned.
+	ScrollSmoothContext(ctx context.Context, delta int32, steps int, interval time.Duration) error
+}
+
+// A ButtonHolder holds a button down for as long as a context lasts. The mice created by this package implement
+// ButtonHolder.
+type ButtonHolder interface {
+	// HoldButton will press the given button and block until the context is done, then release the button
+	// again. Combined with a timeout or a cancel function, this allows holding a button (e.g. for drag and
+	// drop) without having to ensure the release manually.
+	HoldButton(ctx context.Context, button MouseButton) error
+}
+
+// A ScrollClicker scrolls by moving the pointer while the middle button is held down. The mice created by this
+// package implement ScrollClicker.
+type ScrollClicker interface {
+	// ScrollClick will press the middle button, move the pointer by the given distance and release the button
+	// again. Applications that support autoscroll will scroll in the direction of the movement.
+	ScrollClick(x, y int32) error
+}
+
+// A VelocityMover moves the pointer at a given speed rather than by a given distance. The mice created by this
+// package implement VelocityMover.
+type VelocityMover interface {
+	// MoveAtVelocity will move the pointer at the given velocity in pixels per second for the given duration,
+	// by emitting relative movements at a fixed rate (every 8ms, like a 125Hz USB mouse). This is useful in order
+	// to test pointer acceleration, which depends on the speed of the movement rather than on its distance.
+	MoveAtVelocity(vx, vy float64, d time.Duration) error
+
+	// MoveAtVelocityContext works like MoveAtVelocity, but stops moving once the given context is done.
+	// In this case the error of the context is returned.
+	MoveAtVelocityContext(ctx context.Context, vx, vy float64, d time.Duration) error
+}
+
+// An AxisMover moves the pointer along a single axis in either direction, depending on the sign of the distance.
+// The mice created by this package implement AxisMover.
+type AxisMover interface {
+	// MoveX will move the mouse cursor horizontally by the given number of pixel: positive values move it to the
+	// right, negative values to the left.
+	MoveX(delta int32) error
+
+	// MoveY will move the mouse cursor vertically by the given number of pixel: positive values move it down,
+	// negative values up.
+	MoveY(delta int32) error
+}
+
+// A FineMover moves the pointer by fractions of a pixel. The mice created by this package implement FineMover.
+type FineMover interface {
+	// MoveRelFine will move the mouse pointer by fractions of a pixel, e.g. in order to bridge the motion of a
+	// high-DPI source. The fractional parts are accumulated per axis, and whole pixels are emitted once accrued.
+	MoveRelFine(dx, dy float64) error
+
+	// FineRemainder returns the fractions of a pixel that have been accumulated by MoveRelFine, but not emitted yet.
+	FineRemainder() (x, y float64)
+}
+
+// An InertiaScroller scrolls like a wheel that keeps spinning after it has been flicked. The mice created by this
+// package implement InertiaScroller.
+type InertiaScroller interface {
+	// WheelInertia will simulate kinetic scrolling: starting at the given velocity (in wheel notches per second,
+	// positive values scroll up), the vertical wheel decelerates at the given rate (in notches per second²) until
+	// it comes to a halt.
+	WheelInertia(initialVelocity float64, decel float64) error
+
+	// WheelInertiaContext works like WheelInertia, but stops scrolling once the given context is done.
+	// In this case the error of the context is returned.
+	WheelInertiaContext(ctx context.Context, initialVelocity float64, decel float64) error
+}
+
+// An AutoFirer clicks a button repeatedly at a fixed rate. The mice created by this package implement AutoFirer.
+type AutoFirer interface {
+	// AutoFire will click the given button the given number of times, starting a click every interval. This
+	// simulates the auto-fire of gaming mice. The count must be positive, use AutoFireContext in order to fire
+	// until cancelled.
+	AutoFire(button MouseButton, interval time.Duration, count int) error
+
+	// AutoFireContext works like AutoFire, but stops firing once the given context is done, in which case the
+	// error of the context is returned. A count of zero fires until the context is done.
+	AutoFireContext(ctx context.Context, button MouseButton, interval time.Duration, count int) error
+}
+
+// A PathDragger drags the pointer along a recorded path. The mice created by this package implement PathDragger.
+type PathDragger interface {
+	// DragPath will press the left button, move the pointer by each of the given points in turn, which are relative
+	// to the previous position (e.g. as recorded from a gesture), and release the button again.
+	DragPath(points []Point) error
+}
+
 type vMouse struct {
-	name       []byte
-	deviceFile *os.File
+	deviceBase
+	scanCodes     bool
+	naturalScroll bool
+	wheelMode     WheelMode
+	// clickDelay is the time between the press and the release of a click
+	clickDelay time.Duration
+	// maxRelMove is the largest movement that is sent in a single event, or zero if movements are not split
+	maxRelMove int32
+	// fine accumulates the fractional movements of MoveRelFine
+	fine *fineMotion
+	// buttons are the registered buttons, which are the only ones that can be pressed
+	buttons []MouseButton
+}
+
+// fineMotionPrecision is the precision (in fractions of a pixel) to which the remainders of MoveRelFine are
+// rounded, so that the rounding errors of the floating point deltas do not swallow pixels: ten movements by 0.3
+// must add up to exactly three pixels.
+const fineMotionPrecision = 1e9
+
+// fineMotion holds the fractions of a pixel that have been accumulated per axis (see MoveRelFine).
+type fineMotion struct {
+	mu   sync.Mutex
+	x, y float64
+}
+
+// add accumulates the given movement and returns the whole pixels that have accrued, keeping the remainders.
+func (f *fineMotion) add(dx, dy float64) (int32, int32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.x = math.Round((f.x+dx)*fineMotionPrecision) / fineMotionPrecision
+	f.y = math.Round((f.y+dy)*fineMotionPrecision) / fineMotionPrecision
+	px, py := math.Trunc(f.x), math.Trunc(f.y)
+	f.x -= px
+	f.y -= py
+	return int32(px), int32(py)
+}
+
+func (f *fineMotion) remainder() (float64, float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.x, f.y
+}
+
+// mouseScanCodes maps the mouse buttons to the scan codes (HID usages of the button page) that are
+// reported by USB mice.
+var mouseScanCodes = map[int]int32{
+	evMouseBtnLeft:   0x90001,
+	evMouseBtnRight:  0x90002,
+	evMouseBtnMiddle: 0x90003,
 }
 
 // CreateMouse will create a new mouse input device. A mouse is a device that allows relative input.
 // Relative input means that all changes to the x and y coordinates of the mouse pointer will be
-func CreateMouse(path string, name []byte) (Mouse, error) {
+func CreateMouse(path string, name []byte, opts ...Option) (Mouse, error) {
 	err := validateDevicePath(path)
 	if err != nil {
 		return nil, err
@@ -87,12 +290,56 @@ func CreateMouse(path string, name []byte) (Mouse, error) {
 		return nil, err
 	}
 
-	fd, err := createMouse(path, name)
+	options := newDeviceOptions(opts)
+	err = validateMouseButtons(options.buttons)
+	if err != nil {
+		return nil, err
+	}
+	fd, err := createMouse(path, name, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return vMouse{
+		deviceBase:    deviceBase{name: name, deviceFile: fd, report: newReportBuilderWithOptions(fd, name, options)},
+		scanCodes:     options.scanCodes,
+		naturalScroll: options.naturalScroll,
+		wheelMode:     options.wheelMode,
+		clickDelay:    options.clickDelay,
+		maxRelMove:    options.maxRelMove,
+		fine:          &fineMotion{},
+		buttons:       mouseButtons(options),
+	}, nil
+}
+
+// CreateMouseWriter will create a mouse that serializes all input events to the given writer instead of
+// sending them to a uinput device. The events are written in the same binary format that would be written to
+// /dev/uinput, which makes it possible to validate event sequences without moving the actual cursor (dry run).
+// Closing the mouse will not close the writer, and FetchSyspath will always return an error.
+func CreateMouseWriter(w io.Writer, name []byte, opts ...Option) (Mouse, error) {
+	if w == nil {
+		return nil, errors.New("writer must not be nil")
+	}
+	err := validateUinputName(name)
 	if err != nil {
 		return nil, err
 	}
 
-	return vMouse{name: name, deviceFile: fd}, nil
+	options := newDeviceOptions(opts)
+	err = validateMouseButtons(options.buttons)
+	if err != nil {
+		return nil, err
+	}
+	return vMouse{
+		deviceBase:    deviceBase{name: name, report: newReportBuilderWithOptions(w, name, options)},
+		scanCodes:     options.scanCodes,
+		naturalScroll: options.naturalScroll,
+		wheelMode:     options.wheelMode,
+		clickDelay:    options.clickDelay,
+		maxRelMove:    options.maxRelMove,
+		fine:          &fineMotion{},
+		buttons:       mouseButtons(options),
+	}, nil
 }
 
 // MoveLeft will move the cursor left by the number of pixel specified.
@@ -100,7 +347,7 @@ func (vRel vMouse) MoveLeft(pixel int32) error {
 	if err := assertNotNegative(pixel); err != nil {
 		return err
 	}
-	return sendRelEvent(vRel.deviceFile, relX, -pixel)
+	return vRel.MoveX(-pixel)
 }
 
 // MoveRight will move the cursor right by the number of pixel specified.
@@ -108,7 +355,7 @@ func (vRel vMouse) MoveRight(pixel int32) error {
 	if err := assertNotNegative(pixel); err != nil {
 		return err
 	}
-	return sendRelEvent(vRel.deviceFile, relX, pixel)
+	return vRel.MoveX(pixel)
 }
 
 // MoveUp will move the cursor up by the number of pixel specified.
@@ -116,7 +363,7 @@ func (vRel vMouse) MoveUp(pixel int32) error {
 	if err := assertNotNegative(pixel); err != nil {
 		return err
 	}
-	return sendRelEvent(vRel.deviceFile, relY, -pixel)
+	return vRel.MoveY(-pixel)
 }
 
 // MoveDown will move the cursor down by the number of pixel specified.
@@ -124,92 +371,310 @@ func (vRel vMouse) MoveDown(pixel int32) error {
 	if err := assertNotNegative(pixel); err != nil {
 		return err
 	}
-	return sendRelEvent(vRel.deviceFile, relY, pixel)
+	return vRel.MoveY(pixel)
+}
+
+// MoveX will move the cursor along the x axis, where the sign of the delta determines the direction.
+func (vRel vMouse) MoveX(delta int32) error {
+	return vRel.sendMove(relX, delta)
+}
+
+// MoveY will move the cursor along the y axis, where the sign of the delta determines the direction.
+func (vRel vMouse) MoveY(delta int32) error {
+	return vRel.sendMove(relY, delta)
 }
 
 // Move will perform a move of the mouse pointer along the x and y axes relative to the current position as requested.
 // Note that the upper left corner is (0, 0), so positive x and y means moving right (x) and down (y), whereas negative
 // values will cause a move towards the upper left corner.
 func (vRel vMouse) Move(x, y int32) error {
-	if err := sendRelEvent(vRel.deviceFile, relX, x); err != nil {
-		return fmt.Errorf("Failed to move pointer along x axis: %v", err)
+	if err := vRel.sendMove(relX, x); err != nil {
+		return fmt.Errorf("Failed to move pointer along x axis: %w", err)
+	}
+	if err := vRel.sendMove(relY, y); err != nil {
+		return fmt.Errorf("Failed to move pointer along y axis: %w", err)
+	}
+	return nil
+}
+
+// MoveAtVelocity will move the pointer at the given velocity for the given duration.
+func (vRel vMouse) MoveAtVelocity(vx, vy float64, d time.Duration) error {
+	return vRel.MoveAtVelocityContext(context.Background(), vx, vy, d)
+}
+
+// MoveAtVelocityContext will move the pointer at the given velocity until the duration has passed or the context
+// is done. Every movement covers the distance up to the end of its interval, rounded to whole pixels, so that
+// the rounding errors do not add up: the total displacement matches velocity × duration. Movements that exceed
+// the maximum of WithMaxRelMove are split like those of Move.
+func (vRel vMouse) MoveAtVelocityContext(ctx context.Context, vx, vy float64, d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("%v is out of range. Expected a positive duration", d)
 	}
-	if err := sendRelEvent(vRel.deviceFile, relY, y); err != nil {
-		return fmt.Errorf("Failed to move pointer along y axis: %v", err)
+	for _, v := range []float64{vx, vy} {
+		err := validateVelocity(v, velocityInterval.Seconds())
+		if err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(velocityInterval)
+	defer ticker.Stop()
+
+	// the distances are kept as floats, which can not overflow, while every single step fits into an event
+	var movedX, movedY float64
+	steps := int((d + velocityInterval - 1) / velocityInterval)
+	for i := 1; i <= steps; i++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		elapsed := time.Duration(i) * velocityInterval
+		if elapsed > d {
+			elapsed = d
+		}
+		targetX := math.Round(vx * elapsed.Seconds())
+		targetY := math.Round(vy * elapsed.Seconds())
+
+		err := vRel.sendRelMove(int32(targetX-movedX), int32(targetY-movedY))
+		if err != nil {
+			return fmt.Errorf("Failed to move pointer at velocity: %w", err)
+		}
+		movedX, movedY = targetX, targetY
 	}
 	return nil
 }
 
+// sendRelMove reports the given movement along both axes within a single report, leaving out axes without any
+// movement. Movements that exceed the maximum of WithMaxRelMove are split like those of Move instead.
+func (vRel vMouse) sendRelMove(dx, dy int32) error {
+	if vRel.maxRelMove > 0 && (dx > vRel.maxRelMove || dx < -vRel.maxRelMove ||
+		dy > vRel.maxRelMove || dy < -vRel.maxRelMove) {
+		return vRel.Move(dx, dy)
+	}
+
+	var events []inputEvent
+	if dx != 0 {
+		events = append(events, inputEvent{Type: evRel, Code: relX, Value: dx})
+	}
+	if dy != 0 {
+		events = append(events, inputEvent{Type: evRel, Code: relY, Value: dy})
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	return vRel.report.send(events...)
+}
+
+// MoveRelFine will move the pointer by fractions of a pixel. The movements are accumulated per axis, and whole
+// pixels are emitted as REL_X and REL_Y once accrued (truncated towards zero), so that no movement gets lost.
+// The fractions that remain can be inspected using FineRemainder.
+func (vRel vMouse) MoveRelFine(dx, dy float64) error {
+	px, py := vRel.fine.add(dx, dy)
+
+	var events []inputEvent
+	if px != 0 {
+		events = append(events, inputEvent{Type: evRel, Code: relX, Value: px})
+	}
+	if py != 0 {
+		events = append(events, inputEvent{Type: evRel, Code: relY, Value: py})
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	err := vRel.report.send(events...)
+	if err != nil {
+		return fmt.Errorf("Failed to move pointer: %w", err)
+	}
+	return nil
+}
+
+// FineRemainder returns the fractions of a pixel that have been accumulated by MoveRelFine, but not emitted yet.
+func (vRel vMouse) FineRemainder() (x, y float64) {
+	return vRel.fine.remainder()
+}
+
 // LeftClick will issue a LeftClick.
 func (vRel vMouse) LeftClick() error {
-	err := sendBtnEvent(vRel.deviceFile, []int{evMouseBtnLeft}, btnStatePressed)
+	err := vRel.sendButton(evMouseBtnLeft, btnStatePressed)
 	if err != nil {
-		return fmt.Errorf("Failed to issue the LeftClick event: %v", err)
+		return fmt.Errorf("Failed to issue the LeftClick event: %w", err)
 	}
+	time.Sleep(vRel.clickDelay)
 
-	return sendBtnEvent(vRel.deviceFile, []int{evMouseBtnLeft}, btnStateReleased)
+	return vRel.sendButton(evMouseBtnLeft, btnStateReleased)
 }
 
 // RightClick will issue a RightClick
 func (vRel vMouse) RightClick() error {
-	err := sendBtnEvent(vRel.deviceFile, []int{evMouseBtnRight}, btnStatePressed)
+	err := vRel.sendButton(evMouseBtnRight, btnStatePressed)
 	if err != nil {
-		return fmt.Errorf("Failed to issue the RightClick event: %v", err)
+		return fmt.Errorf("Failed to issue the RightClick event: %w", err)
 	}
+	time.Sleep(vRel.clickDelay)
 
-	return sendBtnEvent(vRel.deviceFile, []int{evMouseBtnRight}, btnStateReleased)
+	return vRel.sendButton(evMouseBtnRight, btnStateReleased)
 }
 
 // MiddleClick will issue a MiddleClick
 func (vRel vMouse) MiddleClick() error {
-	err := sendBtnEvent(vRel.deviceFile, []int{evMouseBtnMiddle}, btnStatePressed)
+	err := vRel.sendButton(evMouseBtnMiddle, btnStatePressed)
 	if err != nil {
-		return fmt.Errorf("Failed to issue the MiddleClick event: %v", err)
+		return fmt.Errorf("Failed to issue the MiddleClick event: %w", err)
 	}
+	time.Sleep(vRel.clickDelay)
 
-	return sendBtnEvent(vRel.deviceFile, []int{evMouseBtnMiddle}, btnStateReleased)
+	return vRel.sendButton(evMouseBtnMiddle, btnStateReleased)
 }
 
 // LeftPress will simulate a press of the left mouse button. Note that the button will not be released until
 // LeftRelease is invoked.
 func (vRel vMouse) LeftPress() error {
-	return sendBtnEvent(vRel.deviceFile, []int{evMouseBtnLeft}, btnStatePressed)
+	return vRel.sendButton(evMouseBtnLeft, btnStatePressed)
 }
 
 // LeftRelease will simulate the release of the left mouse button.
 func (vRel vMouse) LeftRelease() error {
-	return sendBtnEvent(vRel.deviceFile, []int{evMouseBtnLeft}, btnStateReleased)
+	return vRel.sendButton(evMouseBtnLeft, btnStateReleased)
 }
 
 // RightPress will simulate the press of the right mouse button. Note that the button will not be released until
 // RightRelease is invoked.
 func (vRel vMouse) RightPress() error {
-	return sendBtnEvent(vRel.deviceFile, []int{evMouseBtnRight}, btnStatePressed)
+	return vRel.sendButton(evMouseBtnRight, btnStatePressed)
 }
 
 // RightRelease will simulate the release of the right mouse button.
 func (vRel vMouse) RightRelease() error {
-	return sendBtnEvent(vRel.deviceFile, []int{evMouseBtnRight}, btnStateReleased)
+	return vRel.sendButton(evMouseBtnRight, btnStateReleased)
 }
 
 // MiddlePress will simulate the press of the middle mouse button. Note that the button will not be released until
 // MiddleRelease is invoked.
 func (vRel vMouse) MiddlePress() error {
-	return sendBtnEvent(vRel.deviceFile, []int{evMouseBtnMiddle}, btnStatePressed)
+	return vRel.sendButton(evMouseBtnMiddle, btnStatePressed)
 }
 
 // MiddleRelease will simulate the release of the middle mouse button.
 func (vRel vMouse) MiddleRelease() error {
-	return sendBtnEvent(vRel.deviceFile, []int{evMouseBtnMiddle}, btnStateReleased)
+	return vRel.sendButton(evMouseBtnMiddle, btnStateReleased)
+}
+
+// ScrollClick will issue a middle button drag, which emulates autoscroll. The button is released even if the
+// movement fails.
+func (vRel vMouse) ScrollClick(x, y int32) error {
+	err := vRel.sendButton(evMouseBtnMiddle, btnStatePressed)
+	if err != nil {
+		return fmt.Errorf("Failed to press the middle button: %w", err)
+	}
+
+	moveErr := vRel.Move(x, y)
+	err = vRel.sendButton(evMouseBtnMiddle, btnStateReleased)
+	if moveErr != nil {
+		return moveErr
+	}
+	return err
+}
+
+// DragPath will press the left button, move the pointer by every delta and release the button, even if a move fails.
+func (vRel vMouse) DragPath(points []Point) error {
+	err := vRel.sendButton(evMouseBtnLeft, btnStatePressed)
+	if err != nil {
+		return fmt.Errorf("Failed to press the left button: %w", err)
+	}
+
+	var moveErr error
+	for _, p := range points {
+		moveErr = vRel.Move(p.X, p.Y)
+		if moveErr != nil {
+			break
+		}
+	}
+	err = vRel.sendButton(evMouseBtnLeft, btnStateReleased)
+	if moveErr != nil {
+		return moveErr
+	}
+	return err
+}
+
+// HoldButton will press the given button until the context is done. The button is released exactly once, no
+// matter how often the context is canceled.
+func (vRel vMouse) HoldButton(ctx context.Context, button MouseButton) error {
+	switch button {
+	case MouseButtonLeft, MouseButtonRight, MouseButtonMiddle:
+	default:
+		return fmt.Errorf("failed to hold button. Code %d is not a mouse button", button)
+	}
+
+	err := vRel.sendButton(int(button), btnStatePressed)
+	if err != nil {
+		return fmt.Errorf("Failed to press button: %w", err)
+	}
+	<-ctx.Done()
+	return vRel.sendButton(int(button), btnStateReleased)
+}
+
+// AutoFire will click the given button repeatedly (see AutoFireContext).
+func (vRel vMouse) AutoFire(button MouseButton, interval time.Duration, count int) error {
+	if count <= 0 {
+		return fmt.Errorf("%d is out of range. Expected a positive number of clicks", count)
+	}
+	return vRel.AutoFireContext(context.Background(), button, interval, count)
+}
+
+// AutoFireContext will click the given button repeatedly until the count is reached or the context is done. Each
+// click is completed before the context is checked again, so that the button is never left pressed.
+func (vRel vMouse) AutoFireContext(ctx context.Context, button MouseButton, interval time.Duration, count int) error {
+	if count < 0 {
+		return fmt.Errorf("%d is out of range. Expected a non-negative number of clicks", count)
+	}
+	if interval <= 0 {
+		return fmt.Errorf("%v is out of range. Expected a positive interval", interval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for fired := 0; count == 0 || fired < count; fired++ {
+		if fired > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := vRel.sendButton(int(button), btnStatePressed)
+		if err != nil {
+			return fmt.Errorf("Failed to issue auto-fire click: %w", err)
+		}
+		time.Sleep(vRel.clickDelay)
+		err = vRel.sendButton(int(button), btnStateReleased)
+		if err != nil {
+			return fmt.Errorf("Failed to issue auto-fire click: %w", err)
+		}
+	}
+	return nil
 }
 
-// Wheel will simulate a wheel movement.
+// Wheel will simulate a wheel movement. Depending on the wheel mode, the movement is emitted as a single event or
+// as one report per notch.
 func (vRel vMouse) Wheel(horizontal bool, delta int32) error {
 	w := relWheel
 	if horizontal {
 		w = relHWheel
 	}
-	return sendRelEvent(vRel.deviceFile, uint16(w), delta)
+	delta = vRel.wheelDelta(delta)
+	if vRel.wheelMode != WheelNotched {
+		return sendRelEvent(vRel.report, uint16(w), delta)
+	}
+	return splitRelMove(delta, 1, func(notch int32) error {
+		return sendRelEvent(vRel.report, uint16(w), notch)
+	})
 }
 
 // WheelHighRes will simulate a wheel movement with high resolution.
@@ -218,39 +683,156 @@ func (vRel vMouse) WheelHighRes(horizontal bool, delta int32) error {
 	if horizontal {
 		w = relHWheelHiRes
 	}
-	return sendRelEvent(vRel.deviceFile, uint16(w), delta)
+	return sendRelEvent(vRel.report, uint16(w), vRel.wheelDelta(delta))
+}
+
+// ScrollSmooth will simulate a smooth vertical wheel movement using high-resolution wheel events.
+func (vRel vMouse) ScrollSmooth(delta int32, steps int, interval time.Duration) error {
+	return vRel.ScrollSmoothContext(context.Background(), delta, steps, interval)
+}
+
+// ScrollSmoothContext will simulate a smooth vertical wheel movement until the context is done.
+// The delta is distributed as evenly as possible, so that the sum of all steps matches it exactly.
+func (vRel vMouse) ScrollSmoothContext(ctx context.Context, delta int32, steps int, interval time.Duration) error {
+	if steps <= 0 {
+		return fmt.Errorf("%d is out of range. Expected a positive number of steps", steps)
+	}
+
+	for i := 0; i < steps; i++ {
+		if i > 0 && interval > 0 {
+			timer := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		step := smoothStep(delta, steps, i)
+		if step == 0 {
+			continue
+		}
+		err := sendRelEvent(vRel.report, relWheelHiRes, vRel.wheelDelta(step))
+		if err != nil {
+			return fmt.Errorf("Failed to issue smooth scroll step: %w", err)
+		}
+	}
+	return nil
+}
+
+// WheelInertia will simulate kinetic scrolling using high-resolution wheel events.
+func (vRel vMouse) WheelInertia(initialVelocity float64, decel float64) error {
+	return vRel.WheelInertiaContext(context.Background(), initialVelocity, decel)
+}
+
+// WheelInertiaContext will simulate kinetic scrolling until the wheel comes to a halt or the context is done. Every
+// tick is a single report, whose high-resolution delta is the distance covered at the current velocity within the
+// tick. Since the velocity decreases linearly, the deltas decrease as well, and the scrolling lasts for
+// initialVelocity / decel seconds.
+func (vRel vMouse) WheelInertiaContext(ctx context.Context, initialVelocity float64, decel float64) error {
+	if math.IsNaN(decel) || decel <= 0 {
+		return fmt.Errorf("%v is out of range. Expected a positive deceleration", decel)
+	}
+	err := validateVelocity(initialVelocity, inertiaInterval.Seconds()*hiResPerDetent)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(inertiaInterval)
+	defer ticker.Stop()
+
+	speed := math.Abs(initialVelocity)
+	for speed > 0 {
+		step := int32(math.Round(speed * inertiaInterval.Seconds() * hiResPerDetent))
+		if initialVelocity < 0 {
+			step = -step
+		}
+		if step != 0 {
+			err := sendRelEvent(vRel.report, relWheelHiRes, vRel.wheelDelta(step))
+			if err != nil {
+				return fmt.Errorf("Failed to issue inertia scroll step: %w", err)
+			}
+		}
+
+		speed -= decel * inertiaInterval.Seconds()
+		if speed <= 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// wheelDelta returns the delta of a wheel movement in the direction that the mouse is configured for. Note that
+// the inverse of math.MinInt32 is out of range, which is why it is mapped to math.MaxInt32.
+func (vRel vMouse) wheelDelta(delta int32) int32 {
+	if !vRel.naturalScroll {
+		return delta
+	}
+	if delta == math.MinInt32 {
+		return math.MaxInt32
+	}
+	return -delta
+}
+
+// smoothStep returns the share of the delta for the given step, such that the rounding errors are spread
+// evenly across all steps.
+func smoothStep(delta int32, steps int, step int) int32 {
+	total := int64(delta)
+	return int32(total*int64(step+1)/int64(steps) - total*int64(step)/int64(steps))
 }
 
-// Close closes the device and releases the device.
-func (vRel vMouse) Close() error {
-	return closeDevice(vRel.deviceFile)
+// Reset releases all buttons that are held down (see Resetter).
+func (vRel vMouse) Reset() error {
+	return vRel.report.reset()
 }
 
-func createMouse(path string, name []byte) (fd *os.File, err error) {
+func createMouse(path string, name []byte, options deviceOptions) (fd *os.File, err error) {
 	deviceFile, err := createDeviceFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("could not create relative axis input device: %v", err)
+		return nil, fmt.Errorf("could not create relative axis input device: %w", err)
 	}
 
 	err = registerDevice(deviceFile, uintptr(evKey))
 	if err != nil {
 		deviceFile.Close()
-		return nil, fmt.Errorf("failed to register key device: %v", err)
+		return nil, fmt.Errorf("failed to register key device: %w", err)
 	}
 
 	// register button events (in order to enable left, right and middle click)
-	for _, event := range []int{evMouseBtnLeft, evMouseBtnRight, evMouseBtnMiddle} {
+	for _, event := range mouseButtons(options) {
 		err = ioctl(deviceFile, uiSetKeyBit, uintptr(event))
 		if err != nil {
 			deviceFile.Close()
-			return nil, fmt.Errorf("failed to register click event %v: %v", event, err)
+			return nil, fmt.Errorf("failed to register click event %v: %w", event, err)
+		}
+	}
+
+	if options.scanCodes {
+		err = registerDevice(deviceFile, uintptr(evMsc))
+		if err != nil {
+			deviceFile.Close()
+			return nil, fmt.Errorf("failed to register misc device: %w", err)
+		}
+		err = ioctl(deviceFile, uiSetMscBit, uintptr(mscScan))
+		if err != nil {
+			deviceFile.Close()
+			return nil, fmt.Errorf("failed to register scan code event: %w", err)
 		}
 	}
 
 	err = registerDevice(deviceFile, uintptr(evRel))
 	if err != nil {
 		deviceFile.Close()
-		return nil, fmt.Errorf("failed to register relative axis input device: %v", err)
+		return nil, fmt.Errorf("failed to register relative axis input device: %w", err)
 	}
 
 	// register relative events
@@ -258,7 +840,7 @@ func createMouse(path string, name []byte) (fd *os.File, err error) {
 		err = ioctl(deviceFile, uiSetRelBit, uintptr(event))
 		if err != nil {
 			deviceFile.Close()
-			return nil, fmt.Errorf("failed to register relative event %v: %v", event, err)
+			return nil, fmt.Errorf("failed to register relative event %v: %w", event, err)
 		}
 	}
 
@@ -269,36 +851,123 @@ func createMouse(path string, name []byte) (fd *os.File, err error) {
 				Bustype: busUsb,
 				Vendor:  0x4711,
 				Product: 0x0816,
-				Version: 1}})
+				Version: 1}},
+		options)
+}
+
+// mouseButtons returns the buttons that the mouse registers.
+func mouseButtons(options deviceOptions) []MouseButton {
+	if len(options.buttons) == 0 {
+		return defaultMouseButtons
+	}
+	return options.buttons
 }
 
-func sendRelEvent(deviceFile *os.File, eventCode uint16, pixel int32) error {
-	iev := inputEvent{
+// validateMouseButtons returns an error if one of the given buttons is not a mouse button.
+func validateMouseButtons(buttons []MouseButton) error {
+	for _, button := range buttons {
+		if indexOfButton(defaultMouseButtons, button) < 0 {
+			return fmt.Errorf("failed to register button. Code %d is not a mouse button", button)
+		}
+	}
+	return nil
+}
+
+func indexOfButton(buttons []MouseButton, button MouseButton) int {
+	for i, b := range buttons {
+		if b == button {
+			return i
+		}
+	}
+	return -1
+}
+
+// sendButton issues a single button event. If scan codes are enabled, the scan code of the button
+// precedes the button event within the same report, just like it does for real hardware.
+func (vRel vMouse) sendButton(button int, btnState int) error {
+	if vRel.buttons != nil && indexOfButton(vRel.buttons, MouseButton(button)) < 0 {
+		return fmt.Errorf("%v is not registered (registered buttons: %v)", MouseButton(button), vRel.buttons)
+	}
+	var events []inputEvent
+	if vRel.scanCodes {
+		events = append(events, inputEvent{
+			Time:  syscall.Timeval{Sec: 0, Usec: 0},
+			Type:  evMsc,
+			Code:  mscScan,
+			Value: mouseScanCodes[button]})
+	}
+	events = append(events, inputEvent{
 		Time:  syscall.Timeval{Sec: 0, Usec: 0},
-		Type:  evRel,
-		Code:  eventCode,
-		Value: pixel}
+		Type:  evKey,
+		Code:  uint16(button),
+		Value: int32(btnState)})
 
-	buf, err := inputEventToBuffer(iev)
+	err := vRel.report.send(events...)
 	if err != nil {
-		return fmt.Errorf("writing abs event failed: %v", err)
+		return fmt.Errorf("writing btnEvent structure to the device file failed: %w", err)
 	}
+	return nil
+}
 
-	_, err = deviceFile.Write(buf)
-	if err != nil {
-		return fmt.Errorf("failed to write rel event to device file: %v", err)
+// sendMove sends a movement along the given axis. Movements that exceed the maximum set by WithMaxRelMove are split
+// into multiple reports.
+func (vRel vMouse) sendMove(eventCode uint16, pixel int32) error {
+	return splitRelMove(pixel, vRel.maxRelMove, func(step int32) error {
+		return sendRelEvent(vRel.report, eventCode, step)
+	})
+}
+
+// maxRelSteps is the maximum number of reports that a single movement is split into (see splitRelMove), so that
+// a large movement with a small maximum does not flood the device with reports.
+const maxRelSteps = 4096
+
+// splitRelMove splits the given movement into steps whose magnitude does not exceed max, all but the last one
+// having the maximum magnitude, and sends them one after another. A max of zero or less disables splitting.
+// Movements that require more than maxRelSteps steps are rejected before anything is sent.
+func splitRelMove(pixel int32, max int32, send func(step int32) error) error {
+	if max <= 0 || (pixel <= max && pixel >= -max) {
+		return send(pixel)
+	}
+	sign := int64(1)
+	remaining := int64(pixel)
+	if remaining < 0 {
+		sign = -1
+		remaining = -remaining
+	}
+	if steps := (remaining + int64(max) - 1) / int64(max); steps > maxRelSteps {
+		return fmt.Errorf("movement of %d is out of range. Steps of at most %d would require %d reports, "+
+			"but at most %d are allowed", pixel, max, steps, maxRelSteps)
 	}
+	for remaining > 0 {
+		step := remaining
+		if step > int64(max) {
+			step = int64(max)
+		}
+		if err := send(int32(sign * step)); err != nil {
+			return err
+		}
+		remaining -= step
+	}
+	return nil
+}
 
-	return syncEvents(deviceFile)
+func sendRelEvent(report *reportBuilder, eventCode uint16, pixel int32) error {
+	err := report.send(inputEvent{
+		Time:  syscall.Timeval{Sec: 0, Usec: 0},
+		Type:  evRel,
+		Code:  eventCode,
+		Value: pixel})
+	if err != nil {
+		return fmt.Errorf("failed to write rel event to device file: %w", err)
+	}
+	return nil
 }
 
+// assertNotNegative is used by the directional move functions before negating the given value. Rejecting
+// negative values ensures that the negation can not overflow, as -math.MaxInt32 is still a valid int32.
 func assertNotNegative(val int32) error {
 	if val < 0 {
 		return fmt.Errorf("%v is out of range. Expected a positive or zero value", val)
 	}
 	return nil
 }
-
-func (vRel vMouse) FetchSyspath() (string, error) {
-	return fetchSyspath(vRel.deviceFile)
-}
@@ -1,10 +1,37 @@
 package uinput
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"os"
+	"reflect"
 	"testing"
+	"time"
+)
+
+var (
+	_ SmoothScroller  = vMouse{}
+	_ SmoothScroller  = noopMouse{}
+	_ ButtonHolder    = vMouse{}
+	_ ButtonHolder    = noopMouse{}
+	_ ScrollClicker   = vMouse{}
+	_ ScrollClicker   = noopMouse{}
+	_ VelocityMover   = vMouse{}
+	_ VelocityMover   = noopMouse{}
+	_ AxisMover       = vMouse{}
+	_ AxisMover       = noopMouse{}
+	_ FineMover       = vMouse{}
+	_ FineMover       = noopMouse{}
+	_ InertiaScroller = vMouse{}
+	_ InertiaScroller = noopMouse{}
+	_ AutoFirer       = vMouse{}
+	_ AutoFirer       = noopMouse{}
+	_ PathDragger     = vMouse{}
+	_ PathDragger     = noopMouse{}
 )
 
 // This test confirms that all basic mouse moves are working as expected.
@@ -476,3 +503,889 @@ func TestMouseSyspath(t *testing.T) {
 	}
 	t.Logf("Syspath: %s", sysPath)
 }
+
+func TestDirectionalMovesRejectMinInt32(t *testing.T) {
+	// the value is rejected before any event is written, so no device is needed here
+	relDev := vMouse{}
+	for name, move := range map[string]func(int32) error{
+		"MoveLeft":  relDev.MoveLeft,
+		"MoveRight": relDev.MoveRight,
+		"MoveUp":    relDev.MoveUp,
+		"MoveDown":  relDev.MoveDown,
+	} {
+		err := move(math.MinInt32)
+		if err == nil {
+			t.Fatalf("Expected %s to fail for math.MinInt32, but got no error.", name)
+		}
+	}
+}
+
+func TestMouseWriterSerializesEvents(t *testing.T) {
+	var buf bytes.Buffer
+	relDev, err := CreateMouseWriter(&buf, []byte("Test Dry Run Mouse"))
+	if err != nil {
+		t.Fatalf("Failed to create the dry run mouse. Last error was: %s\n", err)
+	}
+
+	err = relDev.MoveRight(42)
+	if err != nil {
+		t.Fatalf("Failed to move mouse right. Last error was: %s\n", err)
+	}
+	err = relDev.LeftClick()
+	if err != nil {
+		t.Fatalf("Failed to issue left click. Last error was: %s\n", err)
+	}
+	err = relDev.Close()
+	if err != nil {
+		t.Fatalf("Failed to close dry run mouse. Last error was: %s\n", err)
+	}
+
+	var expected []byte
+	for _, ev := range []inputEvent{
+		{Type: evRel, Code: relX, Value: 42},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: evMouseBtnLeft, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: evMouseBtnLeft, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	} {
+		b, err := inputEventToBuffer(ev)
+		if err != nil {
+			t.Fatalf("Failed to setup test. Unable to serialize event: %v", err)
+		}
+		expected = append(expected, b...)
+	}
+
+	if !bytes.Equal(expected, buf.Bytes()) {
+		t.Fatalf("Serialized events do not match.\nExpected: %v\nActual: %v", expected, buf.Bytes())
+	}
+}
+
+func TestMouseWriterHasNoSyspath(t *testing.T) {
+	relDev, err := CreateMouseWriter(ioutil.Discard, []byte("Test Dry Run Mouse"))
+	if err != nil {
+		t.Fatalf("Failed to create the dry run mouse. Last error was: %s\n", err)
+	}
+
+	_, err = relDev.FetchSyspath()
+	if err == nil {
+		t.Fatalf("Expected FetchSyspath to fail for a dry run mouse, but got no error.")
+	}
+}
+
+func TestMouseWriterCreationFailsOnNilWriter(t *testing.T) {
+	expected := "writer must not be nil"
+	_, err := CreateMouseWriter(nil, []byte("Test Dry Run Mouse"))
+	if err == nil || err.Error() != expected {
+		t.Fatalf("Expected: %s\nActual: %v", expected, err)
+	}
+}
+
+func TestScrollSmoothEmitsRequestedDelta(t *testing.T) {
+	for _, delta := range []int32{250, -250, 3} {
+		file, stop := recordEvents(t)
+		relDev, err := CreateMouseWriter(file, []byte("Test Dry Run Mouse"))
+		if err != nil {
+			t.Fatalf("Failed to create the dry run mouse. Last error was: %s\n", err)
+		}
+
+		err = relDev.(SmoothScroller).ScrollSmooth(delta, 7, time.Millisecond)
+		if err != nil {
+			t.Fatalf("Failed to perform smooth scroll. Last error was: %s\n", err)
+		}
+
+		var sum int32
+		for _, ev := range stop() {
+			if ev.Type == evRel {
+				if ev.Code != relWheelHiRes {
+					t.Fatalf("Expected only high-resolution wheel events, but got code %d", ev.Code)
+				}
+				sum += ev.Value
+			}
+		}
+		if sum != delta {
+			t.Fatalf("Expected the steps to sum up to %d, but got %d", delta, sum)
+		}
+	}
+}
+
+func TestScrollSmoothDistributesDeltaEvenly(t *testing.T) {
+	var steps []int32
+	for i := 0; i < 4; i++ {
+		steps = append(steps, smoothStep(10, 4, i))
+	}
+	for _, step := range steps {
+		if step < 2 || step > 3 {
+			t.Fatalf("Expected every step to be 2 or 3, but got %v", steps)
+		}
+	}
+}
+
+func TestScrollSmoothContextStopsOnCancellation(t *testing.T) {
+	relDev, err := CreateMouseWriter(ioutil.Discard, []byte("Test Dry Run Mouse"))
+	if err != nil {
+		t.Fatalf("Failed to create the dry run mouse. Last error was: %s\n", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = relDev.(SmoothScroller).ScrollSmoothContext(ctx, 120, 10, time.Millisecond)
+	if err != context.Canceled {
+		t.Fatalf("Expected: %v\nActual: %v", context.Canceled, err)
+	}
+}
+
+func TestScrollSmoothFailsOnInvalidSteps(t *testing.T) {
+	relDev, err := CreateMouseWriter(ioutil.Discard, []byte("Test Dry Run Mouse"))
+	if err != nil {
+		t.Fatalf("Failed to create the dry run mouse. Last error was: %s\n", err)
+	}
+
+	err = relDev.(SmoothScroller).ScrollSmooth(120, 0, time.Millisecond)
+	if err == nil {
+		t.Fatalf("Expected smooth scroll to fail for zero steps, but got no error.")
+	}
+}
+
+func TestHoldButtonReleasesButtonOnCancel(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse := vMouse{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- mouse.HoldButton(ctx, MouseButtonLeft)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	cancel()
+	err := <-done
+	if err != nil {
+		t.Fatalf("Failed to hold button. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: evMouseBtnLeft, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: evMouseBtnLeft, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestHoldButtonReleasesButtonOnTimeout(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse := vMouse{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	hold := 50 * time.Millisecond
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), hold)
+	defer cancel()
+
+	err := mouse.HoldButton(ctx, MouseButtonRight)
+	if err != nil {
+		t.Fatalf("Failed to hold button. Last error was: %s\n", err)
+	}
+
+	events := stop()
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: evMouseBtnRight, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: evMouseBtnRight, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, events)
+	if held := events[2].received.Sub(start); held < hold {
+		t.Fatalf("Expected button to be held for at least %v, but it was released after %v", hold, held)
+	}
+}
+
+func TestHoldButtonFailsForUnknownButton(t *testing.T) {
+	mouse := vMouse{deviceBase: deviceBase{report: newReportBuilder(&bytes.Buffer{})}}
+
+	err := mouse.HoldButton(context.Background(), MouseButton(KeyA))
+	if err == nil {
+		t.Fatalf("Expected holding a non-mouse button to fail, but got no error.")
+	}
+}
+
+func TestClickEmitsScanCodeBeforeButtonEvent(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse, err := CreateMouseWriter(file, []byte("Test Mouse"), WithScanCodes())
+	if err != nil {
+		t.Fatalf("Failed to create the dry run mouse. Last error was: %s\n", err)
+	}
+
+	err = mouse.LeftClick()
+	if err != nil {
+		t.Fatalf("Failed to issue left click. Last error was: %s\n", err)
+	}
+	err = mouse.RightClick()
+	if err != nil {
+		t.Fatalf("Failed to issue right click. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evMsc, Code: mscScan, Value: 0x90001},
+		{Type: evKey, Code: evMouseBtnLeft, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evMsc, Code: mscScan, Value: 0x90001},
+		{Type: evKey, Code: evMouseBtnLeft, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+		{Type: evMsc, Code: mscScan, Value: 0x90002},
+		{Type: evKey, Code: evMouseBtnRight, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evMsc, Code: mscScan, Value: 0x90002},
+		{Type: evKey, Code: evMouseBtnRight, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestClickEmitsNoScanCodeByDefault(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse, err := CreateMouseWriter(file, []byte("Test Mouse"))
+	if err != nil {
+		t.Fatalf("Failed to create the dry run mouse. Last error was: %s\n", err)
+	}
+
+	err = mouse.MiddleClick()
+	if err != nil {
+		t.Fatalf("Failed to issue middle click. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: evMouseBtnMiddle, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: evMouseBtnMiddle, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestMouseRegistersScanCodesIfEnabled(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	mouse, err := CreateMouse(path, []byte("Test Mouse"), WithScanCodes())
+	if err != nil {
+		t.Fatalf("Failed to create the virtual mouse. Last error was: %s\n", err)
+	}
+	defer mouse.Close()
+
+	if mscBits := registeredCodes(*calls, uiSetMscBit); len(mscBits) != 1 || mscBits[0] != mscScan {
+		t.Fatalf("Expected MSC_SCAN to be registered, but got %v", mscBits)
+	}
+}
+
+func TestNaturalScrollInvertsWheelMovements(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse, err := CreateMouseWriter(file, []byte("Test Mouse"), WithNaturalScroll())
+	if err != nil {
+		t.Fatalf("Failed to create the dry run mouse. Last error was: %s\n", err)
+	}
+
+	err = mouse.Wheel(false, 1)
+	if err != nil {
+		t.Fatalf("Failed to perform wheel movement. Last error was: %s\n", err)
+	}
+	err = mouse.Wheel(true, -2)
+	if err != nil {
+		t.Fatalf("Failed to perform wheel movement. Last error was: %s\n", err)
+	}
+	err = mouse.WheelHighRes(false, math.MinInt32)
+	if err != nil {
+		t.Fatalf("Failed to perform wheel movement. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evRel, Code: relWheel, Value: -1},
+		{Type: evSyn, Code: synReport},
+		{Type: evRel, Code: relHWheel, Value: 2},
+		{Type: evSyn, Code: synReport},
+		{Type: evRel, Code: relWheelHiRes, Value: math.MaxInt32},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestTraditionalScrollIsDefault(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse, err := CreateMouseWriter(file, []byte("Test Mouse"))
+	if err != nil {
+		t.Fatalf("Failed to create the dry run mouse. Last error was: %s\n", err)
+	}
+
+	err = mouse.Wheel(false, 1)
+	if err != nil {
+		t.Fatalf("Failed to perform wheel movement. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evRel, Code: relWheel, Value: 1},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestScrollClickKeepsMiddleButtonPressedWhileMoving(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse := vMouse{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	err := mouse.ScrollClick(0, 50)
+	if err != nil {
+		t.Fatalf("Failed to issue scroll click. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: evMouseBtnMiddle, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evRel, Code: relX, Value: 0},
+		{Type: evSyn, Code: synReport},
+		{Type: evRel, Code: relY, Value: 50},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: evMouseBtnMiddle, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestDragPathHoldsLeftButtonThroughoutAllMoves(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse := vMouse{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	points := []Point{{X: 10, Y: 0}, {X: 5, Y: -3}, {X: -2, Y: 8}}
+	err := mouse.DragPath(points)
+	if err != nil {
+		t.Fatalf("Failed to drag along the path. Last error was: %s\n", err)
+	}
+
+	events := stop()
+	if len(events) < 4 {
+		t.Fatalf("Expected a press, moves and a release, but got %v", events)
+	}
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: evMouseBtnLeft, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+	}, events[:2])
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: evMouseBtnLeft, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, events[len(events)-2:])
+
+	var x, y int32
+	for _, ev := range events[2 : len(events)-2] {
+		switch {
+		case ev.Type == evRel && ev.Code == relX:
+			x += ev.Value
+		case ev.Type == evRel && ev.Code == relY:
+			y += ev.Value
+		case ev.Type == evKey:
+			t.Fatalf("Expected the left button to be held throughout the drag, but got %v", ev)
+		}
+	}
+	if x != 13 || y != 5 {
+		t.Fatalf("Expected the moves to sum up to (13, 5), but got (%d, %d)", x, y)
+	}
+}
+
+func TestClickDelayIsHonored(t *testing.T) {
+	file, stop := recordEvents(t)
+	delay := 30 * time.Millisecond
+	mouse, err := CreateMouseWriter(file, []byte("Test Mouse"), WithClickDelay(delay))
+	if err != nil {
+		t.Fatalf("Failed to create the dry run mouse. Last error was: %s\n", err)
+	}
+
+	start := time.Now()
+	err = mouse.LeftClick()
+	if err != nil {
+		t.Fatalf("Failed to issue left click. Last error was: %s\n", err)
+	}
+
+	events := stop()
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: evMouseBtnLeft, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: evMouseBtnLeft, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, events)
+	if elapsed := events[2].received.Sub(start); elapsed < delay {
+		t.Fatalf("Expected the release to follow the press after %v, but it followed after %v", delay, elapsed)
+	}
+}
+
+func TestMoveAtVelocityCoversVelocityTimesDuration(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vMouse{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	start := time.Now()
+	err := dev.MoveAtVelocity(1000, -250, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to move at velocity. Last error was: %s\n", err)
+	}
+	elapsed := time.Since(start)
+
+	var x, y int32
+	for _, ev := range stop() {
+		if ev.Type == evRel && ev.Code == relX {
+			x += ev.Value
+		}
+		if ev.Type == evRel && ev.Code == relY {
+			y += ev.Value
+		}
+	}
+	// 1000 and -250 pixels per second for 0.1 seconds
+	if x < 99 || x > 101 || y < -26 || y > -24 {
+		t.Fatalf("Expected a displacement of (100, -25), but got (%d, %d)", x, y)
+	}
+	if elapsed < 100*time.Millisecond {
+		t.Fatalf("Expected the movement to take at least 100ms, but it took %v", elapsed)
+	}
+}
+
+func TestMoveAtVelocityRejectsInvalidVelocities(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vMouse{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	for _, v := range []float64{math.NaN(), math.Inf(1), math.Inf(-1), 1e12} {
+		err := dev.MoveAtVelocity(v, 0, 10*time.Millisecond)
+		if err == nil {
+			t.Fatalf("Expected moving at a velocity of %v to fail, but got no error.", v)
+		}
+		err = dev.MoveAtVelocity(0, v, 10*time.Millisecond)
+		if err == nil {
+			t.Fatalf("Expected moving at a velocity of %v to fail, but got no error.", v)
+		}
+	}
+	if events := stop(); len(events) != 0 {
+		t.Fatalf("Expected no events to be written, but got %v", events)
+	}
+}
+
+func TestMoveAtVelocityHonorsMaxRelMove(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vMouse{deviceBase: deviceBase{report: newReportBuilder(file)}, maxRelMove: 10}
+
+	// 2500 pixels per second cover 20 pixels per report
+	err := dev.MoveAtVelocity(2500, 0, 16*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to move at velocity. Last error was: %s\n", err)
+	}
+
+	var x int32
+	for _, ev := range stop() {
+		if ev.Type != evRel {
+			continue
+		}
+		if ev.Value > 10 || ev.Value < -10 {
+			t.Fatalf("Expected all movements to be split into steps of at most 10, but got %v", ev)
+		}
+		if ev.Code == relX {
+			x += ev.Value
+		}
+	}
+	if x != 40 {
+		t.Fatalf("Expected a total movement of 40, but got %d", x)
+	}
+}
+
+func TestMoveAtVelocityContextStopsOnCancellation(t *testing.T) {
+	relDev, err := CreateMouseWriter(ioutil.Discard, []byte("Test Dry Run Mouse"))
+	if err != nil {
+		t.Fatalf("Failed to create the dry run mouse. Last error was: %s\n", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = relDev.(VelocityMover).MoveAtVelocityContext(ctx, 100, 100, time.Second)
+	if err != context.Canceled {
+		t.Fatalf("Expected: %v\nActual: %v", context.Canceled, err)
+	}
+}
+
+func TestMoveIsSplitIfExceedingMaxRelMove(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse, err := CreateMouseWriter(file, []byte("Test Mouse"), WithMaxRelMove(100))
+	if err != nil {
+		t.Fatalf("Failed to create the dry run mouse. Last error was: %s\n", err)
+	}
+
+	err = mouse.Move(250, -100)
+	if err != nil {
+		t.Fatalf("Failed to move the mouse. Last error was: %s\n", err)
+	}
+	err = mouse.MoveLeft(101)
+	if err != nil {
+		t.Fatalf("Failed to move the mouse. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evRel, Code: relX, Value: 100},
+		{Type: evSyn, Code: synReport},
+		{Type: evRel, Code: relX, Value: 100},
+		{Type: evSyn, Code: synReport},
+		{Type: evRel, Code: relX, Value: 50},
+		{Type: evSyn, Code: synReport},
+		{Type: evRel, Code: relY, Value: -100},
+		{Type: evSyn, Code: synReport},
+		{Type: evRel, Code: relX, Value: -100},
+		{Type: evSyn, Code: synReport},
+		{Type: evRel, Code: relX, Value: -1},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestSplitRelMoveSumsUpToTotal(t *testing.T) {
+	for _, total := range []int32{0, 7, -7, 1000, -1001, 127 * maxRelSteps, -127 * maxRelSteps} {
+		var sum int64
+		err := splitRelMove(total, 127, func(step int32) error {
+			if step > 127 || step < -127 {
+				t.Fatalf("Expected all steps of %d to be within ±127, but got %d", total, step)
+			}
+			sum += int64(step)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Failed to split %d. Last error was: %s\n", total, err)
+		}
+		if sum != int64(total) {
+			t.Fatalf("Expected the steps to add up to %d, but got %d", total, sum)
+		}
+	}
+}
+
+func TestSplitRelMoveRejectsTooManySteps(t *testing.T) {
+	for _, total := range []int32{127*maxRelSteps + 1, math.MaxInt32, math.MinInt32} {
+		steps := 0
+		err := splitRelMove(total, 127, func(step int32) error {
+			steps++
+			return nil
+		})
+		if err == nil {
+			t.Fatalf("Expected splitting %d to fail, but no error was returned.", total)
+		}
+		if steps != 0 {
+			t.Fatalf("Expected no steps to be sent for %d, but got %d", total, steps)
+		}
+	}
+}
+
+func TestMoveFailsForHugeMoveWithSmallMaximum(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse, err := CreateMouseWriter(file, []byte("Test Mouse"), WithMaxRelMove(1))
+	if err != nil {
+		t.Fatalf("Failed to create the dry run mouse. Last error was: %s\n", err)
+	}
+
+	err = mouse.(AxisMover).MoveX(math.MaxInt32)
+	if err == nil {
+		t.Fatalf("Expected the move to fail, but no error was returned.")
+	}
+	assertEvents(t, nil, stop())
+}
+
+func TestMoveXEqualsDirectionalMoves(t *testing.T) {
+	record := func(move func(mouse Mouse) error) []recordedEvent {
+		file, stop := recordEvents(t)
+		mouse, err := CreateMouseWriter(file, []byte("Test Mouse"))
+		if err != nil {
+			t.Fatalf("Failed to create the dry run mouse. Last error was: %s\n", err)
+		}
+		err = move(mouse)
+		if err != nil {
+			t.Fatalf("Failed to move the mouse. Last error was: %s\n", err)
+		}
+		return stop()
+	}
+
+	expected := record(func(mouse Mouse) error { return mouse.MoveLeft(5) })
+	actual := record(func(mouse Mouse) error { return mouse.(AxisMover).MoveX(-5) })
+	assertEvents(t, []inputEvent{expected[0].inputEvent, expected[1].inputEvent}, actual)
+
+	expected = record(func(mouse Mouse) error { return mouse.MoveDown(7) })
+	actual = record(func(mouse Mouse) error { return mouse.(AxisMover).MoveY(7) })
+	assertEvents(t, []inputEvent{expected[0].inputEvent, expected[1].inputEvent}, actual)
+}
+
+func TestWheelModeAccumulatedEmitsSingleEvent(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse, err := CreateMouseWriter(file, []byte("Test Mouse"), WithWheelMode(WheelAccumulated))
+	if err != nil {
+		t.Fatalf("Failed to create the dry run mouse. Last error was: %s\n", err)
+	}
+
+	err = mouse.Wheel(false, 3)
+	if err != nil {
+		t.Fatalf("Failed to perform wheel movement. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evRel, Code: relWheel, Value: 3},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestWheelModeNotchedEmitsOneReportPerNotch(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse, err := CreateMouseWriter(file, []byte("Test Mouse"), WithWheelMode(WheelNotched))
+	if err != nil {
+		t.Fatalf("Failed to create the dry run mouse. Last error was: %s\n", err)
+	}
+
+	err = mouse.Wheel(false, 3)
+	if err != nil {
+		t.Fatalf("Failed to perform wheel movement. Last error was: %s\n", err)
+	}
+	err = mouse.Wheel(true, -1)
+	if err != nil {
+		t.Fatalf("Failed to perform wheel movement. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evRel, Code: relWheel, Value: 1},
+		{Type: evSyn, Code: synReport},
+		{Type: evRel, Code: relWheel, Value: 1},
+		{Type: evSyn, Code: synReport},
+		{Type: evRel, Code: relWheel, Value: 1},
+		{Type: evSyn, Code: synReport},
+		{Type: evRel, Code: relHWheel, Value: -1},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestMoveRelFineEmitsAccruedPixels(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse := vMouse{deviceBase: deviceBase{report: newReportBuilder(file)}, fine: &fineMotion{}}
+
+	for i := 0; i < 10; i++ {
+		err := mouse.MoveRelFine(0.3, -0.3)
+		if err != nil {
+			t.Fatalf("Failed to move mouse. Last error was: %s\n", err)
+		}
+	}
+
+	var movedX, movedY int32
+	events := stop()
+	for _, ev := range events {
+		if ev.Type == evRel && ev.Code == relX {
+			movedX += ev.Value
+		}
+		if ev.Type == evRel && ev.Code == relY {
+			movedY += ev.Value
+		}
+	}
+	if movedX != 3 || movedY != -3 {
+		t.Fatalf("Expected the pointer to be moved by (3, -3) pixels, but got (%d, %d): %v", movedX, movedY, events)
+	}
+	if len(events) != 3*3 {
+		t.Fatalf("Expected a report for every accrued pixel, but got %d events: %v", len(events), events)
+	}
+	if x, y := mouse.FineRemainder(); x != 0 || y != 0 {
+		t.Fatalf("Expected no remainder, but got (%v, %v)", x, y)
+	}
+}
+
+func TestMoveRelFineKeepsRemainder(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse := vMouse{deviceBase: deviceBase{report: newReportBuilder(file)}, fine: &fineMotion{}}
+
+	err := mouse.MoveRelFine(1.25, 0.5)
+	if err != nil {
+		t.Fatalf("Failed to move mouse. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evRel, Code: relX, Value: 1},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+	if x, y := mouse.FineRemainder(); x != 0.25 || y != 0.5 {
+		t.Fatalf("Expected the remainder (0.25, 0.5), but got (%v, %v)", x, y)
+	}
+}
+
+func TestWheelInertiaDecelerates(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse := vMouse{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	// 10 notches per second, which come to a halt after 0.2s, covering one notch (120 high-resolution units)
+	err := mouse.WheelInertia(10, 50)
+	if err != nil {
+		t.Fatalf("Failed to scroll. Last error was: %s\n", err)
+	}
+
+	var deltas []int32
+	var total int32
+	for _, ev := range stop() {
+		if ev.Type == evRel && ev.Code == relWheelHiRes {
+			deltas = append(deltas, ev.Value)
+			total += ev.Value
+		}
+	}
+	if len(deltas) < 2 {
+		t.Fatalf("Expected several wheel movements, but got %v", deltas)
+	}
+	for i := 1; i < len(deltas); i++ {
+		if deltas[i] > deltas[i-1] || deltas[i] <= 0 {
+			t.Fatalf("Expected the deltas to decrease monotonically, but got %v", deltas)
+		}
+	}
+	if total < 100 || total > 150 {
+		t.Fatalf("Expected a total of about 120 high-resolution units, but got %d: %v", total, deltas)
+	}
+}
+
+func TestWheelInertiaStopsIfContextIsDone(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse := vMouse{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := mouse.WheelInertiaContext(ctx, -10, 50)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected: %v\nActual: %v", context.Canceled, err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evRel, Code: relWheelHiRes, Value: -10},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestWheelInertiaRejectsInvalidVelocities(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse := vMouse{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	for _, v := range []float64{math.NaN(), math.Inf(1), math.Inf(-1), 1e10} {
+		err := mouse.WheelInertia(v, 50)
+		if err == nil {
+			t.Fatalf("Expected scrolling at a velocity of %v to fail, but got no error.", v)
+		}
+	}
+	if events := stop(); len(events) != 0 {
+		t.Fatalf("Expected no events to be written, but got %v", events)
+	}
+}
+
+func TestWheelInertiaFailsOnInvalidDeceleration(t *testing.T) {
+	mouse := vMouse{deviceBase: deviceBase{report: newReportBuilder(ioutil.Discard)}}
+
+	err := mouse.WheelInertia(10, 0)
+	if err == nil {
+		t.Fatalf("Expected scrolling without deceleration to fail, but got no error.")
+	}
+}
+
+func TestAutoFireClicksTheGivenNumberOfTimes(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse := vMouse{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	start := time.Now()
+	err := mouse.AutoFire(MouseButtonLeft, 10*time.Millisecond, 3)
+	if err != nil {
+		t.Fatalf("Failed to auto-fire. Last error was: %s\n", err)
+	}
+	// three clicks, separated by two intervals
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Fatalf("Expected auto-fire to take at least 20ms, but it took %v", elapsed)
+	}
+
+	var expected []inputEvent
+	for i := 0; i < 3; i++ {
+		expected = append(expected,
+			inputEvent{Type: evKey, Code: evMouseBtnLeft, Value: btnStatePressed},
+			inputEvent{Type: evSyn, Code: synReport},
+			inputEvent{Type: evKey, Code: evMouseBtnLeft, Value: btnStateReleased},
+			inputEvent{Type: evSyn, Code: synReport},
+		)
+	}
+	assertEvents(t, expected, stop())
+}
+
+func TestAutoFireContextFiresUntilCancelled(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse := vMouse{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 55*time.Millisecond)
+	defer cancel()
+	err := mouse.AutoFireContext(ctx, MouseButtonRight, 10*time.Millisecond, 0)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected: %v\nActual: %v", context.DeadlineExceeded, err)
+	}
+
+	events := stop()
+	presses := countKeyEvents(events, btnStatePressed)
+	if presses < 2 || presses > 7 {
+		t.Fatalf("Expected about 6 clicks within 55ms, but got %d", presses)
+	}
+	if releases := countKeyEvents(events, btnStateReleased); releases != presses {
+		t.Fatalf("Expected every press to be released, but got %d presses and %d releases", presses, releases)
+	}
+}
+
+func TestAutoFireFailsOnInvalidArguments(t *testing.T) {
+	mouse := vMouse{deviceBase: deviceBase{report: newReportBuilder(ioutil.Discard)}}
+
+	if err := mouse.AutoFire(MouseButtonLeft, 10*time.Millisecond, 0); err == nil {
+		t.Fatalf("Expected auto-fire without a count to fail, but got no error.")
+	}
+	if err := mouse.AutoFire(MouseButtonLeft, 0, 3); err == nil {
+		t.Fatalf("Expected auto-fire without an interval to fail, but got no error.")
+	}
+}
+
+func TestClickOnUnregisteredButtonFails(t *testing.T) {
+	w := &writeCounter{}
+	mouse, err := CreateMouseWriter(w, []byte("Test Mouse"), WithMouseButtons(MouseButtonLeft))
+	if err != nil {
+		t.Fatalf("Failed to create the mouse. Last error was: %s\n", err)
+	}
+
+	expected := "Failed to issue the RightClick event: BTN_RIGHT is not registered (registered buttons: [BTN_LEFT])"
+	err = mouse.RightClick()
+	if err == nil || err.Error() != expected {
+		t.Fatalf("Expected: %s\nActual: %v", expected, err)
+	}
+	if len(w.data) != 0 {
+		t.Fatalf("Expected no events to be written, but got %d bytes", len(w.data))
+	}
+
+	err = mouse.LeftClick()
+	if err != nil {
+		t.Fatalf("Failed to click the registered button. Last error was: %s\n", err)
+	}
+}
+
+func TestMouseRegistersOnlyGivenButtons(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	mouse, err := CreateMouse(path, []byte("Test Mouse"), WithMouseButtons(MouseButtonLeft, MouseButtonMiddle))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual mouse. Last error was: %s\n", err)
+	}
+	defer mouse.Close()
+
+	if keyBits := registeredCodes(*calls, uiSetKeyBit); !reflect.DeepEqual(keyBits, []uintptr{evMouseBtnLeft, evMouseBtnMiddle}) {
+		t.Fatalf("Expected only the given buttons to be registered, but got %v", keyBits)
+	}
+
+	_, err = CreateMouse(path, []byte("Test Mouse"), WithMouseButtons(MouseButton(KeyA)))
+	if err == nil {
+		t.Fatalf("Expected registering a key as mouse button to fail, but got no error.")
+	}
+}
+
+func TestWheelModeNotchedFailsForHugeDelta(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse, err := CreateMouseWriter(file, []byte("Test Mouse"), WithWheelMode(WheelNotched))
+	if err != nil {
+		t.Fatalf("Failed to create the dry run mouse. Last error was: %s\n", err)
+	}
+
+	for _, delta := range []int32{math.MaxInt32, math.MinInt32, maxRelSteps + 1} {
+		err = mouse.Wheel(false, delta)
+		if err == nil {
+			t.Fatalf("Expected a wheel movement of %d notches to fail, but no error was returned.", delta)
+		}
+	}
+	assertEvents(t, nil, stop())
+}
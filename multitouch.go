@@ -4,6 +4,26 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sync"
+	"time"
+)
+
+// The orientation of a contact is reported in degrees, where 0 means that the contact is aligned with the y-axis.
+const (
+	multiTouchMinOrientation = -90
+	multiTouchMaxOrientation = 90
+)
+
+// multiTouchMaxTrackingID is the maximum tracking id of a contact, after which the ids start over at 0.
+const multiTouchMaxTrackingID = 0xffff
+
+// MultiTouchToolType describes the kind of tool that is used for a contact (ABS_MT_TOOL_TYPE).
+type MultiTouchToolType int32
+
+const (
+	MultiTouchToolFinger MultiTouchToolType = 0x00
+	MultiTouchToolPen    MultiTouchToolType = 0x01
+	MultiTouchToolPalm   MultiTouchToolType = 0x02
 )
 
 // MultiTouch is an input device that uses absolute axis events.
@@ -23,22 +43,92 @@ type MultiTouch interface {
 	io.Closer
 }
 
+// A ContactAttributeSetter sets attributes of the contacts of a multitouch device besides their position. The
+// multitouch devices created by this package implement ContactAttributeSetter.
+type ContactAttributeSetter interface {
+	// SetContactOrientation sets the orientation of the contact in the given slot, in degrees (-90 to 90).
+	// Values outside of this range are clamped.
+	SetContactOrientation(slot int32, value int32) error
+
+	// SetContactBlobID assigns the contact in the given slot to a blob. Contacts that share the same blob id
+	// belong to the same object, e.g. a palm that is reported as several contacts.
+	SetContactBlobID(slot int32, id int32) error
+
+	// SetContactToolType sets the kind of tool (finger, pen or palm) of the contact in the given slot.
+	SetContactToolType(slot int32, toolType MultiTouchToolType) error
+}
+
+// A MultiTapper taps with several contacts at once. The multitouch devices created by this package implement
+// MultiTapper.
+type MultiTapper interface {
+	// MultiTap will place n contacts at the given positions simultaneously and lift them again, which simulates
+	// a tap with several fingers (e.g. for three- or four-finger gestures). The contacts use the first n slots,
+	// which must not touch the surface already.
+	MultiTap(n int, positions []Point) error
+}
+
 type vMultiTouch struct {
-	name       []byte
-	deviceFile *os.File
-	contacts   []multiTouchContact
+	deviceBase
+	contacts []multiTouchContact
+	tracking *multiTouchTracking
+	// singleTouch is set if the position of slot 0 is mirrored on ABS_X and ABS_Y (see WithSingleTouchEmulation)
+	singleTouch bool
+	// tapDuration is the time between touch down and touch up of MultiTap, or zero for tapHoldDuration
+	tapDuration time.Duration
 }
 
 // The contact can be described as a finger contacting the surface of the MultiTouch device.
 type multiTouchContact struct {
-	multitouch  *vMultiTouch
-	slot        int32
-	tracking_id int32
+	multitouch *vMultiTouch
+	slot       int32
+}
+
+// multiTouchTracking assigns the tracking ids of the contacts. Every touch gets a new id, since consumers like
+// libinput treat a touch that reuses the id of the previous one in the same slot as its continuation.
+type multiTouchTracking struct {
+	mu   sync.Mutex
+	next int32
+	// ids holds the tracking id of every slot, or -1 if there is no contact in the slot
+	ids []int32
+}
+
+func newMultiTouchTracking(slots int32) *multiTouchTracking {
+	ids := make([]int32, slots)
+	for i := range ids {
+		ids[i] = -1
+	}
+	return &multiTouchTracking{ids: ids}
+}
+
+// touchDown returns the tracking id of the contact in the given slot, assigning a new one if the slot is empty.
+func (t *multiTouchTracking) touchDown(slot int32) int32 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.ids[slot] < 0 {
+		t.ids[slot] = t.next
+		t.next = (t.next + 1) % (multiTouchMaxTrackingID + 1)
+	}
+	return t.ids[slot]
+}
+
+// touchUp clears the tracking id of the given slot and returns -1, which marks the end of the contact.
+func (t *multiTouchTracking) touchUp(slot int32) int32 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ids[slot] = -1
+	return -1
+}
+
+func (t *multiTouchTracking) id(slot int32) int32 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.ids[slot]
 }
 
 // CreateMultiTouch will create a new multitouch device. Note that you will need to define the x and y-axis boundaries
 // (min and max) within which the contacs maybe moved around, as well as the maximum amount of contacts allowed.
-func CreateMultiTouch(path string, name []byte, minX int32, maxX int32, minY int32, maxY int32, maxContacts int32) (MultiTouch, error) {
+// Every contact is assigned a slot; the number of slots can be overridden using WithMaxSlots.
+func CreateMultiTouch(path string, name []byte, minX int32, maxX int32, minY int32, maxY int32, maxContacts int32, opts ...Option) (MultiTouch, error) {
 	err := validateDevicePath(path)
 	if err != nil {
 		return nil, err
@@ -48,14 +138,31 @@ func CreateMultiTouch(path string, name []byte, minX int32, maxX int32, minY int
 		return nil, err
 	}
 
-	fd, err := createMultiTouch(path, name, minX, maxX, minY, maxY, maxContacts)
+	options := newDeviceOptions(opts)
+	slots := maxContacts
+	if options.maxSlots > 0 {
+		slots = options.maxSlots
+	}
+	if slots < 1 {
+		return nil, fmt.Errorf("invalid number of slots %d: at least one slot is required", slots)
+	}
+	fd, err := createMultiTouch(path, name, minX, maxX, minY, maxY, slots, options)
 	if err != nil {
 		return nil, err
 	}
 
-	var multitouch vMultiTouch = vMultiTouch{name: name, deviceFile: fd}
+	var multitouch vMultiTouch = vMultiTouch{
+		deviceBase: deviceBase{
+			name:       name,
+			deviceFile: fd,
+			report:     newReportBuilderWithOptions(fd, name, options.withoutReportInterval()),
+		},
+		tracking:    newMultiTouchTracking(slots),
+		singleTouch: options.singleTouch,
+		tapDuration: options.tapDuration,
+	}
 
-	for i := int32(0); i < maxContacts; i++ {
+	for i := int32(0); i < slots; i++ {
 		multitouch.contacts = append(multitouch.contacts, multiTouchContact{slot: i, multitouch: &multitouch})
 	}
 
@@ -66,50 +173,204 @@ func (vMulti vMultiTouch) GetContacts() []multiTouchContact {
 	return vMulti.contacts
 }
 
-func (vMulti vMultiTouch) FetchSyspath() (string, error) {
-	return fetchSyspath(vMulti.deviceFile)
+// SetContactOrientation will issue an orientation event for the contact in the given slot.
+func (vMulti vMultiTouch) SetContactOrientation(slot int32, value int32) error {
+	if err := vMulti.checkSlot(slot); err != nil {
+		return err
+	}
+	if value < multiTouchMinOrientation {
+		value = multiTouchMinOrientation
+	}
+	if value > multiTouchMaxOrientation {
+		value = multiTouchMaxOrientation
+	}
+
+	err := vMulti.report.send(
+		inputEvent{
+			Type:  evAbs,
+			Code:  absMtSlot,
+			Value: slot,
+		},
+		inputEvent{
+			Type:  evAbs,
+			Code:  absMtOrientation,
+			Value: value,
+		})
+	if err != nil {
+		return fmt.Errorf("failed to write orientation event to device file: %w", err)
+	}
+	return nil
+}
+
+// SetContactToolType will issue a tool type event for the contact in the given slot.
+func (vMulti vMultiTouch) SetContactToolType(slot int32, toolType MultiTouchToolType) error {
+	if err := vMulti.checkSlot(slot); err != nil {
+		return err
+	}
+	if toolType < MultiTouchToolFinger || toolType > MultiTouchToolPalm {
+		return fmt.Errorf("unknown tool type %d", toolType)
+	}
+
+	err := vMulti.report.send(
+		inputEvent{
+			Type:  evAbs,
+			Code:  absMtSlot,
+			Value: slot,
+		},
+		inputEvent{
+			Type:  evAbs,
+			Code:  absMtToolType,
+			Value: int32(toolType),
+		})
+	if err != nil {
+		return fmt.Errorf("failed to write tool type event to device file: %w", err)
+	}
+	return nil
+}
+
+// SetContactBlobID will issue a blob id event for the contact in the given slot.
+func (vMulti vMultiTouch) SetContactBlobID(slot int32, id int32) error {
+	if err := vMulti.checkSlot(slot); err != nil {
+		return err
+	}
+
+	err := vMulti.report.send(
+		inputEvent{
+			Type:  evAbs,
+			Code:  absMtSlot,
+			Value: slot,
+		},
+		inputEvent{
+			Type:  evAbs,
+			Code:  absMtBlobId,
+			Value: id,
+		})
+	if err != nil {
+		return fmt.Errorf("failed to write blob id event to device file: %w", err)
+	}
+	return nil
+}
+
+// MultiTap will touch down with the contacts of the first n slots within a single report, wait for the tap
+// duration (see WithTapDuration) and lift all of them within a single report.
+func (vMulti vMultiTouch) MultiTap(n int, positions []Point) error {
+	if len(positions) != n {
+		return fmt.Errorf("expected %d positions for a tap with %d contacts, but got %d", n, n, len(positions))
+	}
+	if n < 1 || n > len(vMulti.contacts) {
+		return fmt.Errorf("%d is out of range: the device supports taps with 1 to %d contacts", n, len(vMulti.contacts))
+	}
+	contacts := vMulti.contacts[:n]
+	for _, contact := range contacts {
+		if err := contact.checkSlot(); err != nil {
+			return err
+		}
+		if contact.TrackingID() >= 0 {
+			return fmt.Errorf("contact of slot %d already touches the surface", contact.slot)
+		}
+	}
+
+	var down []inputEvent
+	for i, contact := range contacts {
+		trackingID := vMulti.tracking.touchDown(contact.slot)
+		down = append(down, contact.slotEvents(trackingID, contact.positionEvents(positions[i].X, positions[i].Y))...)
+	}
+	err := vMulti.report.send(down...)
+	if err != nil {
+		for _, contact := range contacts {
+			vMulti.tracking.touchUp(contact.slot)
+		}
+		return fmt.Errorf("failed to issue the touch down events of the tap: %w", err)
+	}
+
+	time.Sleep(tapHoldTime(vMulti.tapDuration))
+
+	var up []inputEvent
+	for _, contact := range contacts {
+		up = append(up, contact.slotEvents(vMulti.tracking.touchUp(contact.slot), nil)...)
+	}
+	err = vMulti.report.send(up...)
+	if err != nil {
+		return fmt.Errorf("failed to issue the touch up events of the tap: %w", err)
+	}
+	return nil
+}
+
+// checkSlot returns an error if the given slot is not one of the slots of the device.
+func (vMulti vMultiTouch) checkSlot(slot int32) error {
+	if slot < 0 || int(slot) >= len(vMulti.contacts) {
+		return fmt.Errorf("slot %d is out of range: the device has %d slots", slot, len(vMulti.contacts))
+	}
+	return nil
 }
 
-func (vMulti vMultiTouch) Close() error {
-	return closeDevice(vMulti.deviceFile)
+// Reset lifts all contacts that touch the surface (see Resetter).
+func (vMulti vMultiTouch) Reset() error {
+	var events []inputEvent
+	var lifted []int32
+	for _, contact := range vMulti.contacts {
+		if vMulti.tracking == nil || contact.TrackingID() < 0 {
+			continue
+		}
+		events = append(events,
+			inputEvent{Type: evAbs, Code: absMtSlot, Value: contact.slot},
+			inputEvent{Type: evAbs, Code: absMtTrackingId, Value: -1})
+		lifted = append(lifted, contact.slot)
+	}
+
+	err := vMulti.report.reset(events...)
+	if err != nil {
+		return fmt.Errorf("failed to reset multitouch device: %w", err)
+	}
+	for _, slot := range lifted {
+		vMulti.tracking.touchUp(slot)
+	}
+	return nil
 }
 
-func createMultiTouch(path string, name []byte, minX int32, maxX int32, minY int32, maxY int32, maxContacts int32) (fd *os.File, err error) {
+func createMultiTouch(path string, name []byte, minX int32, maxX int32, minY int32, maxY int32, slots int32, options deviceOptions) (fd *os.File, err error) {
 	deviceFile, err := createDeviceFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("could not create absolute axis input device: %v", err)
+		return nil, fmt.Errorf("could not create absolute axis input device: %w", err)
 	}
 
 	err = registerDevice(deviceFile, uintptr(evKey))
 	if err != nil {
 		_ = deviceFile.Close()
-		return nil, fmt.Errorf("failed to register key device: %v", err)
+		return nil, fmt.Errorf("failed to register key device: %w", err)
 	}
 
 	for _, event := range []int{evBtnTouch} {
 		err = ioctl(deviceFile, uiSetKeyBit, uintptr(event))
 		if err != nil {
 			_ = deviceFile.Close()
-			return nil, fmt.Errorf("failed to register button event %v: %v", event, err)
+			return nil, fmt.Errorf("failed to register button event %v: %w", event, err)
 		}
 	}
 
 	err = registerDevice(deviceFile, uintptr(evAbs))
 	if err != nil {
 		_ = deviceFile.Close()
-		return nil, fmt.Errorf("failed to register absolute axis input device: %v", err)
+		return nil, fmt.Errorf("failed to register absolute axis input device: %w", err)
 	}
 
-	for _, event := range []int{
+	events := []int{
 		absMtSlot,
 		absMtTrackingId,
 		absMtPositionX,
 		absMtPositionY,
-	} {
+		absMtOrientation,
+		absMtBlobId,
+		absMtToolType,
+	}
+	if options.singleTouch {
+		events = append(events, absX, absY)
+	}
+	for _, event := range events {
 		err = ioctl(deviceFile, uiSetAbsBit, uintptr(event))
 		if err != nil {
 			_ = deviceFile.Close()
-			return nil, fmt.Errorf("failed to register absolute axis event %v: %v", event, err)
+			return nil, fmt.Errorf("failed to register absolute axis event %v: %w", event, err)
 		}
 	}
 
@@ -118,12 +379,24 @@ func createMultiTouch(path string, name []byte, minX int32, maxX int32, minY int
 	absMin[absMtPositionY] = minY
 	absMin[absMtTrackingId] = 0x00
 	absMin[absMtSlot] = 0x00
+	absMin[absMtOrientation] = multiTouchMinOrientation
+	absMin[absMtBlobId] = 0x00
+	absMin[absMtToolType] = int32(MultiTouchToolFinger)
 
 	var absMax [absSize]int32
 	absMax[absMtPositionX] = maxX
 	absMax[absMtPositionY] = maxY
-	absMax[absMtTrackingId] = maxContacts
-	absMax[absMtSlot] = maxContacts
+	absMax[absMtTrackingId] = multiTouchMaxTrackingID
+	// the slots are numbered from 0 to slots-1
+	absMax[absMtSlot] = slots - 1
+	absMax[absMtOrientation] = multiTouchMaxOrientation
+	absMax[absMtBlobId] = slots
+	absMax[absMtToolType] = int32(MultiTouchToolPalm)
+
+	if options.singleTouch {
+		absMin[absX], absMax[absX] = minX, maxX
+		absMin[absY], absMax[absY] = minY, maxY
+	}
 
 	return createUsbDevice(deviceFile,
 		uinputUserDev{
@@ -134,11 +407,21 @@ func createMultiTouch(path string, name []byte, minX int32, maxX int32, minY int
 				Product: 0x0,
 				Version: 0},
 			Absmin: absMin,
-			Absmax: absMax})
+			Absmax: absMax},
+		options)
 }
 
 // The contact will be held down at the coordinates specified
 func (c multiTouchContact) TouchDownAt(x int32, y int32) error {
+	if err := c.checkSlot(); err != nil {
+		return err
+	}
+	return c.sendAbsEvent(c.multitouch.tracking.touchDown(c.slot), c.positionEvents(x, y))
+}
+
+// positionEvents returns the events that place the contact at the given position, which are mirrored on ABS_X and
+// ABS_Y for slot 0 if single touch emulation is enabled.
+func (c multiTouchContact) positionEvents(x int32, y int32) []inputEvent {
 	var events []inputEvent
 
 	events = append(events, inputEvent{
@@ -157,18 +440,49 @@ func (c multiTouchContact) TouchDownAt(x int32, y int32) error {
 		Value: y,
 	})
 
-	c.tracking_id = c.slot
-
-	return c.sendAbsEvent(events)
+	if c.slot == 0 && c.multitouch.singleTouch {
+		events = append(events,
+			inputEvent{Type: evAbs, Code: absX, Value: x},
+			inputEvent{Type: evAbs, Code: absY, Value: y})
+	}
+	return events
 }
 
 // The contact will be raised off of the surface
 func (c multiTouchContact) TouchUp() error {
-	c.tracking_id = -1
-	return c.sendAbsEvent(nil)
+	if err := c.checkSlot(); err != nil {
+		return err
+	}
+	return c.sendAbsEvent(c.multitouch.tracking.touchUp(c.slot), nil)
+}
+
+// TrackingID returns the tracking id of the contact, or -1 if it does not touch the surface. Every touch gets a
+// new id, while it is kept for subsequent calls of TouchDownAt that move the contact.
+func (c multiTouchContact) TrackingID() int32 {
+	return c.multitouch.tracking.id(c.slot)
+}
+
+// checkSlot returns an error if the slot of the contact is not one of the slots tracked by its device.
+func (c multiTouchContact) checkSlot() error {
+	if c.multitouch == nil || c.multitouch.tracking == nil {
+		return fmt.Errorf("contact of slot %d does not belong to a device", c.slot)
+	}
+	if slots := len(c.multitouch.tracking.ids); c.slot < 0 || int(c.slot) >= slots {
+		return fmt.Errorf("slot %d is out of range: the device has %d slots", c.slot, slots)
+	}
+	return nil
 }
 
-func (c multiTouchContact) sendAbsEvent(events []inputEvent) error {
+func (c multiTouchContact) sendAbsEvent(trackingID int32, events []inputEvent) error {
+	err := c.multitouch.report.send(c.slotEvents(trackingID, events)...)
+	if err != nil {
+		return fmt.Errorf("failed to write abs event to device file: %w", err)
+	}
+	return nil
+}
+
+// slotEvents returns the given events, preceded by the selection of the slot of the contact and its tracking id.
+func (c multiTouchContact) slotEvents(trackingID int32, events []inputEvent) []inputEvent {
 	var ev []inputEvent
 
 	ev = append(ev, inputEvent{
@@ -180,24 +494,11 @@ func (c multiTouchContact) sendAbsEvent(events []inputEvent) error {
 	ev = append(ev, inputEvent{
 		Type:  evAbs,
 		Code:  absMtTrackingId,
-		Value: c.tracking_id,
+		Value: trackingID,
 	})
 
 	if events != nil {
 		ev = append(ev, events...)
 	}
-
-	for _, iev := range ev {
-		buf, err := inputEventToBuffer(iev)
-		if err != nil {
-			return fmt.Errorf("writing abs event failed: %v", err)
-		}
-
-		_, err = c.multitouch.deviceFile.Write(buf)
-		if err != nil {
-			return fmt.Errorf("failed to write abs event to device file: %v", err)
-		}
-	}
-
-	return syncEvents(c.multitouch.deviceFile)
+	return ev
 }
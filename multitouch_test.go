@@ -4,10 +4,18 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 )
 
+var (
+	_ ContactAttributeSetter = vMultiTouch{}
+	_ ContactAttributeSetter = noopMultiTouch{}
+	_ MultiTapper            = vMultiTouch{}
+	_ MultiTapper            = noopMultiTouch{}
+)
+
 func TestBasicMultiTouchMoves(t *testing.T) {
 	absDev, err := CreateMultiTouch("/dev/uinput", []byte("Test MultiTouch"), 0, 1024, 0, 768, 3)
 	if err != nil {
@@ -223,3 +231,266 @@ func TestMultiTouchSyspath(t *testing.T) {
 
 	t.Logf("Syspath: %s", sysPath)
 }
+
+func TestContactOrientationIsEmittedForSlot(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vMultiTouch{deviceBase: deviceBase{report: newReportBuilder(file)}, contacts: make([]multiTouchContact, 2)}
+
+	err := dev.SetContactOrientation(1, 45)
+	if err != nil {
+		t.Fatalf("Failed to set contact orientation. Last error was: %s\n", err)
+	}
+	// values outside of the range are clamped
+	err = dev.SetContactOrientation(0, -180)
+	if err != nil {
+		t.Fatalf("Failed to set contact orientation. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absMtSlot, Value: 1},
+		{Type: evAbs, Code: absMtOrientation, Value: 45},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absMtSlot, Value: 0},
+		{Type: evAbs, Code: absMtOrientation, Value: multiTouchMinOrientation},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestContactOrientationFailsForInvalidSlot(t *testing.T) {
+	dev := vMultiTouch{contacts: make([]multiTouchContact, 2)}
+
+	err := dev.SetContactOrientation(2, 0)
+	if err == nil {
+		t.Fatalf("Expected setting the orientation to fail for an invalid slot, but got no error.")
+	}
+}
+
+func TestContactBlobIdIsEmittedForSlot(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vMultiTouch{deviceBase: deviceBase{report: newReportBuilder(file)}, contacts: make([]multiTouchContact, 2)}
+
+	err := dev.SetContactBlobID(1, 3)
+	if err != nil {
+		t.Fatalf("Failed to set contact blob id. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absMtSlot, Value: 1},
+		{Type: evAbs, Code: absMtBlobId, Value: 3},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestContactBlobIdFailsForInvalidSlot(t *testing.T) {
+	dev := vMultiTouch{contacts: make([]multiTouchContact, 2)}
+
+	err := dev.SetContactBlobID(-1, 0)
+	if err == nil {
+		t.Fatalf("Expected setting the blob id to fail for an invalid slot, but got no error.")
+	}
+}
+
+func TestContactToolTypeIsEmittedForSlot(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vMultiTouch{deviceBase: deviceBase{report: newReportBuilder(file)}, contacts: make([]multiTouchContact, 2)}
+
+	err := dev.SetContactToolType(1, MultiTouchToolPen)
+	if err != nil {
+		t.Fatalf("Failed to set contact tool type. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absMtSlot, Value: 1},
+		{Type: evAbs, Code: absMtToolType, Value: int32(MultiTouchToolPen)},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestContactToolTypeFailsForUnknownToolType(t *testing.T) {
+	dev := vMultiTouch{contacts: make([]multiTouchContact, 2)}
+
+	err := dev.SetContactToolType(0, MultiTouchToolPalm+1)
+	if err == nil {
+		t.Fatalf("Expected setting an unknown tool type to fail, but got no error.")
+	}
+}
+
+func TestSuccessiveTouchesGetDifferentTrackingIDs(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := &vMultiTouch{deviceBase: deviceBase{report: newReportBuilder(file)}, tracking: newMultiTouchTracking(1)}
+	contact := multiTouchContact{slot: 0, multitouch: dev}
+
+	if id := contact.TrackingID(); id != -1 {
+		t.Fatalf("Expected the contact not to have a tracking id before touching, but got %d", id)
+	}
+	for _, step := range []func() error{
+		func() error { return contact.TouchDownAt(10, 10) },
+		// moving keeps the tracking id
+		func() error { return contact.TouchDownAt(20, 20) },
+		contact.TouchUp,
+		func() error { return contact.TouchDownAt(10, 10) },
+	} {
+		err := step()
+		if err != nil {
+			t.Fatalf("Failed to touch. Last error was: %s\n", err)
+		}
+	}
+	if id := contact.TrackingID(); id != 1 {
+		t.Fatalf("Expected the second touch to have tracking id 1, but got %d", id)
+	}
+
+	var ids []int32
+	for _, ev := range stop() {
+		if ev.Type == evAbs && ev.Code == absMtTrackingId {
+			ids = append(ids, ev.Value)
+		}
+	}
+	if expected := []int32{0, 0, -1, 1}; !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("Expected: %v\nActual: %v", expected, ids)
+	}
+}
+
+func TestMaxSlotsLimitsTheSlotsOfTheDevice(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	_, restore := fakeIoctl(nil)
+	defer restore()
+
+	dev, err := CreateMultiTouch(path, []byte("Test MultiTouch"), 0, 1024, 0, 768, 5, WithMaxSlots(2))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual multitouch device. Last error was: %s\n", err)
+	}
+	defer dev.Close()
+
+	contacts := dev.GetContacts()
+	if len(contacts) != 2 {
+		t.Fatalf("Expected 2 contacts, but got %d", len(contacts))
+	}
+	err = dev.(ContactAttributeSetter).SetContactToolType(2, MultiTouchToolFinger)
+	if err == nil {
+		t.Fatalf("Expected using slot 2 of a device with 2 slots to fail, but got no error.")
+	}
+
+	userDev := readUserDev(t, path)
+	if userDev.Absmin[absMtSlot] != 0 || userDev.Absmax[absMtSlot] != 1 {
+		t.Fatalf("Expected the slot range to be 0..1, but got %d..%d", userDev.Absmin[absMtSlot], userDev.Absmax[absMtSlot])
+	}
+}
+
+func TestTouchDownFailsForSlotOutOfRange(t *testing.T) {
+	dev := &vMultiTouch{deviceBase: deviceBase{report: newReportBuilder(ioutil.Discard)}, contacts: make([]multiTouchContact, 2), tracking: newMultiTouchTracking(2)}
+	contact := multiTouchContact{multitouch: dev, slot: 2}
+
+	err := contact.TouchDownAt(10, 10)
+	if err == nil {
+		t.Fatalf("Expected touching down in slot 2 of a device with 2 slots to fail, but got no error.")
+	}
+}
+
+func TestSingleTouchEmulationMirrorsFirstSlot(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := &vMultiTouch{deviceBase: deviceBase{report: newReportBuilder(file)}, tracking: newMultiTouchTracking(2), singleTouch: true}
+	first := multiTouchContact{slot: 0, multitouch: dev}
+	second := multiTouchContact{slot: 1, multitouch: dev}
+
+	err := first.TouchDownAt(10, 20)
+	if err != nil {
+		t.Fatalf("Failed to touch. Last error was: %s\n", err)
+	}
+	err = second.TouchDownAt(30, 40)
+	if err != nil {
+		t.Fatalf("Failed to touch. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absMtSlot, Value: 0},
+		{Type: evAbs, Code: absMtTrackingId, Value: 0},
+		{Type: evAbs, Code: absMtPositionX, Value: 10},
+		{Type: evAbs, Code: absMtPositionY, Value: 20},
+		{Type: evAbs, Code: absX, Value: 10},
+		{Type: evAbs, Code: absY, Value: 20},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absMtSlot, Value: 1},
+		{Type: evAbs, Code: absMtTrackingId, Value: 1},
+		{Type: evAbs, Code: absMtPositionX, Value: 30},
+		{Type: evAbs, Code: absMtPositionY, Value: 40},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestSingleTouchEmulationRegistersSingleTouchAxes(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	dev, err := CreateMultiTouch(path, []byte("Test MultiTouch"), 0, 1024, 0, 768, 2, WithSingleTouchEmulation())
+	if err != nil {
+		t.Fatalf("Failed to create the virtual multitouch device. Last error was: %s\n", err)
+	}
+	defer dev.Close()
+
+	absBits := registeredCodes(*calls, uiSetAbsBit)
+	if !reflect.DeepEqual(absBits[len(absBits)-2:], []uintptr{absX, absY}) {
+		t.Fatalf("Expected ABS_X and ABS_Y to be registered, but got %v", absBits)
+	}
+	userDev := readUserDev(t, path)
+	if userDev.Absmax[absX] != 1024 || userDev.Absmax[absY] != 768 {
+		t.Fatalf("Expected the single touch axes to span the surface, but got %d and %d", userDev.Absmax[absX], userDev.Absmax[absY])
+	}
+}
+
+func TestMultiTapPlacesAndLiftsAllContacts(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := &vMultiTouch{deviceBase: deviceBase{report: newReportBuilder(file)}, tracking: newMultiTouchTracking(4), tapDuration: time.Millisecond}
+	for i := int32(0); i < 4; i++ {
+		dev.contacts = append(dev.contacts, multiTouchContact{slot: i, multitouch: dev})
+	}
+
+	err := dev.MultiTap(3, []Point{{X: 10, Y: 10}, {X: 20, Y: 10}, {X: 30, Y: 10}})
+	if err != nil {
+		t.Fatalf("Failed to tap. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absMtSlot, Value: 0},
+		{Type: evAbs, Code: absMtTrackingId, Value: 0},
+		{Type: evAbs, Code: absMtPositionX, Value: 10},
+		{Type: evAbs, Code: absMtPositionY, Value: 10},
+		{Type: evAbs, Code: absMtSlot, Value: 1},
+		{Type: evAbs, Code: absMtTrackingId, Value: 1},
+		{Type: evAbs, Code: absMtPositionX, Value: 20},
+		{Type: evAbs, Code: absMtPositionY, Value: 10},
+		{Type: evAbs, Code: absMtSlot, Value: 2},
+		{Type: evAbs, Code: absMtTrackingId, Value: 2},
+		{Type: evAbs, Code: absMtPositionX, Value: 30},
+		{Type: evAbs, Code: absMtPositionY, Value: 10},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absMtSlot, Value: 0},
+		{Type: evAbs, Code: absMtTrackingId, Value: -1},
+		{Type: evAbs, Code: absMtSlot, Value: 1},
+		{Type: evAbs, Code: absMtTrackingId, Value: -1},
+		{Type: evAbs, Code: absMtSlot, Value: 2},
+		{Type: evAbs, Code: absMtTrackingId, Value: -1},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+	for _, contact := range dev.contacts {
+		if id := contact.TrackingID(); id != -1 {
+			t.Fatalf("Expected all contacts to be lifted, but slot %d has tracking id %d", contact.slot, id)
+		}
+	}
+}
+
+func TestMultiTapFailsOnInvalidArguments(t *testing.T) {
+	dev := &vMultiTouch{deviceBase: deviceBase{report: newReportBuilder(ioutil.Discard)}, tracking: newMultiTouchTracking(2)}
+	for i := int32(0); i < 2; i++ {
+		dev.contacts = append(dev.contacts, multiTouchContact{slot: i, multitouch: dev})
+	}
+
+	if err := dev.MultiTap(2, []Point{{X: 10, Y: 10}}); err == nil {
+		t.Fatalf("Expected a tap with fewer positions than contacts to fail, but got no error.")
+	}
+	if err := dev.MultiTap(3, make([]Point, 3)); err == nil {
+		t.Fatalf("Expected a tap with more contacts than slots to fail, but got no error.")
+	}
+}
@@ -0,0 +1,182 @@
+package uinput
+
+import (
+	"context"
+	"time"
+)
+
+// The noop devices implement the device interfaces without doing anything at all: every method returns
+// immediately without an error. They are meant for benchmarking or testing code that uses this package,
+// without the overhead of system calls or the need for access to /dev/uinput. Unlike the devices created
+// by CreateMouseWriter, no events are serialized.
+
+// CreateNoopKeyboard will create a keyboard that discards all input.
+func CreateNoopKeyboard() Keyboard {
+	return noopKeyboard{}
+}
+
+// CreateNoopMouse will create a mouse that discards all input.
+func CreateNoopMouse() Mouse {
+	return noopMouse{}
+}
+
+// CreateNoopTouchPad will create a touch pad that discards all input.
+func CreateNoopTouchPad() TouchPad {
+	return noopTouchPad{}
+}
+
+// CreateNoopDial will create a dial that discards all input.
+func CreateNoopDial() Dial {
+	return noopDial{}
+}
+
+// CreateNoopGamepad will create a gamepad that discards all input.
+func CreateNoopGamepad() Gamepad {
+	return noopGamepad{}
+}
+
+// CreateNoopMultiTouch will create a multitouch device that discards all input. Note that it does not provide
+// any contacts.
+func CreateNoopMultiTouch() MultiTouch {
+	return noopMultiTouch{}
+}
+
+type noopKeyboard struct{}
+
+func (noopKeyboard) KeyPress(key int) error                                       { return nil }
+func (noopKeyboard) KeyDown(key int) error                                        { return nil }
+func (noopKeyboard) KeyUp(key int) error                                          { return nil }
+func (noopKeyboard) KeyPressByName(name string) error                             { return nil }
+func (noopKeyboard) KeyDownByName(name string) error                              { return nil }
+func (noopKeyboard) KeyUpByName(name string) error                                { return nil }
+func (noopKeyboard) KeyHoldRepeat(key int, count int, period time.Duration) error { return nil }
+func (noopKeyboard) HoldKey(ctx context.Context, key int) error                   { return nil }
+func (noopKeyboard) TypeString(s string) error                                    { return nil }
+func (noopKeyboard) TypeStringContext(ctx context.Context, s string) error        { return nil }
+func (noopKeyboard) TypeStringDelayed(s string, perKey time.Duration) error       { return nil }
+func (noopKeyboard) TypeStringDelayedContext(ctx context.Context, s string, perKey time.Duration) error {
+	return nil
+}
+func (noopKeyboard) TypeRune(char rune) error                                { return nil }
+func (noopKeyboard) ModifierDown(modifier int) error                         { return nil }
+func (noopKeyboard) ModifierUp(modifier int) error                           { return nil }
+func (noopKeyboard) IsModifierActive(modifier int) bool                      { return false }
+func (noopKeyboard) ClearModifiers() error                                   { return nil }
+func (noopKeyboard) SetComposeKey(key int) error                             { return nil }
+func (noopKeyboard) ReadLEDChanges() <-chan LEDState                         { return closedLEDChanges() }
+func (noopKeyboard) FetchSyspath() (string, error)                           { return "", nil }
+func (noopKeyboard) FetchSyspathContext(ctx context.Context) (string, error) { return "", nil }
+func (noopKeyboard) Reset() error                                            { return nil }
+func (noopKeyboard) Close() error                                            { return nil }
+
+type noopMouse struct{}
+
+func (noopMouse) MoveLeft(pixel int32) error                           { return nil }
+func (noopMouse) MoveRight(pixel int32) error                          { return nil }
+func (noopMouse) MoveUp(pixel int32) error                             { return nil }
+func (noopMouse) MoveDown(pixel int32) error                           { return nil }
+func (noopMouse) MoveX(delta int32) error                              { return nil }
+func (noopMouse) MoveY(delta int32) error                              { return nil }
+func (noopMouse) Move(x, y int32) error                                { return nil }
+func (noopMouse) MoveAtVelocity(vx, vy float64, d time.Duration) error { return nil }
+func (noopMouse) MoveAtVelocityContext(ctx context.Context, vx, vy float64, d time.Duration) error {
+	return nil
+}
+func (noopMouse) MoveRelFine(dx, dy float64) error                         { return nil }
+func (noopMouse) FineRemainder() (x, y float64)                            { return 0, 0 }
+func (noopMouse) LeftClick() error                                         { return nil }
+func (noopMouse) RightClick() error                                        { return nil }
+func (noopMouse) MiddleClick() error                                       { return nil }
+func (noopMouse) LeftPress() error                                         { return nil }
+func (noopMouse) LeftRelease() error                                       { return nil }
+func (noopMouse) RightPress() error                                        { return nil }
+func (noopMouse) RightRelease() error                                      { return nil }
+func (noopMouse) MiddlePress() error                                       { return nil }
+func (noopMouse) MiddleRelease() error                                     { return nil }
+func (noopMouse) ScrollClick(x, y int32) error                             { return nil }
+func (noopMouse) DragPath(points []Point) error                            { return nil }
+func (noopMouse) HoldButton(ctx context.Context, button MouseButton) error { return nil }
+func (noopMouse) Wheel(horizontal bool, delta int32) error                 { return nil }
+func (noopMouse) WheelHighRes(horizontal bool, delta int32) error          { return nil }
+func (noopMouse) AutoFire(button MouseButton, interval time.Duration, count int) error {
+	return nil
+}
+func (noopMouse) AutoFireContext(ctx context.Context, button MouseButton, interval time.Duration, count int) error {
+	return nil
+}
+func (noopMouse) ScrollSmooth(delta int32, steps int, interval time.Duration) error {
+	return nil
+}
+func (noopMouse) ScrollSmoothContext(ctx context.Context, delta int32, steps int, interval time.Duration) error {
+	return nil
+}
+func (noopMouse) WheelInertia(initialVelocity float64, decel float64) error { return nil }
+func (noopMouse) WheelInertiaContext(ctx context.Context, initialVelocity float64, decel float64) error {
+	return nil
+}
+func (noopMouse) FetchSyspath() (string, error)                           { return "", nil }
+func (noopMouse) FetchSyspathContext(ctx context.Context) (string, error) { return "", nil }
+func (noopMouse) Reset() error                                            { return nil }
+func (noopMouse) Close() error                                            { return nil }
+
+type noopTouchPad struct{}
+
+func (noopTouchPad) MoveTo(x int32, y int32) error                           { return nil }
+func (noopTouchPad) MoveToPoint(p Point) error                               { return nil }
+func (noopTouchPad) PathMove(points ...Point) error                          { return nil }
+func (noopTouchPad) DrawLine(x0, y0, x1, y1 int32, steps int) error          { return nil }
+func (noopTouchPad) DragTo(x int32, y int32, steps int) error                { return nil }
+func (noopTouchPad) MoveToPixel(px int32, py int32) error                    { return nil }
+func (noopTouchPad) MoveToFraction(fx float64, fy float64) error             { return nil }
+func (noopTouchPad) SetAbsAxis(code uint16, value int32) error               { return nil }
+func (noopTouchPad) SetPressureNorm(f float64) error                         { return nil }
+func (noopTouchPad) GetPosition() (int32, int32)                             { return 0, 0 }
+func (noopTouchPad) LeftClick() error                                        { return nil }
+func (noopTouchPad) RightClick() error                                       { return nil }
+func (noopTouchPad) ClickAt(x int32, y int32) error                          { return nil }
+func (noopTouchPad) LeftPress() error                                        { return nil }
+func (noopTouchPad) LeftRelease() error                                      { return nil }
+func (noopTouchPad) RightPress() error                                       { return nil }
+func (noopTouchPad) RightRelease() error                                     { return nil }
+func (noopTouchPad) TouchDown() error                                        { return nil }
+func (noopTouchPad) TouchUp() error                                          { return nil }
+func (noopTouchPad) Scroll(hiResDelta int32) error                           { return nil }
+func (noopTouchPad) Tap() error                                              { return nil }
+func (noopTouchPad) FetchSyspath() (string, error)                           { return "", nil }
+func (noopTouchPad) FetchSyspathContext(ctx context.Context) (string, error) { return "", nil }
+func (noopTouchPad) Reset() error                                            { return nil }
+func (noopTouchPad) Close() error                                            { return nil }
+
+type noopDial struct{}
+
+func (noopDial) Turn(delta int32) error { return nil }
+func (noopDial) Reset() error           { return nil }
+func (noopDial) Close() error           { return nil }
+
+type noopGamepad struct{}
+
+func (noopGamepad) ButtonPress(key int) error               { return nil }
+func (noopGamepad) ButtonDown(key int) error                { return nil }
+func (noopGamepad) ButtonUp(key int) error                  { return nil }
+func (noopGamepad) LeftStickMoveX(value float32) error      { return nil }
+func (noopGamepad) LeftStickMoveY(value float32) error      { return nil }
+func (noopGamepad) RightStickMoveX(value float32) error     { return nil }
+func (noopGamepad) RightStickMoveY(value float32) error     { return nil }
+func (noopGamepad) LeftStickMove(x, y float32) error        { return nil }
+func (noopGamepad) RightStickMove(x, y float32) error       { return nil }
+func (noopGamepad) HatPress(direction HatDirection) error   { return nil }
+func (noopGamepad) HatRelease(direction HatDirection) error { return nil }
+func (noopGamepad) Reset() error                            { return nil }
+func (noopGamepad) Close() error                            { return nil }
+
+type noopMultiTouch struct{}
+
+func (noopMultiTouch) GetContacts() []multiTouchContact                                 { return nil }
+func (noopMultiTouch) SetContactOrientation(slot int32, value int32) error              { return nil }
+func (noopMultiTouch) SetContactToolType(slot int32, toolType MultiTouchToolType) error { return nil }
+func (noopMultiTouch) SetContactBlobID(slot int32, id int32) error                      { return nil }
+func (noopMultiTouch) MultiTap(n int, positions []Point) error                          { return nil }
+func (noopMultiTouch) FetchSyspath() (string, error)                                    { return "", nil }
+func (noopMultiTouch) FetchSyspathContext(ctx context.Context) (string, error)          { return "", nil }
+func (noopMultiTouch) Reset() error                                                     { return nil }
+func (noopMultiTouch) Close() error                                                     { return nil }
@@ -0,0 +1,58 @@
+package uinput
+
+import (
+	"reflect"
+	"testing"
+)
+
+// This test calls every method of the noop devices (using zero values as arguments) and verifies that none
+// of them returns an error. Since the methods are discovered using reflection, methods that are added to the
+// device interfaces in the future are covered automatically.
+func TestNoopDeviceMethodsReturnNil(t *testing.T) {
+	devices := map[string]interface{}{
+		"keyboard":   CreateNoopKeyboard(),
+		"mouse":      CreateNoopMouse(),
+		"touchpad":   CreateNoopTouchPad(),
+		"dial":       CreateNoopDial(),
+		"gamepad":    CreateNoopGamepad(),
+		"multitouch": CreateNoopMultiTouch(),
+	}
+
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+	for name, device := range devices {
+		v := reflect.ValueOf(device)
+		for i := 0; i < v.NumMethod(); i++ {
+			method := v.Method(i)
+			methodName := v.Type().Method(i).Name
+
+			args := make([]reflect.Value, method.Type().NumIn())
+			for j := range args {
+				args[j] = reflect.Zero(method.Type().In(j))
+			}
+
+			call := method.Call
+			if method.Type().IsVariadic() {
+				call = method.CallSlice
+			}
+			for _, result := range call(args) {
+				if result.Type() == errorType && !result.IsNil() {
+					t.Fatalf("Expected %s of noop %s to return nil, but got: %v", methodName, name, result.Interface())
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkNoopMouseMove(b *testing.B) {
+	mouse := CreateNoopMouse()
+	for i := 0; i < b.N; i++ {
+		_ = mouse.Move(1, 1)
+	}
+}
+
+func BenchmarkNoopKeyboardKeyPress(b *testing.B) {
+	keyboard := CreateNoopKeyboard()
+	for i := 0; i < b.N; i++ {
+		_ = keyboard.KeyPress(KeyA)
+	}
+}
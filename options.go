@@ -0,0 +1,344 @@
+package uinput
+
+import (
+	"time"
+)
+
+// An Option configures the optional behavior of a device upon creation. Options are passed to the
+// Create functions of the devices; options that do not apply to the device being created are ignored.
+type Option func(*deviceOptions)
+
+// deviceOptions holds the settings of all options. Every device picks the settings that apply to it.
+type deviceOptions struct {
+	scanCodes     bool
+	naturalScroll bool
+	wheelMode     WheelMode
+	clickDelay    time.Duration
+	tapDuration   time.Duration
+	maxRelMove    int32
+	maxSlots      int32
+	singleTouch   bool
+	screenWidth   int32
+	screenHeight  int32
+
+	reportInterval time.Duration
+	axisPolicy     AxisPolicy
+	absAxisOrder   AbsAxisOrder
+	hiResScroll    bool
+	flushThreshold int
+	writeAttempts  int
+	writeBackoff   time.Duration
+	eventHook      EventHook
+	validate       bool
+	logger         Logger
+	keepAlive      bool
+
+	// absResolution holds the resolution of the absolute axes, which is set by the DeviceBuilder. It can only be
+	// applied if the device is set up using UI_ABS_SETUP (see SetupMethod).
+	absResolution [absSize]int32
+
+	keys        []int
+	buttons     []MouseButton
+	leds        bool
+	initialLEDs *LEDState
+	gamepadID   *inputID
+	ffEffects   *int
+
+	createAttempts int
+	createBackoff  time.Duration
+	setupMethod    SetupMethod
+	phys           string
+}
+
+func newDeviceOptions(opts []Option) deviceOptions {
+	var options deviceOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithScanCodes makes the mouse and the keyboard report the scan code of a button or key (MSC_SCAN) before its
+// press and its release, just like real USB devices do. Some applications, like the guest additions of virtual
+// machines, rely on the scan codes in order to identify buttons and keys.
+func WithScanCodes() Option {
+	return func(options *deviceOptions) {
+		options.scanCodes = true
+	}
+}
+
+// WithNaturalScroll makes the mouse invert the direction of all wheel movements ("natural scrolling"), so that
+// callers don't need to special-case platforms that expect the inverted direction.
+func WithNaturalScroll() Option {
+	return func(options *deviceOptions) {
+		options.naturalScroll = true
+	}
+}
+
+// WithWheelMode sets how the mouse emits the wheel movements of Wheel (see WheelMode). Some consumers treat a
+// wheel event with the value N as N notches, while others only count the events. The default is WheelAccumulated.
+func WithWheelMode(mode WheelMode) Option {
+	return func(options *deviceOptions) {
+		options.wheelMode = mode
+	}
+}
+
+// WithClickDelay inserts the given delay between the press and the release of the click methods (LeftClick etc.)
+// of the mouse and the touch pad. Some consumers miss clicks whose press and release arrive at the same time.
+// The default is no delay.
+func WithClickDelay(delay time.Duration) Option {
+	return func(options *deviceOptions) {
+		options.clickDelay = delay
+	}
+}
+
+// WithTapDuration sets the time between touch down and touch up of the Tap methods of the touch pad and the
+// touchscreen, as well as of MultiTap of the multitouch device. The default of 20ms works with libinput, whose tap
+// timeout is 180ms; consumers with a shorter timeout may need a lower value, while others ignore touches that are
+// too short and need a higher one.
+func WithTapDuration(d time.Duration) Option {
+	return func(options *deviceOptions) {
+		options.tapDuration = d
+	}
+}
+
+// WithMaxRelMove limits the magnitude of the relative movements of the mouse. Moves that exceed the given maximum
+// are split into multiple reports whose values add up to the requested movement. Some consumers clamp or drop
+// large relative values, which would otherwise result in lost motion. Note that the steps are merged again if the
+// mouse has been created using WithReportInterval. Moves that would be split into more than 4096 reports are
+// rejected. The default is no limit.
+func WithMaxRelMove(max int32) Option {
+	return func(options *deviceOptions) {
+		options.maxRelMove = max
+	}
+}
+
+// WithMaxSlots sets the number of slots of the multitouch device, i.e. the number of contacts that can be tracked
+// at the same time, which is advertised as the range 0 to n-1 of ABS_MT_SLOT. This overrides the maximum amount of
+// contacts passed to CreateMultiTouch. Using a slot outside of this range results in an error.
+func WithMaxSlots(n int32) Option {
+	return func(options *deviceOptions) {
+		options.maxSlots = n
+	}
+}
+
+// WithSingleTouchEmulation makes the multitouch device register ABS_X and ABS_Y in addition to the multitouch axes,
+// and report the position of the contact in slot 0 on them as well, in the same report as its ABS_MT_POSITION_X and
+// ABS_MT_POSITION_Y events. Older consumers, like some xorg drivers, only evaluate these single touch axes.
+func WithSingleTouchEmulation() Option {
+	return func(options *deviceOptions) {
+		options.singleTouch = true
+	}
+}
+
+// WithScreenResolution sets the resolution of the screen that the touch pad maps to, which enables MoveToPixel.
+func WithScreenResolution(width, height int32) Option {
+	return func(options *deviceOptions) {
+		options.screenWidth = width
+		options.screenHeight = height
+	}
+}
+
+// WithReportInterval makes the device emit at most one report per interval, like hardware that reports at a
+// fixed rate (e.g. 8ms for a 125Hz USB device). All updates within the interval are merged into a single report
+// that is written at the end of it: relative movements are summed up, while for everything else only the final
+// state is reported. Pending updates are written when the device is closed. Supported by the mouse, the touch
+// pad, the hybrid pointer, the relative device and the slider. All other devices ignore the interval, since their
+// reports must not be merged, e.g. the press and the release of a key.
+func WithReportInterval(interval time.Duration) Option {
+	return func(options *deviceOptions) {
+		options.reportInterval = interval
+	}
+}
+
+// withoutReportInterval returns the options without a report interval, for devices whose reports must not be
+// merged (see WithReportInterval).
+func (options deviceOptions) withoutReportInterval() deviceOptions {
+	options.reportInterval = 0
+	return options
+}
+
+// WithAxisPolicy sets how values that are out of the range of an absolute axis are handled (see AxisPolicy).
+// Supported by the touch pad, the stylus and the gamepad. By default, values are passed to the device unchecked.
+func WithAxisPolicy(policy AxisPolicy) Option {
+	return func(options *deviceOptions) {
+		options.axisPolicy = policy
+	}
+}
+
+// WithAbsAxisOrder sets the order in which the touch pad, the hybrid pointer and the stylus report the axes of the
+// positions they move to (see AbsAxisOrder). By default, ABS_X is reported before ABS_Y.
+func WithAbsAxisOrder(order AbsAxisOrder) Option {
+	return func(options *deviceOptions) {
+		options.absAxisOrder = order
+	}
+}
+
+// WithHiResScroll registers a high-resolution wheel (REL_WHEEL_HI_RES) on the touch pad, which enables its Scroll
+// method. Consumers like libinput treat such wheel movements as smooth scrolling.
+func WithHiResScroll() Option {
+	return func(options *deviceOptions) {
+		options.hiResScroll = true
+	}
+}
+
+// WithFlushThreshold makes the device buffer its reports until they add up to at least the given number of events
+// (including the SYN_REPORT of every report), and write them using a single system call. This increases the
+// throughput when replaying large amounts of events, e.g. macros, at the cost of latency. Buffered reports are
+// written by Flush (see Flusher) and when the device is closed.
+func WithFlushThreshold(events int) Option {
+	return func(options *deviceOptions) {
+		options.flushThreshold = events
+	}
+}
+
+// WithNonBlocking configures how the device handles a full buffer. The device file is opened in non-blocking mode,
+// so a write fails with EAGAIN instead of hanging if the buffer of the device is full. Using this option, such
+// writes are retried using up to the given number of attempts. The backoff is the time to wait before the first
+// retry; it doubles with every further one. If all attempts fail, an error wrapping ErrWouldBlock is returned,
+// which is also the behavior without this option.
+func WithNonBlocking(attempts int, backoff time.Duration) Option {
+	return func(options *deviceOptions) {
+		options.writeAttempts = attempts
+		options.writeBackoff = backoff
+	}
+}
+
+// An EventHook receives the type, code and value of an input event that is emitted by a device.
+type EventHook func(evType uint16, code uint16, value int32)
+
+// WithEventHook makes the device invoke the given hook for every event it emits, including the SYN_REPORT that
+// completes a report, right before the events are written. This allows logging, metrics or assertions without a
+// separate recorder. The hook is called synchronously while the report is being written, so it should return
+// quickly and must not use the device itself.
+func WithEventHook(hook EventHook) Option {
+	return func(options *deviceOptions) {
+		options.eventHook = hook
+	}
+}
+
+// WithValidation makes the device check every event against the rules of the input protocol before it is written
+// (see Validator), no matter which method emitted it. The events are checked against the capabilities that the
+// kernel lists for the device, so no configuration is needed. A method whose events violate the rules does not
+// write any of them, and returns an error wrapping ErrInvalidSequence instead. Closing the device returns such an
+// error as well if keys are still pressed. This is meant for tests, and requires a device that has been created by
+// this package, unlike e.g. CreateMouseWriter.
+func WithValidation() Option {
+	return func(options *deviceOptions) {
+		options.validate = true
+	}
+}
+
+// A Logger receives the log messages of a device (see WithLogger), along with alternating keys and values that
+// describe them. It is implemented by *slog.Logger, but allows other logging libraries to be adapted as well.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// WithLogger makes the device log through the given logger, e.g. a *slog.Logger: its creation is logged at info
+// level, every write of reports at debug level and every failed write at error level. Without a logger, which is
+// the default, the device does not log at all.
+func WithLogger(logger Logger) Option {
+	return func(options *deviceOptions) {
+		options.logger = logger
+	}
+}
+
+// WithKeepAlive registers MSC_TIMESTAMP on the device, which enables its KeepAlive method (see KeepAliver). The
+// timestamps are the only events that the kernel forwards to consumers no matter whether the state of the device has
+// changed.
+func WithKeepAlive() Option {
+	return func(options *deviceOptions) {
+		options.keepAlive = true
+	}
+}
+
+// WithGamepadID sets the bus type (e.g. BusUSB), vendor, product and version of the gamepad, overriding the IDs
+// passed to CreateGamepad or those of the preset. SDL identifies controllers by a GUID that is derived from these
+// four values: each of them is stored as a little-endian 16-bit value at the bytes 0, 4, 8 and 12 of the GUID
+// (bytes 2-3 may hold a checksum of the name). For example, bus 0x03, vendor 0x045e, product 0x028e and version
+// 0x0114 result in the GUID 030000005e0400008e02000014010000 of a wired Xbox 360 controller, so setting these
+// values makes SDL apply the mapping of the controller that is emulated.
+func WithGamepadID(bustype, vendor, product, version uint16) Option {
+	return func(options *deviceOptions) {
+		options.gamepadID = &inputID{Bustype: bustype, Vendor: vendor, Product: product, Version: version}
+	}
+}
+
+// WithForceFeedback makes the gamepad register rumble effects (FF_RUMBLE), so that games can let it rumble. Up to
+// the given number of effects (1 to 96) can be uploaded at the same time. The requests of the host are answered
+// by the reader of ReadForceFeedback (see ForceFeedbackReader). The device file is opened for reading as well in
+// this case.
+func WithForceFeedback(effects int) Option {
+	return func(options *deviceOptions) {
+		options.ffEffects = &effects
+	}
+}
+
+// WithKeys makes the keyboard register only the given key codes, instead of all keys up to KEY_MAX. This is useful
+// in order to create devices that resemble a specific piece of hardware, like a numeric keypad.
+func WithKeys(keys ...int) Option {
+	return func(options *deviceOptions) {
+		options.keys = append(options.keys, keys...)
+	}
+}
+
+// WithMouseButtons makes the mouse register only the given buttons, instead of the left, right and middle button.
+// Pressing or clicking a button that is not registered results in an error that lists the registered buttons.
+func WithMouseButtons(buttons ...MouseButton) Option {
+	return func(options *deviceOptions) {
+		options.buttons = append(options.buttons, buttons...)
+	}
+}
+
+// WithLEDs makes the keyboard register the caps lock, num lock and scroll lock LEDs, so that the host can report
+// changes of the lock state to it (see ReadLEDChanges). The device file is opened for reading as well in this case.
+func WithLEDs() Option {
+	return func(options *deviceOptions) {
+		options.leds = true
+	}
+}
+
+// WithInitialLEDs works like WithLEDs, but also sets the LEDs to the given states right after the creation of the
+// keyboard, so that the emulated keyboard matches the desired lock state from the start.
+func WithInitialLEDs(caps, num, scroll bool) Option {
+	return func(options *deviceOptions) {
+		options.leds = true
+		options.initialLEDs = &LEDState{CapsLock: caps, NumLock: num, ScrollLock: scroll}
+	}
+}
+
+// WithCreateRetry makes the creation of the device (UI_DEV_CREATE) retry transient failures like EBUSY, which may
+// occur on busy systems. The device is created using up to the given number of attempts. The backoff is the time
+// to wait before the first retry; it doubles with every further one.
+func WithCreateRetry(attempts int, backoff time.Duration) Option {
+	return func(options *deviceOptions) {
+		options.createAttempts = attempts
+		options.createBackoff = backoff
+	}
+}
+
+// WithPhys sets the phys string of the device, which describes its physical location in the system (see the
+// "phys" attribute in sysfs). By default, a phys string like "uinput/virtual/<name>/input0" is generated that is
+// unique within this process, so that tools matching devices by their phys can tell devices of the same name apart.
+//
+// The phys string and the name are the only strings that uinput passes on to consumers: the name is what tools
+// like evtest and libinput display, while the phys string can be set independently of it. There is no product (or
+// vendor) string, the product is only reported as the numeric ID of the device (see WithGamepadID and
+// DeviceBuilder.SetID).
+func WithPhys(phys string) Option {
+	return func(options *deviceOptions) {
+		options.phys = phys
+	}
+}
+
+// WithSetupMethod forces the given method of configuring the device upon creation, instead of choosing it based on
+// the version of the uinput module (see SetupMethod). This is mostly useful in order to test the behavior of
+// both methods on the same kernel.
+func WithSetupMethod(method SetupMethod) Option {
+	return func(options *deviceOptions) {
+		options.setupMethod = method
+	}
+}
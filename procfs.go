@@ -0,0 +1,60 @@
+package uinput
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// procBusInputDevices is the proc file that lists all input devices of the system.
+const procBusInputDevices = "/proc/bus/input/devices"
+
+// procPollInterval is the time between two reads of the proc file in WaitForProcRegistration.
+const procPollInterval = 10 * time.Millisecond
+
+// WaitForProcRegistration blocks until a device with the given name is listed in /proc/bus/input/devices or the
+// context is done. In the latter case, the error of the context is returned. Some tools read the proc file in order
+// to find devices, so this complements FetchSyspathContext, which only waits for the device to appear in sysfs.
+func WaitForProcRegistration(ctx context.Context, name string) error {
+	return waitForProcRegistration(ctx, procBusInputDevices, name)
+}
+
+func waitForProcRegistration(ctx context.Context, path string, name string) error {
+	for {
+		listed, err := procListsDevice(path, name)
+		if listed {
+			return nil
+		}
+
+		timer := time.NewTimer(procPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("device %q is not listed in %s: %w", name, path, errors.Join(err, ctx.Err()))
+		case <-timer.C:
+		}
+	}
+}
+
+// procListsDevice reports whether the given file, which is formatted like /proc/bus/input/devices, lists a device
+// with the given name (N: Name="...").
+func procListsDevice(path string, name string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read input devices: %w", err)
+	}
+	defer file.Close()
+
+	expected := `N: Name="` + name + `"`
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == expected {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
@@ -0,0 +1,65 @@
+package uinput
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// procEntry returns the entry of a device with the given name, as listed in /proc/bus/input/devices.
+func procEntry(name string) string {
+	return fmt.Sprintf(procEntryFormat, name)
+}
+
+const procEntryFormat = `I: Bus=0003 Vendor=4711 Product=0815 Version=0001
+N: Name="%s"
+P: Phys=uinput/virtual/input0
+S: Sysfs=/devices/virtual/input/input42
+U: Uniq=
+H: Handlers=sysrq kbd event42
+B: EV=3
+
+`
+
+func TestWaitForProcRegistrationWaitsForDeviceToAppear(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "devices")
+	err := os.WriteFile(path, []byte(procEntry("Power Button")), 0644)
+	if err != nil {
+		t.Fatalf("Failed to setup test. Unable to write proc file: %v", err)
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		entries := procEntry("Power Button") + procEntry("Test Keyboard")
+		_ = os.WriteFile(path, []byte(entries), 0644)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	err = waitForProcRegistration(ctx, path, "Test Keyboard")
+	if err != nil {
+		t.Fatalf("Failed to wait for the device. Last error was: %s\n", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("Expected waiting until the device appears, but returned after %v", elapsed)
+	}
+}
+
+func TestWaitForProcRegistrationStopsOnCancellation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "devices")
+	err := os.WriteFile(path, []byte(procEntry("Test Keyboard Extra")), 0644)
+	if err != nil {
+		t.Fatalf("Failed to setup test. Unable to write proc file: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	err = waitForProcRegistration(ctx, path, "Test Keyboard")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected an error wrapping %v, but got: %v", context.DeadlineExceeded, err)
+	}
+}
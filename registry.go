@@ -0,0 +1,139 @@
+package uinput
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// registry keeps track of all devices that have been created, until they are closed.
+var registry struct {
+	sync.Mutex
+	devices []trackedDevice
+}
+
+// trackedDevice is a device file along with the reportBuilder that writes to it, which is nil until the device
+// has been set up completely.
+type trackedDevice struct {
+	deviceFile *os.File
+	report     *reportBuilder
+}
+
+func trackDevice(deviceFile *os.File) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.devices = append(registry.devices, trackedDevice{deviceFile: deviceFile})
+}
+
+// trackReport records the reportBuilder of a tracked device, so that its pending events are written when the
+// device is closed by the cleanup handler. Writers other than tracked device files are ignored.
+func trackReport(w io.Writer, report *reportBuilder) {
+	registry.Lock()
+	defer registry.Unlock()
+	for i, device := range registry.devices {
+		if device.deviceFile == w {
+			registry.devices[i].report = report
+			return
+		}
+	}
+}
+
+func untrackDevice(deviceFile *os.File) {
+	registry.Lock()
+	defer registry.Unlock()
+	for i, device := range registry.devices {
+		if device.deviceFile == deviceFile {
+			registry.devices = append(registry.devices[:i], registry.devices[i+1:]...)
+			return
+		}
+	}
+}
+
+// TrackedDevices returns the syspaths of all devices that have been created by this process and have not been
+// closed yet, in the order of their creation. This is meant for diagnostics, e.g. in order to find devices that
+// have not been cleaned up. Devices whose syspath can not be fetched are left out; their errors are returned
+// along with the syspaths of the other devices.
+func TrackedDevices() ([]string, error) {
+	registry.Lock()
+	devices := append([]trackedDevice(nil), registry.devices...)
+	registry.Unlock()
+
+	var paths []string
+	var errs []error
+	for _, device := range devices {
+		path, err := fetchSyspath(device.deviceFile)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths, errors.Join(errs...)
+}
+
+// InstallCleanupHandler installs a handler for SIGINT and SIGTERM that destroys all devices that have been created
+// by this process and are still open, so that terminating the process (e.g. using Ctrl+C) can not leave any
+// buttons pressed or devices behind. The kernel releases all keys and buttons of a device when it is destroyed.
+//
+// The handler is uninstalled after the cleanup, so that later signals take their default effect again, e.g. a
+// second Ctrl+C terminates the process while it is shutting down. Then the given function is called with the
+// received signal. This allows composing the handler with the caller's own signal handling, e.g. in order to shut
+// down gracefully. If it is nil, the signal is raised again: if the caller has not installed any handlers for the
+// signal using signal.Notify, this terminates the process as if no handler was installed, otherwise the signal is
+// delivered to the caller's handlers. Note that any Close method called on the devices afterwards will fail, since they
+// have already been closed. The returned function uninstalls the handler.
+func InstallCleanupHandler(onSignal func(sig os.Signal)) (uninstall func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go handleSignals(signals, done, onSignal)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
+
+// handleSignals waits for the first signal until done is closed, and cleans up the devices once it is received.
+// The given channel stops receiving signals afterwards, so that further signals take their default effect (or
+// reach the caller's handlers) instead of being swallowed. Only this channel is stopped, since signal.Reset would
+// remove the handlers of the caller as well.
+func handleSignals(signals chan os.Signal, done <-chan struct{}, onSignal func(sig os.Signal)) {
+	select {
+	case <-done:
+		return
+	case sig := <-signals:
+		_ = closeTrackedDevices()
+		signal.Stop(signals)
+		if onSignal != nil {
+			onSignal(sig)
+			return
+		}
+		if sysSig, ok := sig.(syscall.Signal); ok {
+			_ = syscall.Kill(os.Getpid(), sysSig)
+		}
+	}
+}
+
+// closeTrackedDevices closes all devices that are still tracked, writing the pending events of their reports first.
+func closeTrackedDevices() error {
+	registry.Lock()
+	devices := append([]trackedDevice(nil), registry.devices...)
+	registry.Unlock()
+
+	var errs []error
+	for _, device := range devices {
+		if device.report == nil {
+			errs = append(errs, closeDevice(device.deviceFile))
+			continue
+		}
+		errs = append(errs, closeDeviceWithReport(device.report, device.deviceFile))
+	}
+	return errors.Join(errs...)
+}
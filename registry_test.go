@@ -0,0 +1,159 @@
+package uinput
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestTrackedDevicesListsCreatedDevices(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	_, restore := fakeIoctl(nil)
+	defer restore()
+
+	before, err := TrackedDevices()
+	if err != nil {
+		t.Fatalf("Failed to list tracked devices. Last error was: %s\n", err)
+	}
+
+	dial, err := CreateDial(path, []byte("Test Dial"))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual dial. Last error was: %s\n", err)
+	}
+	scroll, err := CreateScrollDevice(path, []byte("Test Scroll Device"))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual scroll device. Last error was: %s\n", err)
+	}
+
+	tracked, err := TrackedDevices()
+	if err != nil {
+		t.Fatalf("Failed to list tracked devices. Last error was: %s\n", err)
+	}
+	if len(tracked) != len(before)+2 {
+		t.Fatalf("Expected both devices to be tracked, but got %v", tracked)
+	}
+
+	_ = dial.Close()
+	_ = scroll.Close()
+
+	tracked, err = TrackedDevices()
+	if err != nil {
+		t.Fatalf("Failed to list tracked devices. Last error was: %s\n", err)
+	}
+	if len(tracked) != len(before) {
+		t.Fatalf("Expected closed devices not to be tracked anymore, but got %v", tracked)
+	}
+}
+
+func TestCleanupHandlerClosesTrackedDevices(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	_, restore := fakeIoctl(nil)
+	defer restore()
+
+	dial, err := CreateDial(path, []byte("Test Dial"))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual dial. Last error was: %s\n", err)
+	}
+	deviceFile := dial.(vDial).deviceFile
+
+	signals := make(chan os.Signal, 1)
+	received := make(chan os.Signal, 1)
+	go handleSignals(signals, make(chan struct{}), func(sig os.Signal) { received <- sig })
+	signals <- syscall.SIGTERM
+
+	if sig := <-received; sig != syscall.SIGTERM {
+		t.Fatalf("Expected the handler to be called with SIGTERM, but got %v", sig)
+	}
+	_, err = deviceFile.Write([]byte{0})
+	if !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("Expected device file to be closed, but write returned: %v", err)
+	}
+	for _, device := range registry.devices {
+		if device.deviceFile == deviceFile {
+			t.Fatalf("Expected the closed device not to be tracked anymore.")
+		}
+	}
+}
+
+func TestCleanupHandlerWritesPendingEvents(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	_, restore := fakeIoctl(nil)
+	defer restore()
+
+	dial, err := CreateDial(path, []byte("Test Dial"))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual dial. Last error was: %s\n", err)
+	}
+	err = dial.(vDial).SendEvent(evRel, relDial, 1)
+	if err != nil {
+		t.Fatalf("Failed to send event. Last error was: %s\n", err)
+	}
+
+	_ = closeTrackedDevices()
+	// the pending event is completed by a SYN_REPORT
+	if stats := dial.(vDial).Stats(); stats.EventsWritten != 2 {
+		t.Fatalf("Expected the pending event to be written before closing, but got %+v", stats)
+	}
+}
+
+func TestCleanupHandlerKeepsOtherSignalHandlers(t *testing.T) {
+	own := make(chan os.Signal, 1)
+	signal.Notify(own, syscall.SIGUSR1)
+	defer signal.Stop(own)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	go handleSignals(signals, make(chan struct{}), nil)
+	signals <- syscall.SIGUSR1
+
+	// the signal is raised again, which must reach the handler that is still installed instead of the default one
+	select {
+	case sig := <-own:
+		if sig != syscall.SIGUSR1 {
+			t.Fatalf("Expected SIGUSR1, but got %v", sig)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the signal to be delivered to the other handler.")
+	}
+}
+
+func TestCleanupHandlerDoesNotSwallowLaterSignals(t *testing.T) {
+	own := make(chan os.Signal, 1)
+	signal.Notify(own, syscall.SIGUSR2)
+	defer signal.Stop(own)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR2)
+	handled := make(chan struct{})
+	go handleSignals(signals, make(chan struct{}), func(sig os.Signal) { close(handled) })
+	signals <- syscall.SIGUSR2
+	<-handled
+
+	err := syscall.Kill(os.Getpid(), syscall.SIGUSR2)
+	if err != nil {
+		t.Fatalf("Failed to raise the signal. Last error was: %s\n", err)
+	}
+	select {
+	case <-own:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the second signal to be delivered to the other handler.")
+	}
+	if len(signals) != 0 {
+		t.Fatalf("Expected the channel of the cleanup handler to be stopped after the first signal.")
+	}
+}
+
+func TestUninstalledCleanupHandlerDoesNothing(t *testing.T) {
+	uninstall := InstallCleanupHandler(func(sig os.Signal) {
+		t.Errorf("Expected the uninstalled handler not to be called, but it was called with %v", sig)
+	})
+	uninstall()
+	// uninstalling twice is fine
+	uninstall()
+}
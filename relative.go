@@ -0,0 +1,129 @@
+package uinput
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// A RelativeDevice is a device with an arbitrary set of relative axes (REL_X, REL_Y, REL_Z, REL_RX, REL_RY,
+// REL_RZ, ...), like a custom relative controller. Unlike the Mouse, it batches the movements of several axes into
+// a single report.
+type RelativeDevice interface {
+	// SetRel will add a movement along the given relative axis to the current report, without writing it yet.
+	// The axis must have been registered upon creation.
+	SetRel(code uint16, value int32) error
+
+	// Sync will write the movements that have been added by SetRel as a single report.
+	Sync() error
+
+	// FetchSyspath will return the syspath to the device file.
+	FetchSyspath() (string, error)
+
+	// FetchSyspathContext works like FetchSyspath, but retries until the syspath is available or the context is
+	// done. In the latter case, the last error is returned along with the error of the context.
+	FetchSyspathContext(ctx context.Context) (string, error)
+
+	// Reset is a no-op, since a relative device has neither buttons nor absolute axes (see Resetter).
+	Reset() error
+
+	io.Closer
+}
+
+type vRelativeDevice struct {
+	deviceBase
+	// axes is the set of relative axes that have been registered
+	axes map[uint16]bool
+}
+
+// CreateRelativeDevice will create a new device that registers the given relative axes (e.g. REL_X, REL_Y and
+// REL_Z, which are 0x00, 0x01 and 0x02). At least one axis is required.
+func CreateRelativeDevice(path string, name []byte, axes []uint16, opts ...Option) (RelativeDevice, error) {
+	err := validateDevicePath(path)
+	if err != nil {
+		return nil, err
+	}
+	err = validateUinputName(name)
+	if err != nil {
+		return nil, err
+	}
+	registered, err := validateRelAxes(axes)
+	if err != nil {
+		return nil, err
+	}
+
+	options := newDeviceOptions(opts)
+	fd, err := createRelativeDevice(path, name, axes, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return vRelativeDevice{
+		deviceBase: deviceBase{name: name, deviceFile: fd, report: newReportBuilderWithOptions(fd, name, options)},
+		axes:       registered,
+	}, nil
+}
+
+// validateRelAxes checks that the given axes are distinct REL_* codes, and returns them as a set.
+func validateRelAxes(axes []uint16) (map[uint16]bool, error) {
+	if len(axes) == 0 {
+		return nil, fmt.Errorf("no relative axes given. Expected at least one axis")
+	}
+	registered := make(map[uint16]bool, len(axes))
+	for _, code := range axes {
+		if code > relMax {
+			return nil, fmt.Errorf("relative axis code %d is out of range. Expected a value between 0 and %d", code, relMax)
+		}
+		if registered[code] {
+			return nil, fmt.Errorf("relative axis %d is given more than once", code)
+		}
+		registered[code] = true
+	}
+	return registered, nil
+}
+
+// SetRel will add a REL event to the current report, which is written by Sync.
+func (vRel vRelativeDevice) SetRel(code uint16, value int32) error {
+	if !vRel.axes[code] {
+		return fmt.Errorf("relative axis %d is not registered", code)
+	}
+	return vRel.report.WriteEventNoSync(evRel, code, value)
+}
+
+// Reset releases the keys that have been pressed using raw events (see EventSender), if any. Since a relative
+// device has neither buttons nor absolute axes of its own, it emits nothing otherwise (see Resetter).
+func (vRel vRelativeDevice) Reset() error {
+	return vRel.report.reset()
+}
+
+func createRelativeDevice(path string, name []byte, axes []uint16, options deviceOptions) (fd *os.File, err error) {
+	deviceFile, err := createDeviceFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not create relative input device: %w", err)
+	}
+
+	err = registerDevice(deviceFile, uintptr(evRel))
+	if err != nil {
+		deviceFile.Close()
+		return nil, fmt.Errorf("failed to register relative input device: %w", err)
+	}
+
+	for _, code := range axes {
+		err = ioctl(deviceFile, uiSetRelBit, uintptr(code))
+		if err != nil {
+			deviceFile.Close()
+			return nil, fmt.Errorf("failed to register relative axis event %v: %w", code, err)
+		}
+	}
+
+	return createUsbDevice(deviceFile,
+		uinputUserDev{
+			Name: toUinputName(name),
+			ID: inputID{
+				Bustype: busUsb,
+				Vendor:  0x4711,
+				Product: 0x0820,
+				Version: 1}},
+		options)
+}
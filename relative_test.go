@@ -0,0 +1,106 @@
+package uinput
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRelativeDeviceSetRel(t *testing.T) {
+	dev, err := CreateRelativeDevice("/dev/uinput", []byte("Test Relative Device"), []uint16{relX, relY, relZ})
+	if err != nil {
+		t.Fatalf("Failed to create the virtual relative device. Last error was: %s\n", err)
+	}
+
+	err = dev.SetRel(relZ, 5)
+	if err != nil {
+		t.Fatalf("Failed to set relative axis. Last error was: %s\n", err)
+	}
+	err = dev.Sync()
+	if err != nil {
+		t.Fatalf("Failed to sync. Last error was: %s\n", err)
+	}
+
+	err = dev.Close()
+	if err != nil {
+		t.Fatalf("Failed to close device. Last error was: %s\n", err)
+	}
+}
+
+func TestRelativeDeviceRegistersGivenAxes(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	dev, err := CreateRelativeDevice(path, []byte("Test Relative Device"), []uint16{relX, relRY, relRZ})
+	if err != nil {
+		t.Fatalf("Failed to create the virtual relative device. Last error was: %s\n", err)
+	}
+	defer dev.Close()
+
+	relBits := registeredCodes(*calls, uiSetRelBit)
+	if !reflect.DeepEqual(relBits, []uintptr{relX, relRY, relRZ}) {
+		t.Fatalf("Expected REL_X, REL_RY and REL_RZ to be registered, but got %v", relBits)
+	}
+}
+
+func TestRelativeDeviceEmitsAxesInOneReport(t *testing.T) {
+	file, stop := recordEvents(t)
+	axes, err := validateRelAxes([]uint16{relX, relY, relZ})
+	if err != nil {
+		t.Fatalf("Failed to validate the axes. Last error was: %s\n", err)
+	}
+	dev := vRelativeDevice{deviceBase: deviceBase{report: newReportBuilder(file)}, axes: axes}
+
+	for _, ev := range []struct {
+		code  uint16
+		value int32
+	}{{relX, 3}, {relY, -4}, {relZ, 5}} {
+		err = dev.SetRel(ev.code, ev.value)
+		if err != nil {
+			t.Fatalf("Failed to set relative axis. Last error was: %s\n", err)
+		}
+	}
+	err = dev.Sync()
+	if err != nil {
+		t.Fatalf("Failed to sync. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evRel, Code: relX, Value: 3},
+		{Type: evRel, Code: relY, Value: -4},
+		{Type: evRel, Code: relZ, Value: 5},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestRelativeDeviceSetRelFailsForUnregisteredAxis(t *testing.T) {
+	dev := vRelativeDevice{deviceBase: deviceBase{report: newReportBuilder(&writeCounter{})}, axes: map[uint16]bool{relX: true}}
+
+	err := dev.SetRel(relWheel, 1)
+	if err == nil {
+		t.Fatalf("Expected setting an unregistered axis to fail, but no error was returned.")
+	}
+}
+
+func TestRelativeDeviceCreationFailsOnInvalidAxes(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	_, restore := fakeIoctl(nil)
+	defer restore()
+
+	for _, axes := range [][]uint16{nil, {relX, 0x10}, {relX, relY, relX}} {
+		_, err := CreateRelativeDevice(path, []byte("Test Relative Device"), axes)
+		if err == nil {
+			t.Fatalf("Expected creation to fail for the axes %v, but no error was returned.", axes)
+		}
+	}
+}
+
+func TestRelativeDeviceCreationFailsOnEmptyPath(t *testing.T) {
+	expected := "device path must not be empty"
+	_, err := CreateRelativeDevice("", []byte("RelativeDevice"), []uint16{relX})
+	if err.Error() != expected {
+		t.Fatalf("Expected: %s\nActual: %s", expected, err)
+	}
+}
@@ -0,0 +1,355 @@
+package uinput
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// A reportBuilder collects the input events that make up a report and writes them to the device at once:
+// all events are serialized into a single buffer, followed by a SYN_REPORT, and handed to the writer in
+// one call. Every device owns a reportBuilder for its device file, which keeps the write and sync logic
+// in one place and ensures that concurrent callers can not interleave the events of their reports.
+//
+// If an interval is set, reports are not written right away. Instead, all reports within the interval are
+// coalesced into a single one that reflects the final state, which models hardware that reports at a fixed
+// rate. The error of such a deferred write is returned by the next call.
+//
+// If a threshold is set, complete reports are buffered until they add up to at least the given number of events,
+// and only then written at once. This reduces the number of system calls when replaying large amounts of events.
+type reportBuilder struct {
+	mu       sync.Mutex
+	w        io.Writer
+	name     string // the name of the device, which is added to write errors
+	events   []inputEvent
+	interval time.Duration
+	timer    *time.Timer
+	err      error
+
+	threshold     int
+	buffer        []byte
+	buffered      int
+	bufferedSyncs int
+
+	// writeAttempts and writeBackoff configure the retries of writes that fail with EAGAIN
+	writeAttempts int
+	writeBackoff  time.Duration
+
+	// hook is invoked for every event before it is written (see WithEventHook)
+	hook EventHook
+	// validate is set if the events need to be validated before they are added to a report, using validator,
+	// which is created from the capabilities of the device once it is needed (see WithValidation)
+	validate  bool
+	validator *Validator
+	// logger logs the writes of the device, or nil if the device does not log (see WithLogger)
+	logger Logger
+	// held is the set of keys that have been pressed, but not released yet (see Resetter)
+	held map[uint16]bool
+	// abs holds the last value of every absolute axis, except for the multitouch axes (see Device.GetAxis)
+	abs map[uint16]int32
+	// keepAlive is set if MSC_TIMESTAMP has been registered, and stopKeepAlive stops the keep-alive reports, or is
+	// nil if none are emitted (see KeepAliver)
+	keepAlive     bool
+	stopKeepAlive func()
+	// stats counts the events that have been written (see StatsReporter)
+	stats Stats
+	// lastErr is the error of the most recent write that failed, and healthy is set if the most recent write
+	// succeeded or nothing has been written yet (see HealthReporter)
+	lastErr error
+	healthy bool
+}
+
+func newReportBuilder(w io.Writer) *reportBuilder {
+	return &reportBuilder{w: w, healthy: true}
+}
+
+// newReportBuilderWithOptions creates a reportBuilder for the named device that honors the given device options.
+func newReportBuilderWithOptions(w io.Writer, name []byte, options deviceOptions) *reportBuilder {
+	rb := &reportBuilder{
+		w:             w,
+		name:          string(name),
+		interval:      options.reportInterval,
+		threshold:     options.flushThreshold,
+		writeAttempts: options.writeAttempts,
+		writeBackoff:  options.writeBackoff,
+		hook:          options.eventHook,
+		validate:      options.validate,
+		logger:        options.logger,
+		keepAlive:     options.keepAlive,
+		healthy:       true,
+	}
+	trackReport(w, rb)
+	return rb
+}
+
+// A Flusher writes buffered reports to the device. All devices of this package (except for the noop devices)
+// implement Flusher, which is only needed if they have been created using WithFlushThreshold.
+type Flusher interface {
+	Flush() error
+}
+
+// Flush writes all complete reports that have been buffered due to the flush threshold. Pending events that
+// have not been completed by a SYN_REPORT yet are left untouched.
+func (rb *reportBuilder) Flush() error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return rb.writeBufferLocked()
+}
+
+// add appends the given events to the pending report, without writing them.
+func (rb *reportBuilder) add(events ...inputEvent) error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	err := rb.validateLocked(events)
+	if err != nil {
+		return err
+	}
+	rb.events = append(rb.events, events...)
+	return nil
+}
+
+// validateLocked checks the given events before they are added to a report, if the device has been created using
+// WithValidation. Since events may be coalesced later on, they are validated as they are passed in.
+func (rb *reportBuilder) validateLocked(events []inputEvent) error {
+	if !rb.validate {
+		return nil
+	}
+	if rb.validator == nil {
+		config, err := deviceCapabilities(rb.w)
+		if err != nil {
+			return fmt.Errorf("failed to read the capabilities of the device to validate its events: %w", err)
+		}
+		rb.validator = NewValidator(nil, config)
+	}
+	return rb.validator.validateReport(events)
+}
+
+// heldKeys returns an error if keys are still pressed when the device is closed, if it validates its events (see
+// WithValidation).
+func (rb *reportBuilder) heldKeys() error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	if rb.validator == nil {
+		return nil
+	}
+	return rb.validator.heldKeys()
+}
+
+// flush writes all pending events, followed by a SYN_REPORT.
+func (rb *reportBuilder) flush() error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return rb.flushLocked()
+}
+
+// send writes the given events (along with any pending ones) as a single report.
+func (rb *reportBuilder) send(events ...inputEvent) error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return rb.sendLocked(events)
+}
+
+func (rb *reportBuilder) sendLocked(events []inputEvent) error {
+	err := rb.validateLocked(events)
+	if err != nil {
+		return err
+	}
+	if rb.interval <= 0 {
+		rb.events = append(rb.events, events...)
+		return rb.flushLocked()
+	}
+
+	rb.events = coalesceEvents(rb.events, events)
+	if rb.timer == nil {
+		rb.timer = time.AfterFunc(rb.interval, rb.flushDeferred)
+	}
+	err = rb.err
+	rb.err = nil
+	return err
+}
+
+// close writes the events that are still pending due to the interval. It needs to be called before the
+// device is closed.
+func (rb *reportBuilder) close() error {
+	rb.endKeepAlive()
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	if rb.timer != nil {
+		rb.timer.Stop()
+		rb.timer = nil
+	}
+	err := rb.err
+	rb.err = nil
+	if len(rb.events) > 0 {
+		err = errors.Join(err, rb.flushLocked())
+	}
+	return errors.Join(err, rb.writeBufferLocked())
+}
+
+func (rb *reportBuilder) flushDeferred() {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	rb.timer = nil
+	if len(rb.events) == 0 {
+		return
+	}
+	if err := rb.flushLocked(); err != nil {
+		rb.err = err
+	}
+}
+
+// coalesceEvents merges the given events into the pending ones: relative values are summed up, while all other
+// events replace a pending event of the same type and code, so that only the final state is reported.
+func coalesceEvents(pending []inputEvent, events []inputEvent) []inputEvent {
+	for _, ev := range events {
+		merged := false
+		for i := range pending {
+			if pending[i].Type == ev.Type && pending[i].Code == ev.Code {
+				if ev.Type == evRel {
+					pending[i].Value += ev.Value
+				} else {
+					pending[i].Value = ev.Value
+				}
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			pending = append(pending, ev)
+		}
+	}
+	return pending
+}
+
+func (rb *reportBuilder) flushLocked() error {
+	events := append(rb.events, inputEvent{
+		Time:  syscall.Timeval{Sec: 0, Usec: 0},
+		Type:  evSyn,
+		Code:  uint16(synReport),
+		Value: 0})
+	rb.events = nil
+
+	err := rb.bufferLocked(events)
+	if err != nil {
+		return err
+	}
+	if rb.buffered < rb.threshold {
+		return nil
+	}
+	return rb.writeBufferLocked()
+}
+
+// bufferLocked serializes the given events and appends them to the buffer.
+func (rb *reportBuilder) bufferLocked(events []inputEvent) error {
+	buf := make([]byte, 0, len(events)*24)
+	for _, ev := range events {
+		evBuf, err := inputEventToBuffer(ev)
+		if err != nil {
+			return fmt.Errorf("failed to serialize report: %w", err)
+		}
+		buf = append(buf, evBuf...)
+	}
+	if rb.hook != nil {
+		for _, ev := range events {
+			rb.hook(ev.Type, ev.Code, ev.Value)
+		}
+	}
+	if rb.held == nil {
+		rb.held = make(map[uint16]bool)
+	}
+	trackKeys(rb.held, events)
+	rb.trackAbsLocked(events)
+
+	rb.buffer = append(rb.buffer, buf...)
+	rb.buffered += len(events)
+	for _, ev := range events {
+		if ev.Type == evSyn && ev.Code == synReport {
+			rb.bufferedSyncs++
+		}
+	}
+	return nil
+}
+
+// writeBufferLocked writes all buffered reports at once.
+func (rb *reportBuilder) writeBufferLocked() error {
+	if len(rb.buffer) == 0 {
+		return nil
+	}
+	buf := rb.buffer
+	events, syncs := rb.buffered, rb.bufferedSyncs
+	rb.buffer = nil
+	rb.buffered = 0
+	rb.bufferedSyncs = 0
+
+	err := rb.writeWithRetry(buf)
+	if err != nil {
+		if rb.name != "" {
+			err = fmt.Errorf("failed to write report to device file of %q: %w", rb.name, classifyWriteError(err))
+		} else {
+			err = fmt.Errorf("failed to write report to device file: %w", classifyWriteError(err))
+		}
+		rb.lastErr = err
+		rb.healthy = false
+		if rb.logger != nil {
+			rb.logger.Error("failed to write report", "device", rb.name, "error", err)
+		}
+		return err
+	}
+	rb.healthy = true
+	if rb.logger != nil {
+		rb.logger.Debug("wrote report", "device", rb.name, "events", events, "reports", syncs)
+	}
+	rb.stats.EventsWritten += uint64(events)
+	rb.stats.Syncs += uint64(syncs)
+	return nil
+}
+
+// writeWithRetry writes the given buffer. If the buffer of the device is full (EAGAIN), the remainder is written
+// again as configured by WithNonBlocking, doubling the backoff after every attempt.
+func (rb *reportBuilder) writeWithRetry(buf []byte) error {
+	backoff := rb.writeBackoff
+	for attempt := 1; ; attempt++ {
+		n, err := rb.w.Write(buf)
+		if err == nil || !errors.Is(err, syscall.EAGAIN) || attempt >= rb.writeAttempts {
+			return err
+		}
+		buf = buf[n:]
+		rb.stats.WriteRetries++
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// deviceGoneError marks a write error that indicates that the device does no longer exist.
+type deviceGoneError struct {
+	err error
+}
+
+func (e deviceGoneError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrDeviceGone, e.err)
+}
+
+func (e deviceGoneError) Is(target error) bool {
+	return target == ErrDeviceGone
+}
+
+func (e deviceGoneError) Unwrap() error {
+	return e.err
+}
+
+// classifyWriteError marks errors that indicate that the device is gone (see ErrDeviceGone), so that callers can
+// tell them apart from transient ones, as well as errors that indicate that the buffer of the device is full (see
+// ErrWouldBlock). All other errors are returned as they are.
+func classifyWriteError(err error) error {
+	if errors.Is(err, syscall.ENODEV) || errors.Is(err, syscall.EBADF) || errors.Is(err, os.ErrClosed) {
+		return deviceGoneError{err: err}
+	}
+	if errors.Is(err, syscall.EAGAIN) {
+		return fmt.Errorf("%w: %w", ErrWouldBlock, err)
+	}
+	return err
+}
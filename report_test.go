@@ -0,0 +1,354 @@
+package uinput
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// writeCounter counts the calls to Write, so that it can be verified that a report is written at once.
+type writeCounter struct {
+	writes int
+	data   []byte
+}
+
+func (w *writeCounter) Write(p []byte) (int, error) {
+	w.writes++
+	w.data = append(w.data, p...)
+	return len(p), nil
+}
+
+func TestReportBuilderWritesEventsInOrderWithSingleSync(t *testing.T) {
+	file, stop := recordEvents(t)
+	report := newReportBuilder(file)
+
+	report.add(inputEvent{Type: evAbs, Code: absX, Value: 10})
+	err := report.send(
+		inputEvent{Type: evAbs, Code: absY, Value: 20},
+		inputEvent{Type: evKey, Code: evBtnTouch, Value: btnStatePressed})
+	if err != nil {
+		t.Fatalf("Failed to send report. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absX, Value: 10},
+		{Type: evAbs, Code: absY, Value: 20},
+		{Type: evKey, Code: evBtnTouch, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestReportBuilderUsesSingleWrite(t *testing.T) {
+	w := &writeCounter{}
+	report := newReportBuilder(w)
+
+	err := report.send(
+		inputEvent{Type: evRel, Code: relX, Value: 1},
+		inputEvent{Type: evRel, Code: relY, Value: 2})
+	if err != nil {
+		t.Fatalf("Failed to send report. Last error was: %s\n", err)
+	}
+
+	if w.writes != 1 {
+		t.Fatalf("Expected the report to be written at once, but got %d writes", w.writes)
+	}
+	if len(w.data) != 3*24 {
+		t.Fatalf("Expected 3 events (72 bytes) to be written, but got %d bytes", len(w.data))
+	}
+}
+
+func TestReportBuilderFlushWithoutEventsOnlySyncs(t *testing.T) {
+	file, stop := recordEvents(t)
+	report := newReportBuilder(file)
+
+	err := report.flush()
+	if err != nil {
+		t.Fatalf("Failed to flush report. Last error was: %s\n", err)
+	}
+	// nothing is pending anymore, so a second flush only syncs as well
+	err = report.flush()
+	if err != nil {
+		t.Fatalf("Failed to flush report. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evSyn, Code: synReport},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+// failingWriter returns the given error on every write.
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+func TestWriteErrorsIndicatingARemovedDeviceAreClassified(t *testing.T) {
+	for _, errno := range []syscall.Errno{syscall.ENODEV, syscall.EBADF} {
+		relDev, err := CreateMouseWriter(failingWriter{err: &os.PathError{Op: "write", Path: "/dev/uinput", Err: errno}}, []byte("Test Mouse"))
+		if err != nil {
+			t.Fatalf("Failed to create the dry run mouse. Last error was: %s\n", err)
+		}
+
+		err = relDev.MoveRight(1)
+		if !errors.Is(err, ErrDeviceGone) {
+			t.Fatalf("Expected error to be ErrDeviceGone for %v, but got: %v", errno, err)
+		}
+		if !errors.Is(err, errno) {
+			t.Fatalf("Expected error to still wrap %v, but got: %v", errno, err)
+		}
+	}
+}
+
+func TestWriteToClosedDeviceFileIsClassifiedAsGone(t *testing.T) {
+	file, stop := recordEvents(t)
+	stop()
+
+	err := newReportBuilder(file).send(inputEvent{Type: evRel, Code: relX, Value: 1})
+	if !errors.Is(err, ErrDeviceGone) {
+		t.Fatalf("Expected error to be ErrDeviceGone, but got: %v", err)
+	}
+}
+
+func TestTransientWriteErrorsAreNotClassifiedAsGone(t *testing.T) {
+	err := newReportBuilder(failingWriter{err: syscall.EAGAIN}).send(inputEvent{Type: evRel, Code: relX, Value: 1})
+	if err == nil {
+		t.Fatalf("Expected write to fail, but got no error.")
+	}
+	if errors.Is(err, ErrDeviceGone) {
+		t.Fatalf("Expected transient error not to be ErrDeviceGone, but got: %v", err)
+	}
+}
+
+func TestReportsWithinIntervalAreCoalesced(t *testing.T) {
+	file, stop := recordEvents(t)
+	interval := 50 * time.Millisecond
+	dev := &vTouchPad{deviceBase: deviceBase{report: newReportBuilderWithOptions(file, nil, deviceOptions{reportInterval: interval})}}
+
+	for _, pos := range [][2]int32{{10, 20}, {30, 40}, {50, 60}} {
+		err := dev.MoveTo(pos[0], pos[1])
+		if err != nil {
+			t.Fatalf("Failed to move touch pad. Last error was: %s\n", err)
+		}
+	}
+	time.Sleep(2 * interval)
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absX, Value: 50},
+		{Type: evAbs, Code: absY, Value: 60},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestCoalescedRelativeMovesAreSummedAndFlushedOnClose(t *testing.T) {
+	file, stop := recordEvents(t)
+	mouse, err := CreateMouseWriter(file, []byte("Test Mouse"), WithReportInterval(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to create the dry run mouse. Last error was: %s\n", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		err = mouse.Move(int32(i+1), -1)
+		if err != nil {
+			t.Fatalf("Failed to move mouse. Last error was: %s\n", err)
+		}
+	}
+	err = mouse.Close()
+	if err != nil {
+		t.Fatalf("Failed to close mouse. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evRel, Code: relX, Value: 6},
+		{Type: evRel, Code: relY, Value: -3},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestDeferredWriteErrorIsReturnedByNextCall(t *testing.T) {
+	rb := newReportBuilderWithOptions(failingWriter{err: syscall.ENODEV}, nil, deviceOptions{reportInterval: time.Millisecond})
+
+	err := rb.send(inputEvent{Type: evRel, Code: relX, Value: 1})
+	if err != nil {
+		t.Fatalf("Expected the first send to be deferred, but got: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	err = rb.send(inputEvent{Type: evRel, Code: relX, Value: 1})
+	if !errors.Is(err, ErrDeviceGone) {
+		t.Fatalf("Expected the error of the deferred write to be returned, but got: %v", err)
+	}
+}
+
+func TestReportsAreBufferedUntilThresholdIsReached(t *testing.T) {
+	w := &writeCounter{}
+	report := newReportBuilderWithOptions(w, nil, deviceOptions{flushThreshold: 6})
+
+	// every report consists of two events, including the SYN_REPORT
+	for i := 0; i < 2; i++ {
+		err := report.send(inputEvent{Type: evKey, Code: KeyA, Value: btnStatePressed})
+		if err != nil {
+			t.Fatalf("Failed to send report. Last error was: %s\n", err)
+		}
+	}
+	if w.writes != 0 {
+		t.Fatalf("Expected reports to be buffered below the threshold, but got %d writes", w.writes)
+	}
+
+	err := report.send(inputEvent{Type: evKey, Code: KeyA, Value: btnStateReleased})
+	if err != nil {
+		t.Fatalf("Failed to send report. Last error was: %s\n", err)
+	}
+	if w.writes != 1 || len(w.data) != 6*24 {
+		t.Fatalf("Expected 6 events to be written at once, but got %d writes of %d bytes", w.writes, len(w.data))
+	}
+}
+
+func TestFlushWritesBufferedReports(t *testing.T) {
+	w := &writeCounter{}
+	report := newReportBuilderWithOptions(w, nil, deviceOptions{flushThreshold: 100})
+
+	err := report.send(inputEvent{Type: evKey, Code: KeyA, Value: btnStatePressed})
+	if err != nil {
+		t.Fatalf("Failed to send report. Last error was: %s\n", err)
+	}
+	err = report.Flush()
+	if err != nil {
+		t.Fatalf("Failed to flush reports. Last error was: %s\n", err)
+	}
+	if w.writes != 1 || len(w.data) != 2*24 {
+		t.Fatalf("Expected the buffered report to be written, but got %d writes of %d bytes", w.writes, len(w.data))
+	}
+
+	// nothing is left to be written
+	err = report.close()
+	if err != nil {
+		t.Fatalf("Failed to close report builder. Last error was: %s\n", err)
+	}
+	if w.writes != 1 {
+		t.Fatalf("Expected no further writes, but got %d writes", w.writes)
+	}
+}
+
+func benchmarkReports(b *testing.B, options deviceOptions) {
+	file, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatalf("Failed to setup benchmark. Unable to open %s: %v", os.DevNull, err)
+	}
+	defer file.Close()
+	report := newReportBuilderWithOptions(file, nil, options)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = report.send(inputEvent{Type: evKey, Code: KeyA, Value: btnStatePressed})
+	}
+	_ = report.close()
+}
+
+func BenchmarkReportsWithoutThreshold(b *testing.B) {
+	benchmarkReports(b, deviceOptions{})
+}
+
+func BenchmarkReportsWithThreshold(b *testing.B) {
+	benchmarkReports(b, deviceOptions{flushThreshold: 256})
+}
+
+func TestWriteErrorsIdentifyTheDevice(t *testing.T) {
+	mouse, err := CreateMouseWriter(failingWriter{err: syscall.EIO}, []byte("Test Mouse"))
+	if err != nil {
+		t.Fatalf("Failed to create the mouse. Last error was: %s\n", err)
+	}
+
+	err = mouse.Move(1, 1)
+	if err == nil || !strings.Contains(err.Error(), `"Test Mouse"`) {
+		t.Fatalf("Expected the write error to contain the name of the device, but got: %v", err)
+	}
+}
+
+// blockingWriter fails with EAGAIN for the given number of writes, before writing to the writeCounter.
+type blockingWriter struct {
+	writeCounter
+	failures int
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	if w.failures > 0 {
+		w.failures--
+		return 0, &os.PathError{Op: "write", Path: "/dev/uinput", Err: syscall.EAGAIN}
+	}
+	return w.writeCounter.Write(p)
+}
+
+func TestWriteIsRetriedIfDeviceBufferIsFull(t *testing.T) {
+	w := &blockingWriter{failures: 2}
+	mouse, err := CreateMouseWriter(w, []byte("Test Mouse"), WithNonBlocking(3, time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create the mouse. Last error was: %s\n", err)
+	}
+
+	err = mouse.MoveRight(1)
+	if err != nil {
+		t.Fatalf("Expected the write to succeed after retrying, but got: %v", err)
+	}
+	if len(w.data) != 2*24 {
+		t.Fatalf("Expected the report to be written, but got %d bytes", len(w.data))
+	}
+}
+
+func TestFullDeviceBufferIsReportedAsWouldBlock(t *testing.T) {
+	w := &blockingWriter{failures: 3}
+	mouse, err := CreateMouseWriter(w, []byte("Test Mouse"), WithNonBlocking(3, time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create the mouse. Last error was: %s\n", err)
+	}
+
+	err = mouse.MoveRight(1)
+	if !errors.Is(err, ErrWouldBlock) || !errors.Is(err, syscall.EAGAIN) {
+		t.Fatalf("Expected an error wrapping ErrWouldBlock and EAGAIN, but got: %v", err)
+	}
+	if errors.Is(err, ErrDeviceGone) {
+		t.Fatalf("Expected a full buffer not to be classified as a removed device, but got: %v", err)
+	}
+}
+
+func TestEventHookSeesEmittedEventsInOrder(t *testing.T) {
+	var seen []inputEvent
+	hook := func(evType uint16, code uint16, value int32) {
+		seen = append(seen, inputEvent{Type: evType, Code: code, Value: value})
+	}
+	file, stop := recordEvents(t)
+	mouse, err := CreateMouseWriter(file, []byte("Test Mouse"), WithEventHook(hook), WithScanCodes())
+	if err != nil {
+		t.Fatalf("Failed to create the mouse. Last error was: %s\n", err)
+	}
+
+	err = mouse.Move(3, -4)
+	if err != nil {
+		t.Fatalf("Failed to move the mouse. Last error was: %s\n", err)
+	}
+	err = mouse.LeftPress()
+	if err != nil {
+		t.Fatalf("Failed to press the left button. Last error was: %s\n", err)
+	}
+
+	expected := []inputEvent{
+		{Type: evRel, Code: relX, Value: 3},
+		{Type: evSyn, Code: synReport},
+		{Type: evRel, Code: relY, Value: -4},
+		{Type: evSyn, Code: synReport},
+		{Type: evMsc, Code: mscScan, Value: mouseScanCodes[evMouseBtnLeft]},
+		{Type: evKey, Code: evMouseBtnLeft, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+	}
+	if !reflect.DeepEqual(seen, expected) {
+		t.Fatalf("Expected the hook to see %v, but got %v", expected, seen)
+	}
+	assertEvents(t, expected, stop())
+}
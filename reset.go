@@ -0,0 +1,96 @@
+package uinput
+
+import "sort"
+
+// A Resetter returns a device to a known state, e.g. in order to isolate test cases from each other. All devices
+// of this package implement Resetter: Reset releases all keys and buttons that are held down, and returns the
+// absolute axes of the device to their resting position, using a single report. Devices that neither have
+// buttons nor absolute axes, or that are at rest already, do not emit anything.
+type Resetter interface {
+	Reset() error
+}
+
+// reset writes a single report that releases all keys that are held down (including those of pending events),
+// followed by the given events, which return the absolute axes of the device to their resting position.
+func (rb *reportBuilder) reset(events ...inputEvent) error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	held := make(map[uint16]bool, len(rb.held))
+	for code := range rb.held {
+		held[code] = true
+	}
+	trackKeys(held, rb.events)
+
+	codes := make([]uint16, 0, len(held))
+	for code := range held {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+
+	releases := make([]inputEvent, 0, len(codes)+len(events))
+	for _, code := range codes {
+		releases = append(releases, inputEvent{Type: evKey, Code: code, Value: btnStateReleased})
+	}
+	events = append(releases, events...)
+	if len(events) == 0 {
+		return nil
+	}
+	return rb.sendLocked(events)
+}
+
+// trackKeys updates the given set of keys that are held down according to the given events.
+func trackKeys(held map[uint16]bool, events []inputEvent) {
+	for _, ev := range events {
+		if ev.Type != evKey {
+			continue
+		}
+		if ev.Value == btnStateReleased {
+			delete(held, ev.Code)
+		} else {
+			held[ev.Code] = true
+		}
+	}
+}
+
+// isHeld reports whether the given key has been pressed, but not released yet, not counting pending events.
+func (rb *reportBuilder) isHeld(code uint16) bool {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return rb.held[code]
+}
+
+// absValue returns the last value that has been reported for the given absolute axis, or zero if there is none.
+func (rb *reportBuilder) absValue(code uint16) int32 {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return rb.abs[code]
+}
+
+// axisCenter returns the midpoint of the given range, rounded towards zero, so that symmetric ranges like
+// -32768 to 32767 are centered at zero.
+func axisCenter(min int32, max int32) int32 {
+	return int32((int64(min) + int64(max)) / 2)
+}
+
+// axisRest returns the resting position of a generic axis: zero if it is part of the range, otherwise its midpoint.
+func axisRest(min int32, max int32) int32 {
+	if min <= 0 && 0 <= max {
+		return 0
+	}
+	return axisCenter(min, max)
+}
+
+// trackAbsLocked records the values of the absolute axes of the given events. The multitouch axes are left out,
+// since their values depend on the selected slot.
+func (rb *reportBuilder) trackAbsLocked(events []inputEvent) {
+	for _, ev := range events {
+		if ev.Type != evAbs || ev.Code >= absMtSlot {
+			continue
+		}
+		if rb.abs == nil {
+			rb.abs = make(map[uint16]int32)
+		}
+		rb.abs[ev.Code] = ev.Value
+	}
+}
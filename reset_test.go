@@ -0,0 +1,141 @@
+package uinput
+
+import "testing"
+
+var (
+	_ Resetter = (*vKeyboard)(nil)
+	_ Resetter = vMouse{}
+	_ Resetter = (*vTouchPad)(nil)
+	_ Resetter = vDial{}
+	_ Resetter = vGamepad{}
+	_ Resetter = vMultiTouch{}
+	_ Resetter = vScrollDevice{}
+	_ Resetter = vSpaceMouse{}
+	_ Resetter = vStylus{}
+	_ Resetter = vHybridPointer{}
+	_ Resetter = vButtonPad{}
+	_ Resetter = vSlider{}
+	_ Resetter = vRelativeDevice{}
+	_ Resetter = vTouchScreen{}
+	_ Resetter = vDevice{}
+	_ Resetter = noopKeyboard{}
+	_ Resetter = noopMouse{}
+	_ Resetter = noopTouchPad{}
+	_ Resetter = noopDial{}
+	_ Resetter = noopGamepad{}
+	_ Resetter = noopMultiTouch{}
+)
+
+func TestGamepadResetReleasesButtonsAndCentersAxes(t *testing.T) {
+	file, stop := recordEvents(t)
+	layout := gamepadPresets[PresetDualShock4]
+	axes := make(map[uint16]gamepadAxis, len(layout.axes))
+	for _, axis := range layout.axes {
+		axes[axis.code] = axis
+	}
+	dev := vGamepad{deviceBase: deviceBase{report: newReportBuilder(file)}, axes: axes}
+
+	err := dev.ButtonDown(ButtonSouth)
+	if err != nil {
+		t.Fatalf("Failed to press button. Last error was: %s\n", err)
+	}
+	err = dev.SendEvent(evAbs, absZ, 200)
+	if err != nil {
+		t.Fatalf("Failed to move trigger. Last error was: %s\n", err)
+	}
+	err = dev.LeftStickMove(1, -1)
+	if err != nil {
+		t.Fatalf("Failed to move stick. Last error was: %s\n", err)
+	}
+	err = dev.Reset()
+	if err != nil {
+		t.Fatalf("Failed to reset gamepad. Last error was: %s\n", err)
+	}
+
+	// the reset is a single report that follows the ones of the button, the trigger and the stick
+	events := stop()
+	if len(events) < 10 {
+		t.Fatalf("Expected at least 10 events, but got %d: %v", len(events), events)
+	}
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: ButtonSouth, Value: btnStateReleased},
+		{Type: evAbs, Code: absX, Value: 127},
+		{Type: evAbs, Code: absY, Value: 127},
+		{Type: evAbs, Code: absZ, Value: 0},
+		{Type: evAbs, Code: absRX, Value: 127},
+		{Type: evAbs, Code: absRY, Value: 127},
+		{Type: evAbs, Code: absRZ, Value: 0},
+		{Type: evAbs, Code: absHat0X, Value: 0},
+		{Type: evAbs, Code: absHat0Y, Value: 0},
+		{Type: evSyn, Code: synReport},
+	}, events[len(events)-10:])
+}
+
+func TestKeyboardResetReleasesOnlyHeldKeys(t *testing.T) {
+	file, stop := recordEvents(t)
+	vk := &vKeyboard{deviceBase: deviceBase{report: newReportBuilder(file)}, composeKey: KeyCompose}
+
+	for _, step := range []func() error{
+		func() error { return vk.KeyDown(KeyB) },
+		func() error { return vk.KeyDown(KeyA) },
+		func() error { return vk.KeyPress(KeyC) },
+		vk.Reset,
+		// nothing is held anymore, so the second reset does not emit anything
+		vk.Reset,
+	} {
+		err := step()
+		if err != nil {
+			t.Fatalf("Failed to issue key events. Last error was: %s\n", err)
+		}
+	}
+
+	events := stop()
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: KeyA, Value: btnStateReleased},
+		{Type: evKey, Code: KeyB, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, events[len(events)-3:])
+	if len(events) != 11 {
+		t.Fatalf("Expected 11 events, but got %d: %v", len(events), events)
+	}
+}
+
+func TestMultiTouchResetLiftsContacts(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := &vMultiTouch{deviceBase: deviceBase{report: newReportBuilder(file)}, tracking: newMultiTouchTracking(3)}
+	for i := int32(0); i < 3; i++ {
+		dev.contacts = append(dev.contacts, multiTouchContact{slot: i, multitouch: dev})
+	}
+
+	err := dev.contacts[2].TouchDownAt(10, 10)
+	if err != nil {
+		t.Fatalf("Failed to touch down. Last error was: %s\n", err)
+	}
+	err = dev.Reset()
+	if err != nil {
+		t.Fatalf("Failed to reset multitouch device. Last error was: %s\n", err)
+	}
+
+	events := stop()
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absMtSlot, Value: 2},
+		{Type: evAbs, Code: absMtTrackingId, Value: -1},
+		{Type: evSyn, Code: synReport},
+	}, events[len(events)-3:])
+	if id := dev.contacts[2].TrackingID(); id != -1 {
+		t.Fatalf("Expected the contact to be lifted, but it has tracking id %d", id)
+	}
+}
+
+func TestResetOfDeviceWithoutStateEmitsNothing(t *testing.T) {
+	w := &writeCounter{}
+	dev := vDial{deviceBase: deviceBase{report: newReportBuilder(w)}}
+
+	err := dev.Reset()
+	if err != nil {
+		t.Fatalf("Failed to reset dial. Last error was: %s\n", err)
+	}
+	if w.writes != 0 {
+		t.Fatalf("Expected no writes, but got %d", w.writes)
+	}
+}
@@ -0,0 +1,169 @@
+package uinput
+
+// keyScanCodes maps the key codes to the scan codes (HID usages of the keyboard page) that are reported by USB
+// keyboards, as derived from the hid_keyboard table of the kernel (drivers/hid/hid-input.c). Keys that are
+// reported by more than one usage are mapped to the first one. Keys without a usage are not included.
+var keyScanCodes = map[int]int32{
+	KeyEsc:              0x70029,
+	Key1:                0x7001e,
+	Key2:                0x7001f,
+	Key3:                0x70020,
+	Key4:                0x70021,
+	Key5:                0x70022,
+	Key6:                0x70023,
+	Key7:                0x70024,
+	Key8:                0x70025,
+	Key9:                0x70026,
+	Key0:                0x70027,
+	KeyMinus:            0x7002d,
+	KeyEqual:            0x7002e,
+	KeyBackspace:        0x7002a,
+	KeyTab:              0x7002b,
+	KeyQ:                0x70014,
+	KeyW:                0x7001a,
+	KeyE:                0x70008,
+	KeyR:                0x70015,
+	KeyT:                0x70017,
+	KeyY:                0x7001c,
+	KeyU:                0x70018,
+	KeyI:                0x7000c,
+	KeyO:                0x70012,
+	KeyP:                0x70013,
+	KeyLeftbrace:        0x7002f,
+	KeyRightbrace:       0x70030,
+	KeyEnter:            0x70028,
+	KeyLeftctrl:         0x700e0,
+	KeyA:                0x70004,
+	KeyS:                0x70016,
+	KeyD:                0x70007,
+	KeyF:                0x70009,
+	KeyG:                0x7000a,
+	KeyH:                0x7000b,
+	KeyJ:                0x7000d,
+	KeyK:                0x7000e,
+	KeyL:                0x7000f,
+	KeySemicolon:        0x70033,
+	KeyApostrophe:       0x70034,
+	KeyGrave:            0x70035,
+	KeyLeftshift:        0x700e1,
+	KeyBackslash:        0x70031,
+	KeyZ:                0x7001d,
+	KeyX:                0x7001b,
+	KeyC:                0x70006,
+	KeyV:                0x70019,
+	KeyB:                0x70005,
+	KeyN:                0x70011,
+	KeyM:                0x70010,
+	KeyComma:            0x70036,
+	KeyDot:              0x70037,
+	KeySlash:            0x70038,
+	KeyRightshift:       0x700e5,
+	KeyKpasterisk:       0x70055,
+	KeyLeftalt:          0x700e2,
+	KeySpace:            0x7002c,
+	KeyCapslock:         0x70039,
+	KeyF1:               0x7003a,
+	KeyF2:               0x7003b,
+	KeyF3:               0x7003c,
+	KeyF4:               0x7003d,
+	KeyF5:               0x7003e,
+	KeyF6:               0x7003f,
+	KeyF7:               0x70040,
+	KeyF8:               0x70041,
+	KeyF9:               0x70042,
+	KeyF10:              0x70043,
+	KeyNumlock:          0x70053,
+	KeyScrolllock:       0x70047,
+	KeyKp7:              0x7005f,
+	KeyKp8:              0x70060,
+	KeyKp9:              0x70061,
+	KeyKpminus:          0x70056,
+	KeyKp4:              0x7005c,
+	KeyKp5:              0x7005d,
+	KeyKp6:              0x7005e,
+	KeyKpplus:           0x70057,
+	KeyKp1:              0x70059,
+	KeyKp2:              0x7005a,
+	KeyKp3:              0x7005b,
+	KeyKp0:              0x70062,
+	KeyKpdot:            0x70063,
+	KeyZenkakuhankaku:   0x70094,
+	Key102Nd:            0x70064,
+	KeyF11:              0x70044,
+	KeyF12:              0x70045,
+	KeyRo:               0x70087,
+	KeyKatakana:         0x70092,
+	KeyHiragana:         0x70093,
+	KeyHenkan:           0x7008a,
+	KeyKatakanahiragana: 0x70088,
+	KeyMuhenkan:         0x7008b,
+	KeyKpjpcomma:        0x7008c,
+	KeyKpenter:          0x70058,
+	KeyRightctrl:        0x700e4,
+	KeyKpslash:          0x70054,
+	KeySysrq:            0x70046,
+	KeyRightalt:         0x700e6,
+	KeyHome:             0x7004a,
+	KeyUp:               0x70052,
+	KeyPageup:           0x7004b,
+	KeyLeft:             0x70050,
+	KeyRight:            0x7004f,
+	KeyEnd:              0x7004d,
+	KeyDown:             0x70051,
+	KeyPagedown:         0x7004e,
+	KeyInsert:           0x70049,
+	KeyDelete:           0x7004c,
+	KeyMute:             0x7007f,
+	KeyVolumedown:       0x70081,
+	KeyVolumeup:         0x70080,
+	KeyPower:            0x70066,
+	KeyKpequal:          0x70067,
+	KeyPause:            0x70048,
+	KeyKpcomma:          0x70085,
+	KeyHangeul:          0x70090,
+	KeyHanja:            0x70091,
+	KeyYen:              0x70089,
+	KeyLeftmeta:         0x700e3,
+	KeyRightmeta:        0x700e7,
+	KeyCompose:          0x70065,
+	KeyStop:             0x70078,
+	KeyAgain:            0x70079,
+	KeyProps:            0x70076,
+	KeyUndo:             0x7007a,
+	KeyFront:            0x70077,
+	KeyCopy:             0x7007c,
+	KeyOpen:             0x70074,
+	KeyPaste:            0x7007d,
+	KeyFind:             0x7007e,
+	KeyCut:              0x7007b,
+	KeyHelp:             0x70075,
+	KeyCalc:             0x700fb,
+	KeySleep:            0x700f8,
+	KeyWww:              0x700f0,
+	KeyCoffee:           0x700f9,
+	KeyBack:             0x700f1,
+	KeyForward:          0x700f2,
+	KeyEjectcd:          0x700ec,
+	KeyNextsong:         0x700eb,
+	KeyPlaypause:        0x700e8,
+	KeyPrevioussong:     0x700ea,
+	KeyStopcd:           0x700e9,
+	KeyRefresh:          0x700fa,
+	KeyEdit:             0x700f7,
+	KeyScrollup:         0x700f5,
+	KeyScrolldown:       0x700f6,
+	KeyKpleftparen:      0x700b6,
+	KeyKprightparen:     0x700b7,
+	KeyF13:              0x70068,
+	KeyF14:              0x70069,
+	KeyF15:              0x7006a,
+	KeyF16:              0x7006b,
+	KeyF17:              0x7006c,
+	KeyF18:              0x7006d,
+	KeyF19:              0x7006e,
+	KeyF20:              0x7006f,
+	KeyF21:              0x70070,
+	KeyF22:              0x70071,
+	KeyF23:              0x70072,
+	KeyF24:              0x70073,
+}
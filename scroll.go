@@ -0,0 +1,109 @@
+package uinput
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// A ScrollDevice is a device that only provides a vertical and a horizontal scroll wheel, without
+// any pointer movement or buttons. This can be used to build scroll-only peripherals, like they are
+// commonly used as accessibility hardware.
+type ScrollDevice interface {
+	// Scroll will simulate a vertical wheel movement. Positive values scroll up, negative values scroll down.
+	Scroll(delta int32) error
+
+	// HScroll will simulate a horizontal wheel movement. Positive values scroll right, negative values scroll left.
+	HScroll(delta int32) error
+
+	// FetchSyspath will return the syspath to the device file.
+	FetchSyspath() (string, error)
+
+	// FetchSyspathContext works like FetchSyspath, but retries until the syspath is available or the context is
+	// done. In the latter case, the last error is returned along with the error of the context.
+	FetchSyspathContext(ctx context.Context) (string, error)
+
+	// Reset is a no-op, since a scroll device only has relative wheels (see Resetter).
+	Reset() error
+
+	io.Closer
+}
+
+type vScrollDevice struct {
+	deviceBase
+}
+
+// CreateScrollDevice will create a new scroll device. Only the vertical and horizontal wheel will be registered.
+func CreateScrollDevice(path string, name []byte, opts ...Option) (ScrollDevice, error) {
+	err := validateDevicePath(path)
+	if err != nil {
+		return nil, err
+	}
+	err = validateUinputName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	options := newDeviceOptions(opts)
+	fd, err := createScrollDevice(path, name, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return vScrollDevice{
+		deviceBase: deviceBase{
+			name:       name,
+			deviceFile: fd,
+			report:     newReportBuilderWithOptions(fd, name, options.withoutReportInterval()),
+		},
+	}, nil
+}
+
+// Scroll will simulate a vertical wheel movement.
+func (vScroll vScrollDevice) Scroll(delta int32) error {
+	return sendRelEvent(vScroll.report, relWheel, delta)
+}
+
+// HScroll will simulate a horizontal wheel movement.
+func (vScroll vScrollDevice) HScroll(delta int32) error {
+	return sendRelEvent(vScroll.report, relHWheel, delta)
+}
+
+// Reset releases the keys that have been pressed using raw events (see EventSender), if any. Since a scroll device has
+// neither buttons nor absolute axes of its own, it emits nothing otherwise (see Resetter).
+func (vScroll vScrollDevice) Reset() error {
+	return vScroll.report.reset()
+}
+
+func createScrollDevice(path string, name []byte, options deviceOptions) (fd *os.File, err error) {
+	deviceFile, err := createDeviceFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not create scroll input device: %w", err)
+	}
+
+	err = registerDevice(deviceFile, uintptr(evRel))
+	if err != nil {
+		_ = deviceFile.Close()
+		return nil, fmt.Errorf("failed to register scroll input device: %w", err)
+	}
+
+	// register the wheels only, so that the device is not mistaken for a pointing device
+	for _, event := range []int{relWheel, relHWheel} {
+		err = ioctl(deviceFile, uiSetRelBit, uintptr(event))
+		if err != nil {
+			_ = deviceFile.Close()
+			return nil, fmt.Errorf("failed to register relative event %v: %w", event, err)
+		}
+	}
+
+	return createUsbDevice(deviceFile,
+		uinputUserDev{
+			Name: toUinputName(name),
+			ID: inputID{
+				Bustype: busUsb,
+				Vendor:  0x4711,
+				Product: 0x0818,
+				Version: 1}},
+		options)
+}
@@ -0,0 +1,109 @@
+package uinput
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScrollDeviceWheels(t *testing.T) {
+	dev, err := CreateScrollDevice("/dev/uinput", []byte("Test Scroll Device"))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual scroll device. Last error was: %s\n", err)
+	}
+
+	err = dev.Scroll(1)
+	if err != nil {
+		t.Fatalf("Failed to perform vertical scroll. Last error was: %s\n", err)
+	}
+
+	err = dev.HScroll(-1)
+	if err != nil {
+		t.Fatalf("Failed to perform horizontal scroll. Last error was: %s\n", err)
+	}
+
+	err = dev.Close()
+	if err != nil {
+		t.Fatalf("Failed to close device. Last error was: %s\n", err)
+	}
+}
+
+func TestScrollDeviceRegistersOnlyWheels(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	dev, err := CreateScrollDevice(path, []byte("Test Scroll Device"))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual scroll device. Last error was: %s\n", err)
+	}
+	defer dev.Close()
+
+	if evBits := registeredCodes(*calls, uiSetEvBit); !reflect.DeepEqual(evBits, []uintptr{evRel}) {
+		t.Fatalf("Expected only EV_REL to be registered, but got %v", evBits)
+	}
+	if relBits := registeredCodes(*calls, uiSetRelBit); !reflect.DeepEqual(relBits, []uintptr{relWheel, relHWheel}) {
+		t.Fatalf("Expected only REL_WHEEL and REL_HWHEEL to be registered, but got %v", relBits)
+	}
+}
+
+func TestScrollDeviceHasNoMovementMethods(t *testing.T) {
+	scrollType := reflect.TypeOf((*ScrollDevice)(nil)).Elem()
+	for i := 0; i < scrollType.NumMethod(); i++ {
+		if name := scrollType.Method(i).Name; strings.HasPrefix(name, "Move") {
+			t.Fatalf("Expected scroll device to provide no movement methods, but found %s", name)
+		}
+	}
+}
+
+func TestScrollDeviceEmitsWheelEvents(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vScrollDevice{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	err := dev.Scroll(-2)
+	if err != nil {
+		t.Fatalf("Failed to perform vertical scroll. Last error was: %s\n", err)
+	}
+	err = dev.HScroll(3)
+	if err != nil {
+		t.Fatalf("Failed to perform horizontal scroll. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evRel, Code: relWheel, Value: -2},
+		{Type: evSyn, Code: synReport},
+		{Type: evRel, Code: relHWheel, Value: 3},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestScrollDeviceCreationFailsOnEmptyPath(t *testing.T) {
+	expected := "device path must not be empty"
+	_, err := CreateScrollDevice("", []byte("ScrollDevice"))
+	if err.Error() != expected {
+		t.Fatalf("Expected: %s\nActual: %s", expected, err)
+	}
+}
+
+func TestScrollDeviceCreationFailsOnNonExistentPathName(t *testing.T) {
+	path := "/some/bogus/path"
+	_, err := CreateScrollDevice(path, []byte("ScrollDevice"))
+	if !os.IsNotExist(err) {
+		t.Fatalf("Expected: os.IsNotExist error\nActual: %s", err)
+	}
+}
+
+func TestScrollDeviceCreationFailsIfNameIsTooLong(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+
+	name := "adsfdsferqewoirueworiuejdsfjdfa;ljoewrjeworiewuoruew;rj;kdlfjoeai;jfewoaifjef;das"
+	expected := fmt.Sprintf("device name %s is too long (maximum of %d characters allowed)", name, uinputMaxNameSize)
+	_, err := CreateScrollDevice(path, []byte(name))
+	if err.Error() != expected {
+		t.Fatalf("Expected: %s\nActual: %s", expected, err)
+	}
+}
@@ -0,0 +1,48 @@
+package uinput
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// modifierSettleDelay is the time between pressing the modifiers and running the action in HoldModifiers, and
+// between the end of the action and releasing the modifiers.
+const modifierSettleDelay = 10 * time.Millisecond
+
+// HoldModifiers holds down the given modifier keys of the keyboard while the action is run, e.g. in order to
+// Shift+Click using a mouse:
+//
+//	err := uinput.HoldModifiers(keyboard, []int{uinput.KeyLeftshift}, mouse.LeftClick)
+//
+// The modifiers are pressed in the given order and released in reverse order once the action returns, even if
+// it fails. Since the keyboard and the other device are separate devices, their events are not guaranteed to be
+// processed in the order they were written. Therefore, HoldModifiers waits a short moment after pressing the
+// modifiers and before releasing them, so that the receiving environment observes the modifiers as being held
+// down during the whole action.
+func HoldModifiers(keyboard Keyboard, modifiers []int, action func() error) error {
+	var err error
+	pressed := 0
+	for _, modifier := range modifiers {
+		err = keyboard.KeyDown(modifier)
+		if err != nil {
+			err = fmt.Errorf("failed to press modifier %d: %w", modifier, err)
+			break
+		}
+		pressed++
+	}
+
+	if err == nil {
+		time.Sleep(modifierSettleDelay)
+		err = action()
+		time.Sleep(modifierSettleDelay)
+	}
+
+	for i := pressed - 1; i >= 0; i-- {
+		releaseErr := keyboard.KeyUp(modifiers[i])
+		if releaseErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to release modifier %d: %w", modifiers[i], releaseErr))
+		}
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package uinput
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHoldModifiersHoldsModifierDuringClick(t *testing.T) {
+	// both devices write to the same recorder, so that the order of their events can be verified
+	file, stop := recordEvents(t)
+	keyboard := &vKeyboard{deviceBase: deviceBase{report: newReportBuilder(file)}, composeKey: KeyCompose}
+	mouse := vMouse{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	err := HoldModifiers(keyboard, []int{KeyLeftctrl, KeyLeftshift}, mouse.LeftClick)
+	if err != nil {
+		t.Fatalf("Failed to click while holding modifiers. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: KeyLeftctrl, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: KeyLeftshift, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: evMouseBtnLeft, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: evMouseBtnLeft, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: KeyLeftshift, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: KeyLeftctrl, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestHoldModifiersReleasesModifierIfActionFails(t *testing.T) {
+	file, stop := recordEvents(t)
+	keyboard := &vKeyboard{deviceBase: deviceBase{report: newReportBuilder(file)}, composeKey: KeyCompose}
+	actionErr := errors.New("action failed")
+
+	err := HoldModifiers(keyboard, []int{KeyLeftshift}, func() error { return actionErr })
+	if !errors.Is(err, actionErr) {
+		t.Fatalf("Expected the error of the action to be returned, but got: %v", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: KeyLeftshift, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: KeyLeftshift, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
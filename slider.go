@@ -0,0 +1,107 @@
+package uinput
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// A Slider is a device with a single absolute axis (ABS_MISC), like a fader of an audio control surface.
+type Slider interface {
+	// SetValue will move the slider to the given value. Values outside of the range of the slider are clamped.
+	SetValue(value int32) error
+
+	// FetchSyspath will return the syspath to the device file.
+	FetchSyspath() (string, error)
+
+	// FetchSyspathContext works like FetchSyspath, but retries until the syspath is available or the context is
+	// done. In the latter case, the last error is returned along with the error of the context.
+	FetchSyspathContext(ctx context.Context) (string, error)
+
+	// Reset will move the slider to the minimum of its range (see Resetter).
+	Reset() error
+
+	io.Closer
+}
+
+type vSlider struct {
+	deviceBase
+	// the range of the axis
+	min, max int32
+}
+
+// CreateSlider will create a new slider with the given range, which is registered as the absolute axis ABS_MISC.
+func CreateSlider(path string, name []byte, min int32, max int32, opts ...Option) (Slider, error) {
+	err := validateDevicePath(path)
+	if err != nil {
+		return nil, err
+	}
+	err = validateUinputName(name)
+	if err != nil {
+		return nil, err
+	}
+	options := newDeviceOptions(opts)
+	fd, err := createSlider(path, name, min, max, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return vSlider{
+		deviceBase: deviceBase{name: name, deviceFile: fd, report: newReportBuilderWithOptions(fd, name, options)},
+		min:        min,
+		max:        max,
+	}, nil
+}
+
+// SetValue will emit the given value, clamped to the range of the slider, as a single report.
+func (vs vSlider) SetValue(value int32) error {
+	value, _ = clampAxis(value, vs.min, vs.max, AxisClamp)
+	return sendAbsAxisEvent(vs.report, absMisc, value)
+}
+
+// Reset moves the slider to the minimum of its range (see Resetter).
+func (vs vSlider) Reset() error {
+	return vs.report.reset(inputEvent{Type: evAbs, Code: absMisc, Value: vs.min})
+}
+
+func createSlider(path string, name []byte, min int32, max int32, options deviceOptions) (fd *os.File, err error) {
+	err = validateAxisRange("misc", min, max)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceFile, err := createDeviceFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not create slider input device: %w", err)
+	}
+
+	err = registerDevice(deviceFile, uintptr(evAbs))
+	if err != nil {
+		deviceFile.Close()
+		return nil, fmt.Errorf("failed to register slider input device: %w", err)
+	}
+	err = ioctl(deviceFile, uiSetAbsBit, uintptr(absMisc))
+	if err != nil {
+		deviceFile.Close()
+		return nil, fmt.Errorf("failed to register absolute axis event %v: %w", absMisc, err)
+	}
+
+	var absMin [absSize]int32
+	absMin[absMisc] = min
+
+	var absMax [absSize]int32
+	absMax[absMisc] = max
+
+	return createUsbDevice(deviceFile,
+		uinputUserDev{
+			Name: toUinputName(name),
+			ID: inputID{
+				Bustype: busUsb,
+				Vendor:  0x4711,
+				Product: 0x081f,
+				Version: 1},
+			Absmin: absMin,
+			Absmax: absMax},
+		options)
+}
@@ -0,0 +1,81 @@
+package uinput
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSliderSetValue(t *testing.T) {
+	slider, err := CreateSlider("/dev/uinput", []byte("Test Slider"), 0, 127)
+	if err != nil {
+		t.Fatalf("Failed to create the virtual slider. Last error was: %s\n", err)
+	}
+
+	err = slider.SetValue(64)
+	if err != nil {
+		t.Fatalf("Failed to set slider value. Last error was: %s\n", err)
+	}
+
+	err = slider.Close()
+	if err != nil {
+		t.Fatalf("Failed to close device. Last error was: %s\n", err)
+	}
+}
+
+func TestSliderSetValueIsClampedAndEmittedOnMiscAxis(t *testing.T) {
+	file, stop := recordEvents(t)
+	slider := vSlider{deviceBase: deviceBase{report: newReportBuilder(file)}, min: 0, max: 127}
+
+	for _, value := range []int32{64, 200, -5} {
+		err := slider.SetValue(value)
+		if err != nil {
+			t.Fatalf("Failed to set slider value. Last error was: %s\n", err)
+		}
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absMisc, Value: 64},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absMisc, Value: 127},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absMisc, Value: 0},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestSliderRegistersMiscAxisWithRange(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	slider, err := CreateSlider(path, []byte("Test Slider"), -10, 10)
+	if err != nil {
+		t.Fatalf("Failed to create the virtual slider. Last error was: %s\n", err)
+	}
+	defer slider.Close()
+
+	absBits := registeredCodes(*calls, uiSetAbsBit)
+	if !reflect.DeepEqual(absBits, []uintptr{absMisc}) {
+		t.Fatalf("Expected ABS_MISC to be registered, but got %v", absBits)
+	}
+	dev := readUserDev(t, path)
+	if dev.Absmin[absMisc] != -10 || dev.Absmax[absMisc] != 10 {
+		t.Fatalf("Expected the range [-10, 10], but got [%d, %d]", dev.Absmin[absMisc], dev.Absmax[absMisc])
+	}
+}
+
+func TestSliderCreationFailsOnInvalidRange(t *testing.T) {
+	_, err := CreateSlider("/dev/uinput", []byte("Test Slider"), 10, 10)
+	if err == nil {
+		t.Fatalf("Expected creation to fail for an empty range, but no error was returned.")
+	}
+}
+
+func TestSliderCreationFailsOnEmptyPath(t *testing.T) {
+	expected := "device path must not be empty"
+	_, err := CreateSlider("", []byte("SliderDevice"), 0, 127)
+	if err.Error() != expected {
+		t.Fatalf("Expected: %s\nActual: %s", expected, err)
+	}
+}
@@ -0,0 +1,108 @@
+package uinput
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// A SpaceMouse is a 3D input device with six degrees of freedom, as it is used for CAD and 3D modelling
+// applications. It reports translations along the x, y and z axes as well as rotations around them.
+// All values are relative and passed to the device as they are.
+type SpaceMouse interface {
+	// Move6DOF will simulate a movement of the device. x, y and z are the translations along the respective
+	// axes, rx, ry and rz the rotations around them. All six values are sent in a single report.
+	Move6DOF(x, y, z, rx, ry, rz int32) error
+
+	// FetchSyspath will return the syspath to the device file.
+	FetchSyspath() (string, error)
+
+	// FetchSyspathContext works like FetchSyspath, but retries until the syspath is available or the context is
+	// done. In the latter case, the last error is returned along with the error of the context.
+	FetchSyspathContext(ctx context.Context) (string, error)
+
+	// Reset is a no-op, since all axes of a space mouse are relative (see Resetter).
+	Reset() error
+
+	io.Closer
+}
+
+type vSpaceMouse struct {
+	deviceBase
+}
+
+// CreateSpaceMouse will create a new space mouse input device. The device registers the relative axes
+// REL_X, REL_Y, REL_Z, REL_RX, REL_RY and REL_RZ.
+func CreateSpaceMouse(path string, name []byte, opts ...Option) (SpaceMouse, error) {
+	err := validateDevicePath(path)
+	if err != nil {
+		return nil, err
+	}
+	err = validateUinputName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	options := newDeviceOptions(opts)
+	fd, err := createSpaceMouse(path, name, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return vSpaceMouse{
+		deviceBase: deviceBase{
+			name:       name,
+			deviceFile: fd,
+			report:     newReportBuilderWithOptions(fd, name, options.withoutReportInterval()),
+		},
+	}, nil
+}
+
+// Move6DOF will simulate a movement of the device along and around all three axes.
+func (vSpace vSpaceMouse) Move6DOF(x, y, z, rx, ry, rz int32) error {
+	return vSpace.report.send(
+		inputEvent{Type: evRel, Code: relX, Value: x},
+		inputEvent{Type: evRel, Code: relY, Value: y},
+		inputEvent{Type: evRel, Code: relZ, Value: z},
+		inputEvent{Type: evRel, Code: relRX, Value: rx},
+		inputEvent{Type: evRel, Code: relRY, Value: ry},
+		inputEvent{Type: evRel, Code: relRZ, Value: rz})
+}
+
+// Reset releases the keys that have been pressed using raw events (see EventSender), if any. Since a space mouse has
+// neither buttons nor absolute axes of its own, it emits nothing otherwise (see Resetter).
+func (vSpace vSpaceMouse) Reset() error {
+	return vSpace.report.reset()
+}
+
+func createSpaceMouse(path string, name []byte, options deviceOptions) (fd *os.File, err error) {
+	deviceFile, err := createDeviceFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not create space mouse input device: %w", err)
+	}
+
+	err = registerDevice(deviceFile, uintptr(evRel))
+	if err != nil {
+		_ = deviceFile.Close()
+		return nil, fmt.Errorf("failed to register space mouse input device: %w", err)
+	}
+
+	for _, event := range []int{relX, relY, relZ, relRX, relRY, relRZ} {
+		err = ioctl(deviceFile, uiSetRelBit, uintptr(event))
+		if err != nil {
+			_ = deviceFile.Close()
+			return nil, fmt.Errorf("failed to register relative event %v: %w", event, err)
+		}
+	}
+
+	return createUsbDevice(deviceFile,
+		uinputUserDev{
+			Name: toUinputName(name),
+			ID: inputID{
+				Bustype: busUsb,
+				Vendor:  0x4711,
+				Product: 0x0819,
+				Version: 1}},
+		options)
+}
@@ -0,0 +1,81 @@
+package uinput
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestSpaceMouseMove6DOF(t *testing.T) {
+	dev, err := CreateSpaceMouse("/dev/uinput", []byte("Test Space Mouse"))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual space mouse. Last error was: %s\n", err)
+	}
+
+	err = dev.Move6DOF(1, 2, 3, 4, 5, 6)
+	if err != nil {
+		t.Fatalf("Failed to perform movement. Last error was: %s\n", err)
+	}
+
+	err = dev.Close()
+	if err != nil {
+		t.Fatalf("Failed to close device. Last error was: %s\n", err)
+	}
+}
+
+func TestSpaceMouseRegistersAllSixAxes(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	dev, err := CreateSpaceMouse(path, []byte("Test Space Mouse"))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual space mouse. Last error was: %s\n", err)
+	}
+	defer dev.Close()
+
+	if evBits := registeredCodes(*calls, uiSetEvBit); !reflect.DeepEqual(evBits, []uintptr{evRel}) {
+		t.Fatalf("Expected only EV_REL to be registered, but got %v", evBits)
+	}
+	expected := []uintptr{relX, relY, relZ, relRX, relRY, relRZ}
+	if relBits := registeredCodes(*calls, uiSetRelBit); !reflect.DeepEqual(relBits, expected) {
+		t.Fatalf("Expected relative axes %v to be registered, but got %v", expected, relBits)
+	}
+}
+
+func TestSpaceMouseEmitsAllAxesInOneReport(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vSpaceMouse{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	err := dev.Move6DOF(1, -2, 3, -4, 5, -6)
+	if err != nil {
+		t.Fatalf("Failed to perform movement. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evRel, Code: relX, Value: 1},
+		{Type: evRel, Code: relY, Value: -2},
+		{Type: evRel, Code: relZ, Value: 3},
+		{Type: evRel, Code: relRX, Value: -4},
+		{Type: evRel, Code: relRY, Value: 5},
+		{Type: evRel, Code: relRZ, Value: -6},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestSpaceMouseCreationFailsOnEmptyPath(t *testing.T) {
+	expected := "device path must not be empty"
+	_, err := CreateSpaceMouse("", []byte("SpaceMouse"))
+	if err.Error() != expected {
+		t.Fatalf("Expected: %s\nActual: %s", expected, err)
+	}
+}
+
+func TestSpaceMouseCreationFailsOnNonExistentPathName(t *testing.T) {
+	path := "/some/bogus/path"
+	_, err := CreateSpaceMouse(path, []byte("SpaceMouse"))
+	if !os.IsNotExist(err) {
+		t.Fatalf("Expected: os.IsNotExist error\nActual: %s", err)
+	}
+}
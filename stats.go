@@ -0,0 +1,26 @@
+package uinput
+
+// Stats holds the counters of the events that a device has written to its device file. They help to diagnose
+// dropped or throttled input under load.
+type Stats struct {
+	// EventsWritten is the number of events that have been written, including the SYN_REPORT events.
+	EventsWritten uint64
+	// Syncs is the number of reports that have been completed by a SYN_REPORT.
+	Syncs uint64
+	// WriteRetries is the number of writes that have been repeated because the buffer of the device was full
+	// (see WithNonBlocking).
+	WriteRetries uint64
+}
+
+// A StatsReporter reports the statistics of a device. All devices of this package (except for the noop devices)
+// implement StatsReporter. Events that are still pending or buffered (see WithFlushThreshold) are not counted.
+type StatsReporter interface {
+	Stats() Stats
+}
+
+// Stats returns the counters of the events that have been written so far.
+func (rb *reportBuilder) Stats() Stats {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return rb.stats
+}
@@ -0,0 +1,90 @@
+package uinput
+
+import (
+	"testing"
+	"time"
+)
+
+var (
+	_ StatsReporter = (*vKeyboard)(nil)
+	_ StatsReporter = vMouse{}
+	_ StatsReporter = (*vTouchPad)(nil)
+	_ StatsReporter = vDial{}
+	_ StatsReporter = vGamepad{}
+	_ StatsReporter = vMultiTouch{}
+	_ StatsReporter = vScrollDevice{}
+	_ StatsReporter = vSpaceMouse{}
+	_ StatsReporter = vStylus{}
+	_ StatsReporter = vHybridPointer{}
+	_ StatsReporter = vTouchScreen{}
+	_ StatsReporter = vButtonPad{}
+	_ StatsReporter = vSlider{}
+	_ StatsReporter = vRelativeDevice{}
+	_ StatsReporter = vDevice{}
+)
+
+func TestStatsCountWrittenEventsAndSyncs(t *testing.T) {
+	w := &writeCounter{}
+	mouse, err := CreateMouseWriter(w, []byte("Test Mouse"))
+	if err != nil {
+		t.Fatalf("Failed to create the mouse. Last error was: %s\n", err)
+	}
+
+	// Move writes one report per axis
+	err = mouse.Move(3, 4)
+	if err != nil {
+		t.Fatalf("Failed to move mouse. Last error was: %s\n", err)
+	}
+	err = mouse.MoveRight(1)
+	if err != nil {
+		t.Fatalf("Failed to move mouse. Last error was: %s\n", err)
+	}
+
+	expected := Stats{EventsWritten: 6, Syncs: 3}
+	if stats := mouse.(StatsReporter).Stats(); stats != expected {
+		t.Fatalf("Expected: %+v\nActual: %+v", expected, stats)
+	}
+}
+
+func TestStatsDoNotCountBufferedEvents(t *testing.T) {
+	w := &writeCounter{}
+	mouse, err := CreateMouseWriter(w, []byte("Test Mouse"), WithFlushThreshold(10))
+	if err != nil {
+		t.Fatalf("Failed to create the mouse. Last error was: %s\n", err)
+	}
+
+	err = mouse.MoveRight(1)
+	if err != nil {
+		t.Fatalf("Failed to move mouse. Last error was: %s\n", err)
+	}
+	if stats := mouse.(StatsReporter).Stats(); stats != (Stats{}) {
+		t.Fatalf("Expected buffered events not to be counted, but got %+v", stats)
+	}
+
+	err = mouse.(Flusher).Flush()
+	if err != nil {
+		t.Fatalf("Failed to flush mouse. Last error was: %s\n", err)
+	}
+	expected := Stats{EventsWritten: 2, Syncs: 1}
+	if stats := mouse.(StatsReporter).Stats(); stats != expected {
+		t.Fatalf("Expected: %+v\nActual: %+v", expected, stats)
+	}
+}
+
+func TestStatsCountWriteRetries(t *testing.T) {
+	w := &blockingWriter{failures: 2}
+	mouse, err := CreateMouseWriter(w, []byte("Test Mouse"), WithNonBlocking(3, time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create the mouse. Last error was: %s\n", err)
+	}
+
+	err = mouse.MoveRight(1)
+	if err != nil {
+		t.Fatalf("Expected the write to succeed after retrying, but got: %v", err)
+	}
+
+	expected := Stats{EventsWritten: 2, Syncs: 1, WriteRetries: 2}
+	if stats := mouse.(StatsReporter).Stats(); stats != expected {
+		t.Fatalf("Expected: %+v\nActual: %+v", expected, stats)
+	}
+}
@@ -0,0 +1,254 @@
+package uinput
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// stylusMaxPressure is the maximum pressure of a stylus, which corresponds to 12 bits like on common graphic tablets.
+const stylusMaxPressure = 4095
+
+// A Stylus is a pen input device with absolute coordinates, like a graphic tablet. Unlike the TouchPad, it
+// distinguishes between a pen that hovers above the surface (in proximity) and a pen that touches it. Besides the
+// tip of the pen, the stylus has an eraser end, but only one of them can be in proximity at a time.
+type Stylus interface {
+	// HoverTo will move the pen to the specified position while it hovers above the surface, without touching it.
+	HoverTo(x int32, y int32) error
+
+	// TouchAt will move the pen to the specified position while it touches the surface with the given pressure
+	// (0 to 4095).
+	TouchAt(x int32, y int32, pressure int32) error
+
+	// Lift will remove the pen (or the eraser, if it is in proximity) from the surface and out of proximity.
+	Lift() error
+
+	// EraserDown will move the eraser end of the pen to the specified position while it touches the surface with
+	// the given pressure (0 to 4095). If the tip of the pen is in proximity, it is lifted first.
+	EraserDown(x int32, y int32, pressure int32) error
+
+	// EraserUp will remove the eraser from the surface and out of proximity.
+	EraserUp() error
+
+	// SetPressureNorm will set the pressure of the pen to the given fraction (0.0 to 1.0) of the pressure range,
+	// without moving the pen. Values outside of this range are clamped.
+	SetPressureNorm(f float64) error
+
+	// FetchSyspath will return the syspath to the device file.
+	FetchSyspath() (string, error)
+
+	// FetchSyspathContext works like FetchSyspath, but retries until the syspath is available or the context is
+	// done. In the latter case, the last error is returned along with the error of the context.
+	FetchSyspathContext(ctx context.Context) (string, error)
+
+	// Reset will lift the stylus, release its buttons and move it to the center of the surface (see Resetter).
+	Reset() error
+
+	io.Closer
+}
+
+type vStylus struct {
+	deviceBase
+	// the range of the axes
+	minX, maxX, minY, maxY int32
+	axisPolicy             AxisPolicy
+	absAxisOrder           AbsAxisOrder
+}
+
+// CreateStylus will create a new stylus device. Note that you will need to define the x and y-axis boundaries
+// (min and max) of the surface.
+func CreateStylus(path string, name []byte, minX int32, maxX int32, minY int32, maxY int32, opts ...Option) (Stylus, error) {
+	err := validateDevicePath(path)
+	if err != nil {
+		return nil, err
+	}
+	err = validateUinputName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	options := newDeviceOptions(opts)
+	fd, err := createStylus(path, name, minX, maxX, minY, maxY, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return vStylus{
+		deviceBase: deviceBase{
+			name:       name,
+			deviceFile: fd,
+			report:     newReportBuilderWithOptions(fd, name, options.withoutReportInterval()),
+		},
+		minX:         minX,
+		maxX:         maxX,
+		minY:         minY,
+		maxY:         maxY,
+		axisPolicy:   options.axisPolicy,
+		absAxisOrder: options.absAxisOrder,
+	}, nil
+}
+
+// HoverTo will report the pen tool in proximity with BTN_TOUCH released, so that consumers move the cursor
+// without treating the movement as contact.
+func (vStyl vStylus) HoverTo(x int32, y int32) error {
+	return vStyl.sendToolEvent(evBtnToolPen, x, y, 0, btnStateReleased)
+}
+
+// TouchAt will report the pen tool touching the surface with the given pressure.
+func (vStyl vStylus) TouchAt(x int32, y int32, pressure int32) error {
+	if pressure < 0 || pressure > stylusMaxPressure {
+		return fmt.Errorf("pressure %d is out of range. Expected a value between 0 and %d", pressure, stylusMaxPressure)
+	}
+	return vStyl.sendToolEvent(evBtnToolPen, x, y, pressure, btnStatePressed)
+}
+
+// Lift will release BTN_TOUCH as well as BTN_TOOL_PEN, or BTN_TOOL_RUBBER if the eraser is in proximity.
+func (vStyl vStylus) Lift() error {
+	if vStyl.report.isHeld(evBtnToolRubber) {
+		return vStyl.liftTool(evBtnToolRubber)
+	}
+	return vStyl.liftTool(evBtnToolPen)
+}
+
+// EraserDown will report the eraser tool (BTN_TOOL_RUBBER) touching the surface with the given pressure.
+func (vStyl vStylus) EraserDown(x int32, y int32, pressure int32) error {
+	if pressure < 0 || pressure > stylusMaxPressure {
+		return fmt.Errorf("pressure %d is out of range. Expected a value between 0 and %d", pressure, stylusMaxPressure)
+	}
+	return vStyl.sendToolEvent(evBtnToolRubber, x, y, pressure, btnStatePressed)
+}
+
+// EraserUp will release BTN_TOUCH as well as BTN_TOOL_RUBBER.
+func (vStyl vStylus) EraserUp() error {
+	return vStyl.liftTool(evBtnToolRubber)
+}
+
+// otherTool returns the tool at the opposite end of the pen.
+func otherTool(tool uint16) uint16 {
+	if tool == evBtnToolPen {
+		return evBtnToolRubber
+	}
+	return evBtnToolPen
+}
+
+func (vStyl vStylus) liftTool(tool uint16) error {
+	err := vStyl.report.send(
+		inputEvent{Type: evAbs, Code: absPressure, Value: 0},
+		inputEvent{Type: evKey, Code: evBtnTouch, Value: btnStateReleased},
+		inputEvent{Type: evKey, Code: tool, Value: btnStateReleased})
+	if err != nil {
+		return fmt.Errorf("failed to write pen event to device file: %w", err)
+	}
+	return nil
+}
+
+// SetPressureNorm will report the given fraction of the pressure range as a single ABS_PRESSURE event.
+func (vStyl vStylus) SetPressureNorm(f float64) error {
+	return sendAbsAxisEvent(vStyl.report, absPressure, scaleFraction(f, 0, stylusMaxPressure))
+}
+
+// sendToolEvent reports the given tool in proximity. Since consumers expect only one tool to be in proximity at a
+// time, the other tool is lifted in a separate report beforehand if necessary.
+func (vStyl vStylus) sendToolEvent(tool uint16, x int32, y int32, pressure int32, touchState int) error {
+	x, err := clampAxis(x, vStyl.minX, vStyl.maxX, vStyl.axisPolicy)
+	if err != nil {
+		return fmt.Errorf("failed to move along the x-axis: %w", err)
+	}
+	y, err = clampAxis(y, vStyl.minY, vStyl.maxY, vStyl.axisPolicy)
+	if err != nil {
+		return fmt.Errorf("failed to move along the y-axis: %w", err)
+	}
+
+	if other := otherTool(tool); vStyl.report.isHeld(other) {
+		err = vStyl.liftTool(other)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = vStyl.report.send(orderAbsEvents([]inputEvent{
+		{Type: evAbs, Code: absX, Value: x},
+		{Type: evAbs, Code: absY, Value: y},
+		{Type: evAbs, Code: absPressure, Value: pressure},
+		{Type: evKey, Code: tool, Value: btnStatePressed},
+		{Type: evKey, Code: evBtnTouch, Value: int32(touchState)},
+	}, vStyl.absAxisOrder)...)
+	if err != nil {
+		return fmt.Errorf("failed to write pen event to device file: %w", err)
+	}
+	return nil
+}
+
+// Reset lifts the stylus, releases its buttons and moves it to the center of the surface (see Resetter).
+func (vStyl vStylus) Reset() error {
+	x, y := axisCenter(vStyl.minX, vStyl.maxX), axisCenter(vStyl.minY, vStyl.maxY)
+	events := append(orderAbsEvents(absEvents(x, y), vStyl.absAxisOrder),
+		inputEvent{Type: evAbs, Code: absPressure, Value: 0})
+	return vStyl.report.reset(events...)
+}
+
+func createStylus(path string, name []byte, minX int32, maxX int32, minY int32, maxY int32, options deviceOptions) (fd *os.File, err error) {
+	err = validateAxisRange("x", minX, maxX)
+	if err != nil {
+		return nil, err
+	}
+	err = validateAxisRange("y", minY, maxY)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceFile, err := createDeviceFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not create stylus input device: %w", err)
+	}
+
+	err = registerDevice(deviceFile, uintptr(evKey))
+	if err != nil {
+		_ = deviceFile.Close()
+		return nil, fmt.Errorf("failed to register key device: %w", err)
+	}
+
+	for _, event := range []int{evBtnToolPen, evBtnToolRubber, evBtnTouch} {
+		err = ioctl(deviceFile, uiSetKeyBit, uintptr(event))
+		if err != nil {
+			_ = deviceFile.Close()
+			return nil, fmt.Errorf("failed to register button event %v: %w", event, err)
+		}
+	}
+
+	err = registerDevice(deviceFile, uintptr(evAbs))
+	if err != nil {
+		_ = deviceFile.Close()
+		return nil, fmt.Errorf("failed to register absolute axis input device: %w", err)
+	}
+
+	for _, event := range []int{absX, absY, absPressure} {
+		err = ioctl(deviceFile, uiSetAbsBit, uintptr(event))
+		if err != nil {
+			_ = deviceFile.Close()
+			return nil, fmt.Errorf("failed to register absolute axis event %v: %w", event, err)
+		}
+	}
+
+	var absMin [absSize]int32
+	absMin[absX] = minX
+	absMin[absY] = minY
+
+	var absMax [absSize]int32
+	absMax[absX] = maxX
+	absMax[absY] = maxY
+	absMax[absPressure] = stylusMaxPressure
+
+	return createUsbDevice(deviceFile,
+		uinputUserDev{
+			Name: toUinputName(name),
+			ID: inputID{
+				Bustype: busUsb,
+				Vendor:  0x4711,
+				Product: 0x081a,
+				Version: 1},
+			Absmin: absMin,
+			Absmax: absMax},
+		options)
+}
@@ -0,0 +1,272 @@
+package uinput
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestStylusHoverAndTouch(t *testing.T) {
+	dev, err := CreateStylus("/dev/uinput", []byte("Test Stylus"), 0, 1024, 0, 768)
+	if err != nil {
+		t.Fatalf("Failed to create the virtual stylus. Last error was: %s\n", err)
+	}
+
+	err = dev.HoverTo(100, 100)
+	if err != nil {
+		t.Fatalf("Failed to hover. Last error was: %s\n", err)
+	}
+	err = dev.TouchAt(100, 100, 2000)
+	if err != nil {
+		t.Fatalf("Failed to touch. Last error was: %s\n", err)
+	}
+	err = dev.Lift()
+	if err != nil {
+		t.Fatalf("Failed to lift. Last error was: %s\n", err)
+	}
+
+	err = dev.Close()
+	if err != nil {
+		t.Fatalf("Failed to close device. Last error was: %s\n", err)
+	}
+}
+
+func TestStylusRegistersPenTool(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	dev, err := CreateStylus(path, []byte("Test Stylus"), 0, 1024, 0, 768)
+	if err != nil {
+		t.Fatalf("Failed to create the virtual stylus. Last error was: %s\n", err)
+	}
+	defer dev.Close()
+
+	if keyBits := registeredCodes(*calls, uiSetKeyBit); !reflect.DeepEqual(keyBits, []uintptr{evBtnToolPen, evBtnToolRubber, evBtnTouch}) {
+		t.Fatalf("Expected BTN_TOOL_PEN, BTN_TOOL_RUBBER and BTN_TOUCH to be registered, but got %v", keyBits)
+	}
+	if absBits := registeredCodes(*calls, uiSetAbsBit); !reflect.DeepEqual(absBits, []uintptr{absX, absY, absPressure}) {
+		t.Fatalf("Expected ABS_X, ABS_Y and ABS_PRESSURE to be registered, but got %v", absBits)
+	}
+}
+
+func TestStylusHoverKeepsTouchReleased(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vStylus{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	for _, x := range []int32{10, 20} {
+		err := dev.HoverTo(x, 30)
+		if err != nil {
+			t.Fatalf("Failed to hover. Last error was: %s\n", err)
+		}
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absX, Value: 10},
+		{Type: evAbs, Code: absY, Value: 30},
+		{Type: evAbs, Code: absPressure, Value: 0},
+		{Type: evKey, Code: evBtnToolPen, Value: btnStatePressed},
+		{Type: evKey, Code: evBtnTouch, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absX, Value: 20},
+		{Type: evAbs, Code: absY, Value: 30},
+		{Type: evAbs, Code: absPressure, Value: 0},
+		{Type: evKey, Code: evBtnToolPen, Value: btnStatePressed},
+		{Type: evKey, Code: evBtnTouch, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestStylusTouchAndLift(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vStylus{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	err := dev.TouchAt(10, 20, 1000)
+	if err != nil {
+		t.Fatalf("Failed to touch. Last error was: %s\n", err)
+	}
+	err = dev.Lift()
+	if err != nil {
+		t.Fatalf("Failed to lift. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absX, Value: 10},
+		{Type: evAbs, Code: absY, Value: 20},
+		{Type: evAbs, Code: absPressure, Value: 1000},
+		{Type: evKey, Code: evBtnToolPen, Value: btnStatePressed},
+		{Type: evKey, Code: evBtnTouch, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absPressure, Value: 0},
+		{Type: evKey, Code: evBtnTouch, Value: btnStateReleased},
+		{Type: evKey, Code: evBtnToolPen, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestStylusPenAndEraserAreMutuallyExclusive(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vStylus{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	err := dev.TouchAt(10, 20, 1000)
+	if err != nil {
+		t.Fatalf("Failed to touch. Last error was: %s\n", err)
+	}
+	err = dev.EraserDown(30, 40, 2000)
+	if err != nil {
+		t.Fatalf("Failed to put the eraser down. Last error was: %s\n", err)
+	}
+	err = dev.HoverTo(50, 60)
+	if err != nil {
+		t.Fatalf("Failed to hover. Last error was: %s\n", err)
+	}
+	err = dev.Lift()
+	if err != nil {
+		t.Fatalf("Failed to lift. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absX, Value: 10},
+		{Type: evAbs, Code: absY, Value: 20},
+		{Type: evAbs, Code: absPressure, Value: 1000},
+		{Type: evKey, Code: evBtnToolPen, Value: btnStatePressed},
+		{Type: evKey, Code: evBtnTouch, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		// the pen leaves proximity before the eraser enters it
+		{Type: evAbs, Code: absPressure, Value: 0},
+		{Type: evKey, Code: evBtnTouch, Value: btnStateReleased},
+		{Type: evKey, Code: evBtnToolPen, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absX, Value: 30},
+		{Type: evAbs, Code: absY, Value: 40},
+		{Type: evAbs, Code: absPressure, Value: 2000},
+		{Type: evKey, Code: evBtnToolRubber, Value: btnStatePressed},
+		{Type: evKey, Code: evBtnTouch, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		// and vice versa
+		{Type: evAbs, Code: absPressure, Value: 0},
+		{Type: evKey, Code: evBtnTouch, Value: btnStateReleased},
+		{Type: evKey, Code: evBtnToolRubber, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absX, Value: 50},
+		{Type: evAbs, Code: absY, Value: 60},
+		{Type: evAbs, Code: absPressure, Value: 0},
+		{Type: evKey, Code: evBtnToolPen, Value: btnStatePressed},
+		{Type: evKey, Code: evBtnTouch, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absPressure, Value: 0},
+		{Type: evKey, Code: evBtnTouch, Value: btnStateReleased},
+		{Type: evKey, Code: evBtnToolPen, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestStylusEraserUpLiftsEraser(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vStylus{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	err := dev.EraserDown(10, 20, 1000)
+	if err != nil {
+		t.Fatalf("Failed to put the eraser down. Last error was: %s\n", err)
+	}
+	err = dev.EraserUp()
+	if err != nil {
+		t.Fatalf("Failed to lift the eraser. Last error was: %s\n", err)
+	}
+
+	events := stop()
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absPressure, Value: 0},
+		{Type: evKey, Code: evBtnTouch, Value: btnStateReleased},
+		{Type: evKey, Code: evBtnToolRubber, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, events[6:])
+}
+
+func TestStylusTouchFailsForPressureOutOfRange(t *testing.T) {
+	dev := vStylus{}
+
+	err := dev.TouchAt(0, 0, stylusMaxPressure+1)
+	if err == nil {
+		t.Fatalf("Expected touch with pressure out of range to fail, but got no error.")
+	}
+}
+
+func TestStylusSetPressureNormMapsToPressureRange(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vStylus{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	// 0.5 maps to the midpoint of 0..4095, values outside of [0, 1] are clamped
+	for _, f := range []float64{0.5, 1.5, -1} {
+		err := dev.SetPressureNorm(f)
+		if err != nil {
+			t.Fatalf("Failed to set pressure. Last error was: %s\n", err)
+		}
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absPressure, Value: 2048},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absPressure, Value: stylusMaxPressure},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absPressure, Value: 0},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestStylusCreationFailsOnNonExistentPathName(t *testing.T) {
+	path := "/some/bogus/path"
+	_, err := CreateStylus(path, []byte("Stylus"), 0, 1024, 0, 768)
+	if !os.IsNotExist(err) {
+		t.Fatalf("Expected: os.IsNotExist error\nActual: %s", err)
+	}
+}
+
+func TestStylusHonorsAbsAxisOrder(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vStylus{deviceBase: deviceBase{report: newReportBuilder(file)}, maxX: 1024, maxY: 768, absAxisOrder: AbsAxisOrderYX}
+
+	err := dev.TouchAt(10, 20, 1000)
+	if err != nil {
+		t.Fatalf("Failed to touch. Last error was: %s\n", err)
+	}
+	err = dev.Reset()
+	if err != nil {
+		t.Fatalf("Failed to reset. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absY, Value: 20},
+		{Type: evAbs, Code: absX, Value: 10},
+		{Type: evAbs, Code: absPressure, Value: 1000},
+		{Type: evKey, Code: evBtnToolPen, Value: btnStatePressed},
+		{Type: evKey, Code: evBtnTouch, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: evBtnToolPen, Value: btnStateReleased},
+		{Type: evKey, Code: evBtnTouch, Value: btnStateReleased},
+		{Type: evAbs, Code: absY, Value: 384},
+		{Type: evAbs, Code: absX, Value: 512},
+		{Type: evAbs, Code: absPressure, Value: 0},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestStylusCreationFailsOnInvalidRange(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	_, err := CreateStylus(path, []byte("Test Stylus"), 100, 0, 0, 100)
+	if err == nil {
+		t.Fatalf("Expected creating a stylus with an inverted x-axis to fail, but got no error.")
+	}
+	_, err = CreateStylus(path, []byte("Test Stylus"), 0, 100, 50, 50)
+	if err == nil {
+		t.Fatalf("Expected creating a stylus with an empty y-axis to fail, but got no error.")
+	}
+	if len(*calls) != 0 {
+		t.Fatalf("Expected the device not to be set up, but %d ioctls were issued", len(*calls))
+	}
+}
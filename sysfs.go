@@ -0,0 +1,155 @@
+package uinput
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sysClassInput is the sysfs directory that lists all input devices of the system.
+const sysClassInput = "/sys/class/input"
+
+// FindDevicesByName returns the event nodes (like /dev/input/event5) of all input devices of the system with the
+// given name, which helps locating a device that has just been created among many others. Since names do not
+// need to be unique, all matching devices are returned. An empty result means that no device matches.
+func FindDevicesByName(name string) ([]string, error) {
+	return findDevicesByName(sysClassInput, name)
+}
+
+// findDevicesByName scans the given directory, which is laid out like /sys/class/input, for devices with the
+// given name.
+func findDevicesByName(root string, name string) ([]string, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list input devices: %w", err)
+	}
+
+	var nodes []string
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), "input") {
+			continue
+		}
+		deviceDir := filepath.Join(root, entry.Name())
+		deviceName, err := os.ReadFile(filepath.Join(deviceDir, "name"))
+		if err != nil || strings.TrimSuffix(string(deviceName), "\n") != name {
+			continue
+		}
+
+		handlers, err := os.ReadDir(deviceDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list handlers of input device %s: %w", entry.Name(), err)
+		}
+		for _, handler := range handlers {
+			if strings.HasPrefix(handler.Name(), "event") {
+				nodes = append(nodes, filepath.Join("/dev/input", handler.Name()))
+			}
+		}
+	}
+	return nodes, nil
+}
+
+// A DevNodeChowner changes the owner of the event node (like /dev/input/event5) of a device, which allows handing
+// the device over to an unprivileged user after it has been created. All devices of this package (except for the
+// noop devices) implement DevNodeChowner.
+type DevNodeChowner interface {
+	// ChownDevNode changes the owner of the event node of the device to the given user and group. This requires
+	// root privileges or the CAP_CHOWN capability.
+	ChownDevNode(uid int, gid int) error
+}
+
+// chown changes the owner of a file. It is a variable in order to be replaced in tests.
+var chown = os.Chown
+
+// chownDevNode resolves the event node of the device with the syspath returned by the given function and changes
+// its owner.
+func chownDevNode(fetchSyspath func() (string, error), uid int, gid int) error {
+	syspath, err := fetchSyspath()
+	if err != nil {
+		return fmt.Errorf("failed to resolve the event node: %w", err)
+	}
+	node, err := eventNodeOf(strings.TrimRight(syspath, "\x00"))
+	if err != nil {
+		return err
+	}
+
+	err = chown(node, uid, gid)
+	if errors.Is(err, fs.ErrPermission) {
+		return fmt.Errorf("not permitted to change the owner of %s, which requires root privileges or CAP_CHOWN: %w", node, err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to change the owner of %s: %w", node, err)
+	}
+	return nil
+}
+
+// eventNodeOf returns the event node of the input device with the given syspath, which lists the event handler
+// of the device (like event5) as a subdirectory.
+func eventNodeOf(syspath string) (string, error) {
+	handlers, err := os.ReadDir(syspath)
+	if err != nil {
+		return "", fmt.Errorf("failed to list handlers of input device %s: %w", syspath, err)
+	}
+	for _, handler := range handlers {
+		if strings.HasPrefix(handler.Name(), "event") {
+			return filepath.Join("/dev/input", handler.Name()), nil
+		}
+	}
+	return "", fmt.Errorf("input device %s has no event node", syspath)
+}
+
+// readCapabilities returns the configuration of the input device with the given syspath, as listed in its
+// capabilities directory. Only the event types, keys and axes are read, which is what a Validator checks.
+func readCapabilities(syspath string) (DeviceConfig, error) {
+	var config DeviceConfig
+	var err error
+	config.EvTypes, err = readCapabilityBitmap(syspath, "ev")
+	if err != nil {
+		return DeviceConfig{}, err
+	}
+	config.Keys, err = readCapabilityBitmap(syspath, "key")
+	if err != nil {
+		return DeviceConfig{}, err
+	}
+	config.Rels, err = readCapabilityBitmap(syspath, "rel")
+	if err != nil {
+		return DeviceConfig{}, err
+	}
+	abs, err := readCapabilityBitmap(syspath, "abs")
+	if err != nil {
+		return DeviceConfig{}, err
+	}
+	for _, code := range abs {
+		config.Abs = append(config.Abs, ConfigAxis{Code: code})
+	}
+	return config, nil
+}
+
+// readCapabilityBitmap returns the codes that are set in the given capability of an input device. The kernel lists
+// them as a bitmap of hexadecimal words, most significant first, which have the size of a long of the reading
+// process.
+func readCapabilityBitmap(syspath string, capability string) ([]uint16, error) {
+	data, err := os.ReadFile(filepath.Join(syspath, "capabilities", capability))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read capabilities of input device %s: %w", syspath, err)
+	}
+
+	words := strings.Fields(string(data))
+	var codes []uint16
+	for i := len(words) - 1; i >= 0; i-- {
+		bits, err := strconv.ParseUint(words[i], 16, strconv.IntSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s capabilities of input device %s: %w", capability, syspath, err)
+		}
+		offset := (len(words) - 1 - i) * strconv.IntSize
+		for bit := 0; bit < strconv.IntSize; bit++ {
+			if bits&(1<<bit) != 0 {
+				codes = append(codes, uint16(offset+bit))
+			}
+		}
+	}
+	return codes, nil
+}
@@ -0,0 +1,195 @@
+package uinput
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+var (
+	_ DevNodeChowner = (*vKeyboard)(nil)
+	_ DevNodeChowner = vMouse{}
+	_ DevNodeChowner = (*vTouchPad)(nil)
+	_ DevNodeChowner = vDial{}
+	_ DevNodeChowner = vGamepad{}
+	_ DevNodeChowner = vMultiTouch{}
+	_ DevNodeChowner = vScrollDevice{}
+	_ DevNodeChowner = vSpaceMouse{}
+	_ DevNodeChowner = vStylus{}
+	_ DevNodeChowner = vHybridPointer{}
+	_ DevNodeChowner = vTouchScreen{}
+	_ DevNodeChowner = vButtonPad{}
+	_ DevNodeChowner = vSlider{}
+	_ DevNodeChowner = vRelativeDevice{}
+	_ DevNodeChowner = vDevice{}
+)
+
+// fakeSysClassInput creates a directory laid out like /sys/class/input that contains the given devices, which
+// map the name of the input device directory (e.g. input5) to the name of the device and its event handler.
+func fakeSysClassInput(t *testing.T, devices map[string][2]string) string {
+	root := t.TempDir()
+	for dir, device := range devices {
+		deviceDir := filepath.Join(root, dir)
+		err := os.MkdirAll(filepath.Join(deviceDir, device[1]), 0755)
+		if err != nil {
+			t.Fatalf("Failed to setup test. Unable to create device directory: %v", err)
+		}
+		err = os.WriteFile(filepath.Join(deviceDir, "name"), []byte(device[0]+"\n"), 0644)
+		if err != nil {
+			t.Fatalf("Failed to setup test. Unable to write device name: %v", err)
+		}
+	}
+	// the event nodes are listed as well, but do not have a name
+	err := os.Mkdir(filepath.Join(root, "event1"), 0755)
+	if err != nil {
+		t.Fatalf("Failed to setup test. Unable to create event directory: %v", err)
+	}
+	return root
+}
+
+func TestFindDevicesByNameReturnsAllMatchingDevices(t *testing.T) {
+	root := fakeSysClassInput(t, map[string][2]string{
+		"input1": {"Power Button", "event1"},
+		"input2": {"Test Keyboard", "event2"},
+		"input3": {"Test Keyboard Extra", "event3"},
+		"input4": {"Test Keyboard", "event4"},
+	})
+
+	nodes, err := findDevicesByName(root, "Test Keyboard")
+	if err != nil {
+		t.Fatalf("Failed to find devices. Last error was: %s\n", err)
+	}
+	expected := []string{"/dev/input/event2", "/dev/input/event4"}
+	if !reflect.DeepEqual(nodes, expected) {
+		t.Fatalf("Expected: %v\nActual: %v", expected, nodes)
+	}
+}
+
+func TestFindDevicesByNameReturnsNothingIfNoDeviceMatches(t *testing.T) {
+	root := fakeSysClassInput(t, map[string][2]string{
+		"input1": {"Power Button", "event1"},
+	})
+
+	nodes, err := findDevicesByName(root, "Test Keyboard")
+	if err != nil {
+		t.Fatalf("Failed to find devices. Last error was: %s\n", err)
+	}
+	if len(nodes) != 0 {
+		t.Fatalf("Expected no devices to be found, but got: %v", nodes)
+	}
+}
+
+func TestFindDevicesByNameFailsIfDirectoryIsMissing(t *testing.T) {
+	_, err := findDevicesByName(filepath.Join(t.TempDir(), "missing"), "Test Keyboard")
+	if err == nil {
+		t.Fatalf("Expected finding devices to fail, but got no error.")
+	}
+}
+
+// fakeChown replaces chown with a function that records the changed nodes and returns the given error.
+func fakeChown(err error) (*[]string, func()) {
+	original := chown
+	nodes := &[]string{}
+	chown = func(name string, uid int, gid int) error {
+		*nodes = append(*nodes, fmt.Sprintf("%s:%d:%d", name, uid, gid))
+		return err
+	}
+	return nodes, func() { chown = original }
+}
+
+// fakeSyspath creates a directory laid out like the syspath of an input device with the given event handler. The
+// returned syspath is padded with null bytes, just like the one returned by FetchSyspath.
+func fakeSyspath(t *testing.T, handler string) string {
+	syspath := t.TempDir()
+	err := os.Mkdir(filepath.Join(syspath, handler), 0755)
+	if err != nil {
+		t.Fatalf("Failed to setup test. Unable to create handler directory: %v", err)
+	}
+	return syspath + "\x00\x00\x00"
+}
+
+func TestChownDevNodeChangesOwnerOfResolvedNode(t *testing.T) {
+	syspath := fakeSyspath(t, "event7")
+	nodes, restore := fakeChown(nil)
+	defer restore()
+
+	err := chownDevNode(func() (string, error) { return syspath, nil }, 1000, 100)
+	if err != nil {
+		t.Fatalf("Failed to change the owner. Last error was: %s\n", err)
+	}
+	expected := []string{"/dev/input/event7:1000:100"}
+	if !reflect.DeepEqual(*nodes, expected) {
+		t.Fatalf("Expected: %v\nActual: %v", expected, *nodes)
+	}
+}
+
+func TestChownDevNodeReportsMissingPermission(t *testing.T) {
+	syspath := fakeSyspath(t, "event7")
+	_, restore := fakeChown(&os.PathError{Op: "chown", Path: "/dev/input/event7", Err: syscall.EPERM})
+	defer restore()
+
+	err := chownDevNode(func() (string, error) { return syspath, nil }, 1000, 100)
+	if !errors.Is(err, fs.ErrPermission) || !strings.Contains(err.Error(), "not permitted") {
+		t.Fatalf("Expected a permission error, but got %v", err)
+	}
+}
+
+func TestChownDevNodeFailsWithoutEventNode(t *testing.T) {
+	syspath := fakeSyspath(t, "mouse0")
+	nodes, restore := fakeChown(nil)
+	defer restore()
+
+	err := chownDevNode(func() (string, error) { return syspath, nil }, 1000, 100)
+	if err == nil {
+		t.Fatalf("Expected resolving the event node to fail, but no error was returned.")
+	}
+	if len(*nodes) != 0 {
+		t.Fatalf("Expected no node to be changed, but got %v", *nodes)
+	}
+}
+
+func TestReadCapabilitiesDecodesBitmaps(t *testing.T) {
+	syspath := t.TempDir()
+	err := os.Mkdir(filepath.Join(syspath, "capabilities"), 0755)
+	if err != nil {
+		t.Fatalf("Failed to setup test. Unable to create capabilities directory: %v", err)
+	}
+	for capability, bitmap := range map[string]string{
+		"ev":  "7\n",
+		"key": "1 2\n",
+		"rel": "103\n",
+		"abs": "0\n",
+	} {
+		err = os.WriteFile(filepath.Join(syspath, "capabilities", capability), []byte(bitmap), 0644)
+		if err != nil {
+			t.Fatalf("Failed to setup test. Unable to write capabilities: %v", err)
+		}
+	}
+
+	config, err := readCapabilities(syspath)
+	if err != nil {
+		t.Fatalf("Failed to read capabilities. Last error was: %s\n", err)
+	}
+	expected := DeviceConfig{
+		EvTypes: []uint16{evSyn, evKey, evRel},
+		Keys:    []uint16{1, strconv.IntSize},
+		Rels:    []uint16{relX, relY, relWheel},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Fatalf("Expected: %+v\nActual: %+v", expected, config)
+	}
+}
+
+func TestReadCapabilitiesFailsWithoutCapabilities(t *testing.T) {
+	_, err := readCapabilities(t.TempDir())
+	if err == nil {
+		t.Fatalf("Expected reading capabilities to fail, but got no error.")
+	}
+}
@@ -1,11 +1,33 @@
 package uinput
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"sync"
+	"time"
 )
 
+const (
+	// touchPressure is the pressure reported while touching, e.g. during a tap (the maximum being
+	// touchPadMaxPressure). Consumers like libinput ignore touches without pressure once ABS_PRESSURE is registered.
+	touchPressure       = 50
+	touchPadMaxPressure = 255
+	// tapHoldDuration is the default time between touch down and touch up of a tap (see WithTapDuration). It needs
+	// to stay well below the tap timeout of the consumer (libinput uses 180ms), otherwise the touch will not be
+	// interpreted as a tap.
+	tapHoldDuration = 20 * time.Millisecond
+	// hiResPerDetent is the number of high-resolution wheel units that make up one detent of an ordinary wheel.
+	hiResPerDetent = 120
+)
+
+// A Point is a position on a touch pad.
+type Point struct {
+	X, Y int32
+}
+
 // A TouchPad is an input device that uses absolute axis events, meaning that you can specify
 // the exact position the cursor should move to. Therefore, it is necessary to define the size
 // of the rectangle in which the cursor may move upon creation of the device.
@@ -45,14 +67,114 @@ type TouchPad interface {
 	io.Closer
 }
 
+// A Tapper taps on the surface of a device. The touch pads created by this package implement Tapper.
+type Tapper interface {
+	// Tap will simulate a short touch at the current position without any movement, which consumers like
+	// libinput interpret as a click when tap-to-click is enabled. Unlike LeftClick, no BTN_LEFT event is issued.
+	Tap() error
+}
+
+// A PositionReporter reports the absolute position of a device. The touch pads created by this package implement
+// PositionReporter.
+type PositionReporter interface {
+	// GetPosition returns the position that the cursor has last been moved to using MoveTo.
+	GetPosition() (x int32, y int32)
+}
+
+// A ScaledMover moves the cursor to positions that are given in other units than the range of the device. The
+// touch pads created by this package implement ScaledMover.
+type ScaledMover interface {
+	// MoveToPixel will move the cursor to the specified pixel of the screen. The pixel is mapped onto the range of
+	// the touch pad, which requires the resolution of the screen to be set using WithScreenResolution. Pixels
+	// outside of the screen are rejected.
+	MoveToPixel(px int32, py int32) error
+
+	// MoveToFraction will move the cursor to the given fractions (0.0 to 1.0) of the range of the touch pad, which
+	// allows resolution-independent automation. Fractions outside of this range are clamped.
+	MoveToFraction(fx float64, fy float64) error
+}
+
+// A PositionClicker clicks at a given position instead of the current one. The touch pads created by this package
+// implement PositionClicker.
+type PositionClicker interface {
+	// ClickAt will move the cursor to the specified position and issue a left click there. The move is reported
+	// along with the press of the button, so that the click can not land on any other position.
+	ClickAt(x int32, y int32) error
+}
+
+// A HiResScroller scrolls in high-resolution wheel units. The touch pads created by this package implement
+// HiResScroller. Note that a ScrollDevice does not, even though its Scroll method has the same signature: it
+// takes detents instead.
+type HiResScroller interface {
+	// Scroll will simulate a vertical scroll movement in high-resolution wheel units, where 120 units correspond to
+	// one detent of an ordinary wheel. Positive values scroll up. This requires the touch pad to be created using
+	// WithHiResScroll.
+	Scroll(hiResDelta int32) error
+}
+
+// A PointMover moves the cursor to positions that are given as points. The touch pads created by this package
+// implement PointMover.
+type PointMover interface {
+	// MoveToPoint will move the cursor to the specified point, just like MoveTo.
+	MoveToPoint(p Point) error
+
+	// PathMove will move the cursor through the given points in order, issuing one report per point. This is
+	// useful in order to draw paths. The movement stops at the first point that can not be moved to.
+	PathMove(points ...Point) error
+}
+
+// A LineDrawer moves a touch along a straight line, keeping it on the surface in between. The touch pads created by
+// this package implement LineDrawer.
+type LineDrawer interface {
+	// DrawLine will touch down at the start position, move along a straight line to the end position in the given
+	// number of steps, issuing one report per step, and lift the touch again. The start and end positions are hit
+	// exactly. This is useful in order to test signature pads or drawing applications.
+	DrawLine(x0, y0, x1, y1 int32, steps int) error
+
+	// DragTo will perform a finger drag from the current position (see GetPosition) to the given position in the
+	// given number of steps, keeping BTN_TOUCH asserted for all of them. Unlike DrawLine, the cursor is not moved
+	// before touching down, which makes DragTo the touch pad analog of pressing a mouse button while moving.
+	DragTo(x int32, y int32, steps int) error
+}
+
+// An AbsAxisSetter sets single absolute axes of a device. The touch pads created by this package implement
+// AbsAxisSetter.
+type AbsAxisSetter interface {
+	// SetAbsAxis will set a single absolute axis (ABS_X, ABS_Y or ABS_PRESSURE) to the given value, without
+	// reporting the other axes. The axis policy of the touch pad is applied to the value (see WithAxisPolicy).
+	SetAbsAxis(code uint16, value int32) error
+
+	// SetPressureNorm will set ABS_PRESSURE to the given fraction (0.0 to 1.0) of the pressure range, which
+	// avoids scaling normalized values manually. Values outside of this range are clamped.
+	SetPressureNorm(f float64) error
+}
+
 type vTouchPad struct {
-	name       []byte
-	deviceFile *os.File
+	deviceBase
+	// mu guards the position and the scroll remainder, which are only updated once the events have been written
+	mu sync.Mutex
+	// the position that has last been moved to
+	x int32
+	y int32
+	// the range of the axes, along with the resolution of the screen (zero if not configured)
+	minX, maxX, minY, maxY    int32
+	screenWidth, screenHeight int32
+	// clickDelay is the time between the press and the release of a click
+	clickDelay time.Duration
+	// tapDuration is the time between touch down and touch up of a tap, or zero for tapHoldDuration
+	tapDuration time.Duration
+	axisPolicy  AxisPolicy
+	// absAxisOrder is the order in which the axes of positions are reported
+	absAxisOrder AbsAxisOrder
+	// hiResScroll is set if the wheel has been registered, scrollRemainder holds the high-resolution units that
+	// do not yet add up to a full detent
+	hiResScroll     bool
+	scrollRemainder int32
 }
 
 // CreateTouchPad will create a new touchpad device. note that you will need to define the x and y-axis boundaries
 // (min and max) within which the cursor maybe moved around.
-func CreateTouchPad(path string, name []byte, minX int32, maxX int32, minY int32, maxY int32) (TouchPad, error) {
+func CreateTouchPad(path string, name []byte, minX int32, maxX int32, minY int32, maxY int32, opts ...Option) (TouchPad, error) {
 	err := validateDevicePath(path)
 	if err != nil {
 		return nil, err
@@ -61,103 +183,430 @@ func CreateTouchPad(path string, name []byte, minX int32, maxX int32, minY int32
 	if err != nil {
 		return nil, err
 	}
+	options := newDeviceOptions(opts)
+	if options.screenWidth < 0 || options.screenHeight < 0 {
+		return nil, fmt.Errorf("invalid screen resolution %dx%d", options.screenWidth, options.screenHeight)
+	}
 
-	fd, err := createTouchPad(path, name, minX, maxX, minY, maxY)
+	fd, err := createTouchPad(path, name, minX, maxX, minY, maxY, options)
 	if err != nil {
 		return nil, err
 	}
 
-	return vTouchPad{name: name, deviceFile: fd}, nil
+	return &vTouchPad{
+		deviceBase:   deviceBase{name: name, deviceFile: fd, report: newReportBuilderWithOptions(fd, name, options)},
+		minX:         minX,
+		maxX:         maxX,
+		minY:         minY,
+		maxY:         maxY,
+		screenWidth:  options.screenWidth,
+		screenHeight: options.screenHeight,
+		clickDelay:   options.clickDelay,
+		tapDuration:  options.tapDuration,
+		axisPolicy:   options.axisPolicy,
+		absAxisOrder: options.absAxisOrder,
+		hiResScroll:  options.hiResScroll,
+	}, nil
+}
+
+func (vTouch *vTouchPad) MoveTo(x int32, y int32) error {
+	x, y, err := vTouch.clampPosition(x, y)
+	if err != nil {
+		return err
+	}
+
+	vTouch.mu.Lock()
+	defer vTouch.mu.Unlock()
+	err = sendAbsEvent(vTouch.report, x, y, vTouch.absAxisOrder)
+	if err != nil {
+		return err
+	}
+	vTouch.x, vTouch.y = x, y
+	return nil
+}
+
+func (vTouch *vTouchPad) MoveToPoint(p Point) error {
+	return vTouch.MoveTo(p.X, p.Y)
+}
+
+func (vTouch *vTouchPad) PathMove(points ...Point) error {
+	for i, p := range points {
+		err := vTouch.MoveTo(p.X, p.Y)
+		if err != nil {
+			return fmt.Errorf("failed to move to point %d of the path: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (vTouch *vTouchPad) DrawLine(x0, y0, x1, y1 int32, steps int) error {
+	points, err := linePoints(x0, y0, x1, y1, steps)
+	if err != nil {
+		return err
+	}
+
+	err = vTouch.MoveTo(x0, y0)
+	if err != nil {
+		return fmt.Errorf("failed to move to the start of the line: %w", err)
+	}
+	err = vTouch.touchAlong(points[1:])
+	if err != nil {
+		return fmt.Errorf("failed to draw line: %w", err)
+	}
+	return nil
+}
+
+// DragTo will touch down at the current position, move to the given position like DrawLine and lift the touch again.
+func (vTouch *vTouchPad) DragTo(x int32, y int32, steps int) error {
+	x0, y0 := vTouch.GetPosition()
+	points, err := linePoints(x0, y0, x, y, steps)
+	if err != nil {
+		return err
+	}
+
+	err = vTouch.touchAlong(points[1:])
+	if err != nil {
+		return fmt.Errorf("failed to drag: %w", err)
+	}
+	return nil
+}
+
+// touchAlong touches down, moves through the given points and lifts the touch again, even if a move fails.
+func (vTouch *vTouchPad) touchAlong(points []Point) error {
+	err := vTouch.TouchDown()
+	if err != nil {
+		return fmt.Errorf("failed to touch down: %w", err)
+	}
+	err = vTouch.PathMove(points...)
+	if err != nil {
+		_ = vTouch.TouchUp()
+		return err
+	}
+	return vTouch.TouchUp()
+}
+
+// linePoints interpolates the given number of steps along the line from (x0, y0) to (x1, y1). The returned points
+// include the start point, followed by one point per step, the last one being the end point.
+func linePoints(x0, y0, x1, y1 int32, steps int) ([]Point, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("%d is out of range. Expected at least one step", steps)
+	}
+	points := make([]Point, 0, steps+1)
+	for i := 0; i <= steps; i++ {
+		points = append(points, Point{
+			X: interpolate(x0, x1, i, steps),
+			Y: interpolate(y0, y1, i, steps),
+		})
+	}
+	return points, nil
+}
+
+// interpolate returns the value at the given step between from and to, rounded to the nearest integer.
+func interpolate(from int32, to int32, step int, steps int) int32 {
+	delta := float64(int64(to)-int64(from)) * float64(step) / float64(steps)
+	return int32(int64(from) + int64(math.Round(delta)))
+}
+
+// clampPosition applies the axis policy of the touch pad to the given position.
+func (vTouch *vTouchPad) clampPosition(x int32, y int32) (int32, int32, error) {
+	x, err := clampAxis(x, vTouch.minX, vTouch.maxX, vTouch.axisPolicy)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to move along the x-axis: %w", err)
+	}
+	y, err = clampAxis(y, vTouch.minY, vTouch.maxY, vTouch.axisPolicy)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to move along the y-axis: %w", err)
+	}
+	return x, y, nil
+}
+
+// MoveToPixel will move the cursor to the given pixel. The first and last pixel of the screen are mapped to the
+// minimum and maximum of the respective axis, pixels beyond them would be mapped outside of the axes.
+func (vTouch *vTouchPad) MoveToPixel(px int32, py int32) error {
+	if vTouch.screenWidth == 0 || vTouch.screenHeight == 0 {
+		return errors.New("screen resolution is not configured (see WithScreenResolution)")
+	}
+	if px < 0 || px >= vTouch.screenWidth || py < 0 || py >= vTouch.screenHeight {
+		return fmt.Errorf("pixel (%d, %d) is outside of the %dx%d screen", px, py, vTouch.screenWidth, vTouch.screenHeight)
+	}
+	return vTouch.MoveTo(scalePixel(px, vTouch.screenWidth, vTouch.minX, vTouch.maxX),
+		scalePixel(py, vTouch.screenHeight, vTouch.minY, vTouch.maxY))
+}
+
+// scalePixel maps the given pixel of a screen dimension with the given number of pixels onto the range [min, max].
+func scalePixel(pixel int32, pixels int32, min int32, max int32) int32 {
+	if pixels <= 1 {
+		return min
+	}
+	scale := (float64(max) - float64(min)) / float64(pixels-1)
+	return min + int32(math.Round(float64(pixel)*scale))
+}
+
+// MoveToFraction will move the cursor to the given fractions of the range of the touch pad, where 0.0 is mapped to
+// the minimum and 1.0 to the maximum of the respective axis.
+func (vTouch *vTouchPad) MoveToFraction(fx float64, fy float64) error {
+	return vTouch.MoveTo(scaleFraction(fx, vTouch.minX, vTouch.maxX), scaleFraction(fy, vTouch.minY, vTouch.maxY))
+}
+
+// scaleFraction maps the given fraction onto the range [min, max], clamping it to [0, 1] first.
+func scaleFraction(fraction float64, min int32, max int32) int32 {
+	if math.IsNaN(fraction) || fraction < 0 {
+		fraction = 0
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	return min + int32(math.Round(fraction*(float64(max)-float64(min))))
+}
+
+// SetAbsAxis will emit a single absolute axis event. Setting ABS_X or ABS_Y updates the position that is returned
+// by GetPosition.
+func (vTouch *vTouchPad) SetAbsAxis(code uint16, value int32) error {
+	var err error
+	switch code {
+	case absX:
+		value, err = clampAxis(value, vTouch.minX, vTouch.maxX, vTouch.axisPolicy)
+	case absY:
+		value, err = clampAxis(value, vTouch.minY, vTouch.maxY, vTouch.axisPolicy)
+	case absPressure:
+		value, err = clampAxis(value, 0, touchPadMaxPressure, vTouch.axisPolicy)
+	default:
+		return fmt.Errorf("absolute axis %d is not registered", code)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set absolute axis %d: %w", code, err)
+	}
+
+	vTouch.mu.Lock()
+	defer vTouch.mu.Unlock()
+	err = sendAbsAxisEvent(vTouch.report, code, value)
+	if err != nil {
+		return err
+	}
+	switch code {
+	case absX:
+		vTouch.x = value
+	case absY:
+		vTouch.y = value
+	}
+	return nil
+}
+
+// SetPressureNorm will report the given fraction of the pressure range as a single ABS_PRESSURE event.
+func (vTouch *vTouchPad) SetPressureNorm(f float64) error {
+	return sendAbsAxisEvent(vTouch.report, absPressure, scaleFraction(f, 0, touchPadMaxPressure))
+}
+
+// GetPosition returns the position that has last been moved to successfully. Note that this is the requested
+// position, even if a slightly different value had to be sent to the device (see sendAbsEvent).
+func (vTouch *vTouchPad) GetPosition() (x int32, y int32) {
+	vTouch.mu.Lock()
+	defer vTouch.mu.Unlock()
+	return vTouch.x, vTouch.y
 }
 
-func (vTouch vTouchPad) MoveTo(x int32, y int32) error {
-	return sendAbsEvent(vTouch.deviceFile, x, y)
+func (vTouch *vTouchPad) LeftClick() error {
+	err := sendBtnEvent(vTouch.report, []int{evMouseBtnLeft}, btnStatePressed)
+	if err != nil {
+		return fmt.Errorf("failed to issue the LeftClick event: %w", err)
+	}
+	time.Sleep(vTouch.clickDelay)
+
+	return sendBtnEvent(vTouch.report, []int{evMouseBtnLeft}, btnStateReleased)
 }
 
-func (vTouch vTouchPad) LeftClick() error {
-	err := sendBtnEvent(vTouch.deviceFile, []int{evMouseBtnLeft}, btnStatePressed)
+func (vTouch *vTouchPad) RightClick() error {
+	err := sendBtnEvent(vTouch.report, []int{evMouseBtnRight}, btnStatePressed)
 	if err != nil {
-		return fmt.Errorf("failed to issue the LeftClick event: %v", err)
+		return fmt.Errorf("failed to issue the RightClick event: %w", err)
 	}
+	time.Sleep(vTouch.clickDelay)
 
-	return sendBtnEvent(vTouch.deviceFile, []int{evMouseBtnLeft}, btnStateReleased)
+	return sendBtnEvent(vTouch.report, []int{evMouseBtnRight}, btnStateReleased)
 }
 
-func (vTouch vTouchPad) RightClick() error {
-	err := sendBtnEvent(vTouch.deviceFile, []int{evMouseBtnRight}, btnStatePressed)
+// ClickAt will move to the given position and press the left button within a single report, followed by the
+// release of the button after the click delay.
+func (vTouch *vTouchPad) ClickAt(x int32, y int32) error {
+	x, y, err := vTouch.clampPosition(x, y)
 	if err != nil {
-		return fmt.Errorf("failed to issue the RightClick event: %v", err)
+		return err
 	}
 
-	return sendBtnEvent(vTouch.deviceFile, []int{evMouseBtnRight}, btnStateReleased)
+	events := append(orderAbsEvents(absEvents(x, y), vTouch.absAxisOrder),
+		inputEvent{Type: evKey, Code: evMouseBtnLeft, Value: btnStatePressed})
+	err = vTouch.pressAt(x, y, events)
+	if err != nil {
+		return fmt.Errorf("failed to issue the ClickAt event: %w", err)
+	}
+	time.Sleep(vTouch.clickDelay)
+
+	return sendBtnEvent(vTouch.report, []int{evMouseBtnLeft}, btnStateReleased)
+}
+
+// pressAt sends the press of ClickAt and records the position, without letting another move interfere in between.
+func (vTouch *vTouchPad) pressAt(x int32, y int32, events []inputEvent) error {
+	vTouch.mu.Lock()
+	defer vTouch.mu.Unlock()
+	err := vTouch.report.send(events...)
+	if err != nil {
+		return err
+	}
+	vTouch.x, vTouch.y = x, y
+	return nil
 }
 
 // LeftPress will simulate a press of the left mouse button. Note that the button will not be released until
 // LeftRelease is invoked.
-func (vTouch vTouchPad) LeftPress() error {
-	return sendBtnEvent(vTouch.deviceFile, []int{evMouseBtnLeft}, btnStatePressed)
+func (vTouch *vTouchPad) LeftPress() error {
+	return sendBtnEvent(vTouch.report, []int{evMouseBtnLeft}, btnStatePressed)
 }
 
 // LeftRelease will simulate the release of the left mouse button.
-func (vTouch vTouchPad) LeftRelease() error {
-	return sendBtnEvent(vTouch.deviceFile, []int{evMouseBtnLeft}, btnStateReleased)
+func (vTouch *vTouchPad) LeftRelease() error {
+	return sendBtnEvent(vTouch.report, []int{evMouseBtnLeft}, btnStateReleased)
 }
 
 // RightPress will simulate the press of the right mouse button. Note that the button will not be released until
 // RightRelease is invoked.
-func (vTouch vTouchPad) RightPress() error {
-	return sendBtnEvent(vTouch.deviceFile, []int{evMouseBtnRight}, btnStatePressed)
+func (vTouch *vTouchPad) RightPress() error {
+	return sendBtnEvent(vTouch.report, []int{evMouseBtnRight}, btnStatePressed)
 }
 
 // RightRelease will simulate the release of the right mouse button.
-func (vTouch vTouchPad) RightRelease() error {
-	return sendBtnEvent(vTouch.deviceFile, []int{evMouseBtnRight}, btnStateReleased)
+func (vTouch *vTouchPad) RightRelease() error {
+	return sendBtnEvent(vTouch.report, []int{evMouseBtnRight}, btnStateReleased)
+}
+
+// TouchDown reports the touch along with some pressure, since the touch pad registers ABS_PRESSURE (see Tap).
+func (vTouch *vTouchPad) TouchDown() error {
+	return sendTouchEvent(vTouch.report, touchPressure, btnStatePressed)
 }
 
-func (vTouch vTouchPad) TouchDown() error {
-	return sendBtnEvent(vTouch.deviceFile, []int{evBtnTouch}, btnStatePressed)
+// TouchUp ends the touch and its pressure.
+func (vTouch *vTouchPad) TouchUp() error {
+	return sendTouchEvent(vTouch.report, 0, btnStateReleased)
 }
 
-func (vTouch vTouchPad) TouchUp() error {
-	return sendBtnEvent(vTouch.deviceFile, []int{evBtnTouch}, btnStateReleased)
+// Tap will issue a touch down with a small pressure value, followed by a touch up shortly after.
+func (vTouch *vTouchPad) Tap() error {
+	err := sendTouchEvent(vTouch.report, touchPressure, btnStatePressed)
+	if err != nil {
+		return fmt.Errorf("failed to issue the touch down event of the tap: %w", err)
+	}
+
+	time.Sleep(tapHoldTime(vTouch.tapDuration))
+
+	err = sendTouchEvent(vTouch.report, 0, btnStateReleased)
+	if err != nil {
+		return fmt.Errorf("failed to issue the touch up event of the tap: %w", err)
+	}
+	return nil
 }
 
-func (vTouch vTouchPad) Close() error {
-	return closeDevice(vTouch.deviceFile)
+// tapHoldTime returns the given tap duration, or the default if it is not set.
+func tapHoldTime(tapDuration time.Duration) time.Duration {
+	if tapDuration <= 0 {
+		return tapHoldDuration
+	}
+	return tapDuration
 }
 
-func createTouchPad(path string, name []byte, minX int32, maxX int32, minY int32, maxY int32) (fd *os.File, err error) {
+// Scroll will emit the given high-resolution wheel movement along with an ordinary wheel movement for every full
+// detent that has been accumulated, which is how real high-resolution wheels report scrolling.
+func (vTouch *vTouchPad) Scroll(hiResDelta int32) error {
+	if !vTouch.hiResScroll {
+		return errors.New("high-resolution scrolling is not enabled (see WithHiResScroll)")
+	}
+
+	vTouch.mu.Lock()
+	defer vTouch.mu.Unlock()
+	accumulated := int64(vTouch.scrollRemainder) + int64(hiResDelta)
+	detents := accumulated / hiResPerDetent
+	events := []inputEvent{{Type: evRel, Code: relWheelHiRes, Value: hiResDelta}}
+	if detents != 0 {
+		events = append(events, inputEvent{Type: evRel, Code: relWheel, Value: int32(detents)})
+	}
+
+	err := vTouch.report.send(events...)
+	if err != nil {
+		return fmt.Errorf("failed to issue the scroll event: %w", err)
+	}
+	vTouch.scrollRemainder = int32(accumulated - detents*hiResPerDetent)
+	return nil
+}
+
+// Reset releases all buttons, ends the touch and moves the cursor to the center of the touch pad (see Resetter).
+func (vTouch *vTouchPad) Reset() error {
+	x, y := axisCenter(vTouch.minX, vTouch.maxX), axisCenter(vTouch.minY, vTouch.maxY)
+	events := append(orderAbsEvents(absEvents(x, y), vTouch.absAxisOrder),
+		inputEvent{Type: evAbs, Code: absPressure, Value: 0})
+	vTouch.mu.Lock()
+	defer vTouch.mu.Unlock()
+	err := vTouch.report.reset(events...)
+	if err != nil {
+		return fmt.Errorf("failed to reset touch pad: %w", err)
+	}
+	vTouch.x, vTouch.y = x, y
+	return nil
+}
+
+func createTouchPad(path string, name []byte, minX int32, maxX int32, minY int32, maxY int32, options deviceOptions) (fd *os.File, err error) {
+	err = validateAxisRange("x", minX, maxX)
+	if err != nil {
+		return nil, err
+	}
+	err = validateAxisRange("y", minY, maxY)
+	if err != nil {
+		return nil, err
+	}
+
 	deviceFile, err := createDeviceFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("could not create absolute axis input device: %v", err)
+		return nil, fmt.Errorf("could not create absolute axis input device: %w", err)
 	}
 
 	err = registerDevice(deviceFile, uintptr(evKey))
 	if err != nil {
 		_ = deviceFile.Close()
-		return nil, fmt.Errorf("failed to register key device: %v", err)
+		return nil, fmt.Errorf("failed to register key device: %w", err)
 	}
 	// register button events (in order to enable left and right click)
 	for _, event := range []int{evMouseBtnLeft, evMouseBtnRight, evBtnTouch} {
 		err = ioctl(deviceFile, uiSetKeyBit, uintptr(event))
 		if err != nil {
 			_ = deviceFile.Close()
-			return nil, fmt.Errorf("failed to register button event %v: %v", event, err)
+			return nil, fmt.Errorf("failed to register button event %v: %w", event, err)
 		}
 	}
 
 	err = registerDevice(deviceFile, uintptr(evAbs))
 	if err != nil {
 		_ = deviceFile.Close()
-		return nil, fmt.Errorf("failed to register absolute axis input device: %v", err)
+		return nil, fmt.Errorf("failed to register absolute axis input device: %w", err)
 	}
 
-	// register x and y-axis events
-	for _, event := range []int{absX, absY} {
+	// register x and y-axis events as well as the pressure (used by Tap)
+	for _, event := range []int{absX, absY, absPressure} {
 		err = ioctl(deviceFile, uiSetAbsBit, uintptr(event))
 		if err != nil {
 			_ = deviceFile.Close()
-			return nil, fmt.Errorf("failed to register absolute axis event %v: %v", event, err)
+			return nil, fmt.Errorf("failed to register absolute axis event %v: %w", event, err)
+		}
+	}
+
+	if options.hiResScroll {
+		err = registerDevice(deviceFile, uintptr(evRel))
+		if err != nil {
+			_ = deviceFile.Close()
+			return nil, fmt.Errorf("failed to register relative axis input device: %w", err)
+		}
+		for _, event := range []int{relWheel, relWheelHiRes} {
+			err = ioctl(deviceFile, uiSetRelBit, uintptr(event))
+			if err != nil {
+				_ = deviceFile.Close()
+				return nil, fmt.Errorf("failed to register relative event %v: %w", event, err)
+			}
 		}
 	}
 
@@ -168,6 +617,7 @@ func createTouchPad(path string, name []byte, minX int32, maxX int32, minY int32
 	var absMax [absSize]int32
 	absMax[absX] = maxX
 	absMax[absY] = maxY
+	absMax[absPressure] = touchPadMaxPressure
 
 	return createUsbDevice(deviceFile,
 		uinputUserDev{
@@ -178,11 +628,30 @@ func createTouchPad(path string, name []byte, minX int32, maxX int32, minY int32
 				Product: 0x0817,
 				Version: 1},
 			Absmin: absMin,
-			Absmax: absMax})
+			Absmax: absMax},
+		options)
+}
+
+func sendAbsEvent(report *reportBuilder, xPos int32, yPos int32, order AbsAxisOrder) error { // TODO: Perhaps move this to a more generic function? This conflicts with the gamepad ABS events which only have one value.
+	err := report.send(orderAbsEvents(absEvents(xPos, yPos), order)...)
+	if err != nil {
+		return fmt.Errorf("failed to write abs event to device file: %w", err)
+	}
+	return nil
+}
+
+// sendAbsAxisEvent reports a single absolute axis, unlike sendAbsEvent, which always reports both ABS_X and ABS_Y.
+func sendAbsAxisEvent(report *reportBuilder, code uint16, value int32) error {
+	err := report.send(inputEvent{Type: evAbs, Code: code, Value: value})
+	if err != nil {
+		return fmt.Errorf("failed to write abs event to device file: %w", err)
+	}
+	return nil
 }
 
-func sendAbsEvent(deviceFile *os.File, xPos int32, yPos int32) error { // TODO: Perhaps move this to a more generic function? This conflicts with the gamepad ABS events which only have one value.
-	var ev [2]inputEvent
+// absEvents returns the events that move to the given position.
+func absEvents(xPos int32, yPos int32) []inputEvent {
+	ev := make([]inputEvent, 2)
 	ev[0].Type = evAbs
 	ev[0].Code = absX
 	ev[0].Value = xPos
@@ -199,21 +668,21 @@ func sendAbsEvent(deviceFile *os.File, xPos int32, yPos int32) error { // TODO:
 	ev[1].Code = absY
 	ev[1].Value = yPos
 
-	for _, iev := range ev {
-		buf, err := inputEventToBuffer(iev)
-		if err != nil {
-			return fmt.Errorf("writing abs event failed: %v", err)
-		}
-
-		_, err = deviceFile.Write(buf)
-		if err != nil {
-			return fmt.Errorf("failed to write abs event to device file: %v", err)
-		}
-	}
-
-	return syncEvents(deviceFile)
+	return ev
 }
 
-func (vTouch vTouchPad) FetchSyspath() (string, error) {
-	return fetchSyspath(vTouch.deviceFile)
+func sendTouchEvent(report *reportBuilder, pressure int32, btnState int) error {
+	err := report.send(
+		inputEvent{
+			Type:  evAbs,
+			Code:  absPressure,
+			Value: pressure},
+		inputEvent{
+			Type:  evKey,
+			Code:  evBtnTouch,
+			Value: int32(btnState)})
+	if err != nil {
+		return fmt.Errorf("failed to write touch event to device file: %w", err)
+	}
+	return nil
 }
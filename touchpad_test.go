@@ -4,7 +4,29 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"reflect"
+	"sync"
 	"testing"
+	"time"
+)
+
+var (
+	_ Tapper           = (*vTouchPad)(nil)
+	_ Tapper           = noopTouchPad{}
+	_ PositionReporter = (*vTouchPad)(nil)
+	_ PositionReporter = noopTouchPad{}
+	_ ScaledMover      = (*vTouchPad)(nil)
+	_ ScaledMover      = noopTouchPad{}
+	_ PositionClicker  = (*vTouchPad)(nil)
+	_ PositionClicker  = noopTouchPad{}
+	_ HiResScroller    = (*vTouchPad)(nil)
+	_ HiResScroller    = noopTouchPad{}
+	_ PointMover       = (*vTouchPad)(nil)
+	_ PointMover       = noopTouchPad{}
+	_ LineDrawer       = (*vTouchPad)(nil)
+	_ LineDrawer       = noopTouchPad{}
+	_ AbsAxisSetter    = (*vTouchPad)(nil)
+	_ AbsAxisSetter    = noopTouchPad{}
 )
 
 func TestBasicTouchPadMoves(t *testing.T) {
@@ -282,3 +304,521 @@ func TestTouchPadSyspath(t *testing.T) {
 
 	t.Logf("Syspath: %s", sysPath)
 }
+
+func TestTap(t *testing.T) {
+	dev, err := CreateTouchPad("/dev/uinput", []byte("touchpad"), 0, 200, 0, 100)
+	if err != nil {
+		t.Fatalf("Failed to create the virtual touch pad. Last error was: %s\n", err)
+	}
+	defer dev.Close()
+
+	err = dev.(Tapper).Tap()
+	if err != nil {
+		t.Fatalf("Failed to issue tap: %v", err)
+	}
+}
+
+func TestTapEmitsTouchDownAndUpWithinTapTimeout(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vTouchPad{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	err := dev.Tap()
+	if err != nil {
+		t.Fatalf("Failed to issue tap: %v", err)
+	}
+
+	events := stop()
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absPressure, Value: touchPressure},
+		{Type: evKey, Code: evBtnTouch, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absPressure, Value: 0},
+		{Type: evKey, Code: evBtnTouch, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, events)
+
+	// libinput will not consider the touch a tap if it takes longer than 180ms
+	held := events[4].received.Sub(events[1].received)
+	if held < tapHoldDuration || held >= 180*time.Millisecond {
+		t.Fatalf("Expected touch to be held for at least %v and less than 180ms, but it was held for %v", tapHoldDuration, held)
+	}
+}
+
+func TestTapDurationCanBeOverridden(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vTouchPad{deviceBase: deviceBase{report: newReportBuilder(file)}, tapDuration: 60 * time.Millisecond}
+
+	err := dev.Tap()
+	if err != nil {
+		t.Fatalf("Failed to issue tap: %v", err)
+	}
+
+	events := stop()
+	held := events[4].received.Sub(events[1].received)
+	if held < 60*time.Millisecond {
+		t.Fatalf("Expected touch to be held for at least 60ms, but it was held for %v", held)
+	}
+}
+
+func TestTapDurationOptionIsApplied(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	_, restore := fakeIoctl(nil)
+	defer restore()
+
+	dev, err := CreateTouchPad(path, []byte("Test TouchPad"), 0, 200, 0, 100, WithTapDuration(time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual touch pad. Last error was: %s\n", err)
+	}
+	defer dev.Close()
+
+	if d := dev.(*vTouchPad).tapDuration; d != time.Millisecond {
+		t.Fatalf("Expected a tap duration of 1ms, but got %v", d)
+	}
+}
+
+func TestGetPositionReturnsLastMoveTo(t *testing.T) {
+	file, stop := recordEvents(t)
+	defer stop()
+	dev := &vTouchPad{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	for _, pos := range [][2]int32{{100, 250}, {0, 0}, {1024, 768}} {
+		err := dev.MoveTo(pos[0], pos[1])
+		if err != nil {
+			t.Fatalf("Failed to move cursor. Last error was: %s\n", err)
+		}
+		x, y := dev.GetPosition()
+		if x != pos[0] || y != pos[1] {
+			t.Fatalf("Expected position %v, but got [%d %d]", pos, x, y)
+		}
+	}
+}
+
+func TestGetPositionIsUnchangedByFailedMove(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := &vTouchPad{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	err := dev.MoveTo(10, 20)
+	if err != nil {
+		t.Fatalf("Failed to move cursor. Last error was: %s\n", err)
+	}
+	stop()
+
+	err = dev.MoveTo(30, 40)
+	if err == nil {
+		t.Fatalf("Expected MoveTo to fail on a closed device, but got no error.")
+	}
+	x, y := dev.GetPosition()
+	if x != 10 || y != 20 {
+		t.Fatalf("Expected position [10 20], but got [%d %d]", x, y)
+	}
+}
+
+func TestGetPositionIsSafeForConcurrentMoves(t *testing.T) {
+	file, stop := recordEvents(t)
+	defer stop()
+	dev := &vTouchPad{deviceBase: deviceBase{report: newReportBuilder(file)}, maxX: 100, maxY: 100}
+
+	var wg sync.WaitGroup
+	for i := int32(0); i < 4; i++ {
+		wg.Add(1)
+		go func(i int32) {
+			defer wg.Done()
+			for j := int32(0); j < 50; j++ {
+				_ = dev.MoveTo(i, i)
+				_, _ = dev.GetPosition()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	x, y := dev.GetPosition()
+	if x != y || x < 0 || x > 3 {
+		t.Fatalf("Expected the position to be one of the moves, but got [%d %d]", x, y)
+	}
+}
+
+func TestMoveToPixelMapsMidpointToMidpoint(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := &vTouchPad{
+		deviceBase:   deviceBase{report: newReportBuilder(file)},
+		minX:         0,
+		maxX:         3840,
+		minY:         100,
+		maxY:         2260,
+		screenWidth:  1921,
+		screenHeight: 1081,
+	}
+
+	err := dev.MoveToPixel(960, 540)
+	if err != nil {
+		t.Fatalf("Failed to move to pixel. Last error was: %s\n", err)
+	}
+	err = dev.MoveToPixel(1920, 1080)
+	if err != nil {
+		t.Fatalf("Failed to move to pixel. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absX, Value: 1920},
+		{Type: evAbs, Code: absY, Value: 1180},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absX, Value: 3840},
+		{Type: evAbs, Code: absY, Value: 2260},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestMoveToFractionMapsMidpointToMidpoint(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := &vTouchPad{deviceBase: deviceBase{report: newReportBuilder(file)}, minX: 0, maxX: 1024, minY: 100, maxY: 900}
+
+	err := dev.MoveToFraction(0.5, 0.5)
+	if err != nil {
+		t.Fatalf("Failed to move to fraction. Last error was: %s\n", err)
+	}
+	// fractions outside of [0, 1] are clamped
+	err = dev.MoveToFraction(-0.5, 1.5)
+	if err != nil {
+		t.Fatalf("Failed to move to fraction. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absX, Value: 512},
+		{Type: evAbs, Code: absY, Value: 500},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absX, Value: 0},
+		{Type: evAbs, Code: absY, Value: 900},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestSetAbsAxisEmitsOnlyTheGivenAxis(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := &vTouchPad{deviceBase: deviceBase{report: newReportBuilder(file)}, minX: 0, maxX: 1024, minY: 0, maxY: 768, x: 10, y: 20}
+
+	err := dev.SetAbsAxis(absY, 300)
+	if err != nil {
+		t.Fatalf("Failed to set absolute axis. Last error was: %s\n", err)
+	}
+	if x, y := dev.GetPosition(); x != 10 || y != 300 {
+		t.Fatalf("Expected position (10, 300), but got (%d, %d)", x, y)
+	}
+
+	err = dev.SetAbsAxis(absMtSlot, 1)
+	if err == nil {
+		t.Fatalf("Expected setting an unregistered axis to fail, but no error was returned.")
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absY, Value: 300},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestSetPressureNormMapsMidpointToMidpoint(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := &vTouchPad{deviceBase: deviceBase{report: newReportBuilder(file)}}
+
+	err := dev.SetPressureNorm(0.5)
+	if err != nil {
+		t.Fatalf("Failed to set pressure. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absPressure, Value: 128},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestAbsAxisOrderFlipsTheOrderOfTheAxes(t *testing.T) {
+	file, stop := recordEvents(t)
+
+	options := newDeviceOptions([]Option{WithAbsAxisOrder(AbsAxisOrderYX)})
+	dev := &vTouchPad{deviceBase: deviceBase{report: newReportBuilder(file)}, maxX: 1024, maxY: 768, absAxisOrder: options.absAxisOrder}
+
+	err := dev.MoveTo(10, 20)
+	if err != nil {
+		t.Fatalf("Failed to move cursor. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absY, Value: 20},
+		{Type: evAbs, Code: absX, Value: 10},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestMoveToPixelFailsOutsideOfScreen(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := &vTouchPad{
+		deviceBase:   deviceBase{report: newReportBuilder(file)},
+		maxX:         1024,
+		maxY:         768,
+		screenWidth:  1920,
+		screenHeight: 1080,
+	}
+
+	for _, pixel := range [][2]int32{{-1, 0}, {1920, 0}, {0, -1}, {0, 1080}} {
+		err := dev.MoveToPixel(pixel[0], pixel[1])
+		if err == nil {
+			t.Fatalf("Expected moving to pixel %v to fail, but got no error.", pixel)
+		}
+	}
+	if events := stop(); len(events) != 0 {
+		t.Fatalf("Expected no events to be written, but got %v", events)
+	}
+}
+
+func TestMoveToPixelFailsWithoutScreenResolution(t *testing.T) {
+	dev := &vTouchPad{maxX: 1024, maxY: 768}
+
+	err := dev.MoveToPixel(1, 1)
+	if err == nil {
+		t.Fatalf("Expected MoveToPixel to fail without a screen resolution, but got no error.")
+	}
+}
+
+func TestTouchPadCreationFailsOnInvalidScreenResolution(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+
+	expected := "invalid screen resolution -1x768"
+	_, err := CreateTouchPad(path, []byte("TouchDevice"), 0, 1024, 0, 768, WithScreenResolution(-1, 768))
+	if err == nil || err.Error() != expected {
+		t.Fatalf("Expected: %s\nActual: %v", expected, err)
+	}
+}
+
+func TestTouchPadClickDelayIsHonored(t *testing.T) {
+	file, stop := recordEvents(t)
+	delay := 30 * time.Millisecond
+	dev := &vTouchPad{deviceBase: deviceBase{report: newReportBuilder(file)}, clickDelay: delay}
+
+	start := time.Now()
+	err := dev.RightClick()
+	if err != nil {
+		t.Fatalf("Failed to issue right click. Last error was: %s\n", err)
+	}
+
+	events := stop()
+	if elapsed := events[2].received.Sub(start); elapsed < delay {
+		t.Fatalf("Expected the release to follow the press after %v, but it followed after %v", delay, elapsed)
+	}
+}
+
+func TestClickAtMovesBeforeClicking(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := &vTouchPad{deviceBase: deviceBase{report: newReportBuilder(file)}, minX: 0, maxX: 1024, minY: 0, maxY: 768}
+
+	err := dev.ClickAt(100, 200)
+	if err != nil {
+		t.Fatalf("Failed to click at position. Last error was: %s\n", err)
+	}
+	if x, y := dev.GetPosition(); x != 100 || y != 200 {
+		t.Fatalf("Expected position to be (100, 200), but got (%d, %d)", x, y)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absX, Value: 100},
+		{Type: evAbs, Code: absY, Value: 200},
+		{Type: evKey, Code: evMouseBtnLeft, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: evMouseBtnLeft, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestTouchPadCreationFailsOnInvertedBounds(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	expected := "invalid range of the y-axis: minimum 768 must be lower than maximum 0"
+	_, err := CreateTouchPad(path, []byte("TouchDevice"), 0, 1024, 768, 0)
+	if err == nil || err.Error() != expected {
+		t.Fatalf("Expected: %s\nActual: %v", expected, err)
+	}
+	if len(*calls) != 0 {
+		t.Fatalf("Expected the device not to be set up, but %d ioctls were issued", len(*calls))
+	}
+}
+
+func TestTouchPadScrollEmitsHiResWheelEvents(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := &vTouchPad{deviceBase: deviceBase{report: newReportBuilder(file)}, hiResScroll: true}
+
+	// two thirds of a detent, followed by another two thirds, which complete the first detent
+	for _, delta := range []int32{80, 80, -40} {
+		err := dev.Scroll(delta)
+		if err != nil {
+			t.Fatalf("Failed to scroll. Last error was: %s\n", err)
+		}
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evRel, Code: relWheelHiRes, Value: 80},
+		{Type: evSyn, Code: synReport},
+		{Type: evRel, Code: relWheelHiRes, Value: 80},
+		{Type: evRel, Code: relWheel, Value: 1},
+		{Type: evSyn, Code: synReport},
+		{Type: evRel, Code: relWheelHiRes, Value: -40},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestTouchPadScrollFailsIfNotEnabled(t *testing.T) {
+	dev := &vTouchPad{}
+	err := dev.Scroll(120)
+	if err == nil {
+		t.Fatalf("Expected scrolling to fail without WithHiResScroll, but got no error.")
+	}
+}
+
+func TestTouchPadRegistersWheelForHiResScroll(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	touchPad, err := CreateTouchPad(path, []byte("TouchDevice"), 0, 1024, 0, 768, WithHiResScroll())
+	if err != nil {
+		t.Fatalf("Failed to create the virtual touch pad. Last error was: %s\n", err)
+	}
+	defer touchPad.Close()
+
+	expected := []uintptr{relWheel, relWheelHiRes}
+	if codes := registeredCodes(*calls, uiSetRelBit); !reflect.DeepEqual(codes, expected) {
+		t.Fatalf("Expected: %v\nActual: %v", expected, codes)
+	}
+}
+
+func TestPathMoveEmitsOneReportPerPoint(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := &vTouchPad{deviceBase: deviceBase{report: newReportBuilder(file)}, minX: 0, maxX: 1024, minY: 0, maxY: 768}
+
+	err := dev.PathMove(Point{X: 10, Y: 20}, Point{X: 30, Y: 40}, Point{X: 50, Y: 60})
+	if err != nil {
+		t.Fatalf("Failed to move along path. Last error was: %s\n", err)
+	}
+	if x, y := dev.GetPosition(); x != 50 || y != 60 {
+		t.Fatalf("Expected position to be (50, 60), but got (%d, %d)", x, y)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absX, Value: 10},
+		{Type: evAbs, Code: absY, Value: 20},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absX, Value: 30},
+		{Type: evAbs, Code: absY, Value: 40},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absX, Value: 50},
+		{Type: evAbs, Code: absY, Value: 60},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestDrawLineTouchesDownAlongTheLine(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := &vTouchPad{deviceBase: deviceBase{report: newReportBuilder(file)}, minX: 0, maxX: 1024, minY: 0, maxY: 768}
+
+	err := dev.DrawLine(10, 10, 100, 50, 2)
+	if err != nil {
+		t.Fatalf("Failed to draw line. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absX, Value: 10},
+		{Type: evAbs, Code: absY, Value: 10},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absPressure, Value: touchPressure},
+		{Type: evKey, Code: evBtnTouch, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absX, Value: 55},
+		{Type: evAbs, Code: absY, Value: 30},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absX, Value: 100},
+		{Type: evAbs, Code: absY, Value: 50},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absPressure, Value: 0},
+		{Type: evKey, Code: evBtnTouch, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestDragToKeepsTouchDownAcrossAllMoves(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := &vTouchPad{deviceBase: deviceBase{report: newReportBuilder(file)}, minX: 0, maxX: 1024, minY: 0, maxY: 768}
+
+	err := dev.MoveTo(0, 100)
+	if err != nil {
+		t.Fatalf("Failed to move cursor. Last error was: %s\n", err)
+	}
+	err = dev.DragTo(500, 100, 5)
+	if err != nil {
+		t.Fatalf("Failed to drag. Last error was: %s\n", err)
+	}
+	if x, y := dev.GetPosition(); x != 500 || y != 100 {
+		t.Fatalf("Expected the drag to end at [500 100], but got [%d %d]", x, y)
+	}
+
+	// the cursor is moved to the start by MoveTo, all moves of the drag must happen while touching
+	events := stop()[3:]
+	touching := false
+	var moves []int32
+	for _, ev := range events {
+		if ev.Type == evKey && ev.Code == evBtnTouch {
+			touching = ev.Value == btnStatePressed
+			continue
+		}
+		if ev.Type == evAbs && ev.Code == absX {
+			if !touching {
+				t.Fatalf("Expected the touch to be down while moving to x=%d", ev.Value)
+			}
+			moves = append(moves, ev.Value)
+		}
+	}
+	if touching {
+		t.Fatalf("Expected the touch to be lifted at the end of the drag")
+	}
+	if expected := []int32{100, 200, 300, 400, 500}; !reflect.DeepEqual(moves, expected) {
+		t.Fatalf("Expected: %v\nActual: %v", expected, moves)
+	}
+}
+
+func TestDrawLineFailsWithoutSteps(t *testing.T) {
+	dev := &vTouchPad{deviceBase: deviceBase{report: newReportBuilder(ioutil.Discard)}, minX: 0, maxX: 1024, minY: 0, maxY: 768}
+
+	err := dev.DrawLine(0, 0, 100, 50, 0)
+	if err == nil {
+		t.Fatalf("Expected drawing a line without steps to fail, but got no error.")
+	}
+}
+
+func TestAbsAxisOrderIsHonoredByClickAtAndReset(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := &vTouchPad{deviceBase: deviceBase{report: newReportBuilder(file)}, maxX: 1024, maxY: 768, absAxisOrder: AbsAxisOrderYX}
+
+	err := dev.ClickAt(10, 20)
+	if err != nil {
+		t.Fatalf("Failed to click. Last error was: %s\n", err)
+	}
+	err = dev.Reset()
+	if err != nil {
+		t.Fatalf("Failed to reset. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absY, Value: 20},
+		{Type: evAbs, Code: absX, Value: 10},
+		{Type: evKey, Code: evMouseBtnLeft, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: evMouseBtnLeft, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+		{Type: evAbs, Code: absY, Value: 384},
+		{Type: evAbs, Code: absX, Value: 512},
+		{Type: evAbs, Code: absPressure, Value: 0},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
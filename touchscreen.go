@@ -0,0 +1,211 @@
+package uinput
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// A TouchScreen is an input device whose coordinates correspond to positions on the screen (INPUT_PROP_DIRECT).
+// Unlike the TouchPad, it has no buttons: touching the screen is reported using BTN_TOUCH only, along with the
+// position of the touch, which is what consumers like libinput expect from touchscreens.
+type TouchScreen interface {
+	// Tap will simulate a short touch at the given position.
+	Tap(x int32, y int32) error
+
+	// TouchDown will simulate a touch at the given position, which lasts until TouchUp is invoked. Calling it
+	// again while touching moves the touch to the new position.
+	TouchDown(x int32, y int32) error
+
+	// TouchUp will end the touch started by TouchDown.
+	TouchUp() error
+
+	// DrawLine will touch down at the start position, move along a straight line to the end position in the given
+	// number of steps, issuing one report per step, and lift the touch again. The start and end positions are hit
+	// exactly.
+	DrawLine(x0, y0, x1, y1 int32, steps int) error
+
+	// FetchSyspath will return the syspath to the device file.
+	FetchSyspath() (string, error)
+
+	// FetchSyspathContext works like FetchSyspath, but retries until the syspath is available or the context is
+	// done. In the latter case, the last error is returned along with the error of the context.
+	FetchSyspathContext(ctx context.Context) (string, error)
+
+	// Reset will end the touch and move its position to the center of the screen (see Resetter).
+	Reset() error
+
+	io.Closer
+}
+
+type vTouchScreen struct {
+	deviceBase
+	// the range of the axes
+	minX, maxX, minY, maxY int32
+	axisPolicy             AxisPolicy
+	// tapDuration is the time between touch down and touch up of a tap, or zero for tapHoldDuration
+	tapDuration time.Duration
+}
+
+// CreateTouchScreen will create a new touchscreen device. Note that you will need to define the x and y-axis
+// boundaries (min and max), which are mapped onto the screen by the consumer.
+func CreateTouchScreen(path string, name []byte, minX int32, maxX int32, minY int32, maxY int32, opts ...Option) (TouchScreen, error) {
+	err := validateDevicePath(path)
+	if err != nil {
+		return nil, err
+	}
+	err = validateUinputName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	options := newDeviceOptions(opts)
+	fd, err := createTouchScreen(path, name, minX, maxX, minY, maxY, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return vTouchScreen{
+		deviceBase: deviceBase{
+			name:       name,
+			deviceFile: fd,
+			report:     newReportBuilderWithOptions(fd, name, options.withoutReportInterval()),
+		},
+		minX:        minX,
+		maxX:        maxX,
+		minY:        minY,
+		maxY:        maxY,
+		axisPolicy:  options.axisPolicy,
+		tapDuration: options.tapDuration,
+	}, nil
+}
+
+// Tap will report the position along with BTN_TOUCH=1, followed by BTN_TOUCH=0 shortly after, each within a
+// report of its own.
+func (vScreen vTouchScreen) Tap(x int32, y int32) error {
+	err := vScreen.TouchDown(x, y)
+	if err != nil {
+		return fmt.Errorf("failed to issue the touch down event of the tap: %w", err)
+	}
+
+	time.Sleep(tapHoldTime(vScreen.tapDuration))
+
+	err = vScreen.TouchUp()
+	if err != nil {
+		return fmt.Errorf("failed to issue the touch up event of the tap: %w", err)
+	}
+	return nil
+}
+
+// TouchDown will report the position along with BTN_TOUCH=1 in a single report.
+func (vScreen vTouchScreen) TouchDown(x int32, y int32) error {
+	x, err := clampAxis(x, vScreen.minX, vScreen.maxX, vScreen.axisPolicy)
+	if err != nil {
+		return fmt.Errorf("failed to move along the x-axis: %w", err)
+	}
+	y, err = clampAxis(y, vScreen.minY, vScreen.maxY, vScreen.axisPolicy)
+	if err != nil {
+		return fmt.Errorf("failed to move along the y-axis: %w", err)
+	}
+
+	events := append(absEvents(x, y), inputEvent{Type: evKey, Code: evBtnTouch, Value: btnStatePressed})
+	err = vScreen.report.send(events...)
+	if err != nil {
+		return fmt.Errorf("failed to write touch event to device file: %w", err)
+	}
+	return nil
+}
+
+func (vScreen vTouchScreen) TouchUp() error {
+	return sendBtnEvent(vScreen.report, []int{evBtnTouch}, btnStateReleased)
+}
+
+func (vScreen vTouchScreen) DrawLine(x0, y0, x1, y1 int32, steps int) error {
+	points, err := linePoints(x0, y0, x1, y1, steps)
+	if err != nil {
+		return err
+	}
+
+	for i, p := range points {
+		err = vScreen.TouchDown(p.X, p.Y)
+		if err != nil {
+			_ = vScreen.TouchUp()
+			return fmt.Errorf("failed to move to point %d of the line: %w", i, err)
+		}
+	}
+	return vScreen.TouchUp()
+}
+
+// Reset ends the touch and moves its position to the center of the screen (see Resetter).
+func (vScreen vTouchScreen) Reset() error {
+	x, y := axisCenter(vScreen.minX, vScreen.maxX), axisCenter(vScreen.minY, vScreen.maxY)
+	return vScreen.report.reset(absEvents(x, y)...)
+}
+
+func createTouchScreen(path string, name []byte, minX int32, maxX int32, minY int32, maxY int32, options deviceOptions) (fd *os.File, err error) {
+	err = validateAxisRange("x", minX, maxX)
+	if err != nil {
+		return nil, err
+	}
+	err = validateAxisRange("y", minY, maxY)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceFile, err := createDeviceFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not create touchscreen input device: %w", err)
+	}
+
+	err = registerDevice(deviceFile, uintptr(evKey))
+	if err != nil {
+		_ = deviceFile.Close()
+		return nil, fmt.Errorf("failed to register key device: %w", err)
+	}
+	err = ioctl(deviceFile, uiSetKeyBit, uintptr(evBtnTouch))
+	if err != nil {
+		_ = deviceFile.Close()
+		return nil, fmt.Errorf("failed to register button event %v: %w", evBtnTouch, err)
+	}
+
+	err = registerDevice(deviceFile, uintptr(evAbs))
+	if err != nil {
+		_ = deviceFile.Close()
+		return nil, fmt.Errorf("failed to register absolute axis input device: %w", err)
+	}
+	for _, event := range []int{absX, absY} {
+		err = ioctl(deviceFile, uiSetAbsBit, uintptr(event))
+		if err != nil {
+			_ = deviceFile.Close()
+			return nil, fmt.Errorf("failed to register absolute axis event %v: %w", event, err)
+		}
+	}
+
+	err = ioctl(deviceFile, uiSetPropBit, uintptr(inputPropDirect))
+	if err != nil {
+		_ = deviceFile.Close()
+		return nil, fmt.Errorf("failed to register direct input property: %w", err)
+	}
+
+	var absMin [absSize]int32
+	absMin[absX] = minX
+	absMin[absY] = minY
+
+	var absMax [absSize]int32
+	absMax[absX] = maxX
+	absMax[absY] = maxY
+
+	return createUsbDevice(deviceFile,
+		uinputUserDev{
+			Name: toUinputName(name),
+			ID: inputID{
+				Bustype: busUsb,
+				Vendor:  0x4711,
+				Product: 0x081c,
+				Version: 1},
+			Absmin: absMin,
+			Absmax: absMax},
+		options)
+}
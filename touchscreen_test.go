@@ -0,0 +1,103 @@
+package uinput
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestTouchScreenTap(t *testing.T) {
+	dev, err := CreateTouchScreen("/dev/uinput", []byte("Test TouchScreen"), 0, 1024, 0, 768)
+	if err != nil {
+		t.Fatalf("Failed to create the virtual touchscreen. Last error was: %s\n", err)
+	}
+
+	err = dev.Tap(100, 100)
+	if err != nil {
+		t.Fatalf("Failed to tap. Last error was: %s\n", err)
+	}
+
+	err = dev.Close()
+	if err != nil {
+		t.Fatalf("Failed to close device. Last error was: %s\n", err)
+	}
+}
+
+func TestTouchScreenTapUsesBtnTouch(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vTouchScreen{deviceBase: deviceBase{report: newReportBuilder(file)}, minX: 0, maxX: 1024, minY: 0, maxY: 768}
+
+	err := dev.Tap(100, 200)
+	if err != nil {
+		t.Fatalf("Failed to tap. Last error was: %s\n", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evAbs, Code: absX, Value: 100},
+		{Type: evAbs, Code: absY, Value: 200},
+		{Type: evKey, Code: evBtnTouch, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: evBtnTouch, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestTouchScreenDrawLineHitsEndpoints(t *testing.T) {
+	file, stop := recordEvents(t)
+	dev := vTouchScreen{deviceBase: deviceBase{report: newReportBuilder(file)}, minX: 0, maxX: 1024, minY: 0, maxY: 768}
+
+	err := dev.DrawLine(10, 700, 1000, 3, 7)
+	if err != nil {
+		t.Fatalf("Failed to draw line. Last error was: %s\n", err)
+	}
+
+	var positions []Point
+	var touches []int32
+	for _, ev := range stop() {
+		switch {
+		case ev.Type == evAbs && ev.Code == absX:
+			positions = append(positions, Point{X: ev.Value})
+		case ev.Type == evAbs && ev.Code == absY:
+			positions[len(positions)-1].Y = ev.Value
+		case ev.Type == evKey && ev.Code == evBtnTouch:
+			touches = append(touches, ev.Value)
+		}
+	}
+	if len(positions) != 8 {
+		t.Fatalf("Expected 8 positions (start point and 7 steps), but got %d", len(positions))
+	}
+	if first, last := positions[0], positions[len(positions)-1]; first != (Point{10, 700}) || last != (Point{1000, 3}) {
+		t.Fatalf("Expected the line to start at (10, 700) and end at (1000, 3), but got %v and %v", first, last)
+	}
+	if touches[len(touches)-1] != btnStateReleased {
+		t.Fatalf("Expected the touch to be lifted at the end of the line")
+	}
+}
+
+func TestTouchScreenRegistersDirectTouchWithoutButtons(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	dev, err := CreateTouchScreen(path, []byte("Test TouchScreen"), 0, 1024, 0, 768)
+	if err != nil {
+		t.Fatalf("Failed to create the virtual touchscreen. Last error was: %s\n", err)
+	}
+	defer dev.Close()
+
+	if keyBits := registeredCodes(*calls, uiSetKeyBit); !reflect.DeepEqual(keyBits, []uintptr{evBtnTouch}) {
+		t.Fatalf("Expected only BTN_TOUCH to be registered, but got %v", keyBits)
+	}
+	if propBits := registeredCodes(*calls, uiSetPropBit); !reflect.DeepEqual(propBits, []uintptr{inputPropDirect}) {
+		t.Fatalf("Expected INPUT_PROP_DIRECT to be registered, but got %v", propBits)
+	}
+}
+
+func TestTouchScreenCreationFailsOnNonExistentPathName(t *testing.T) {
+	path := "/some/bogus/path"
+	_, err := CreateTouchScreen(path, []byte("TouchScreen"), 0, 1024, 0, 768)
+	if !os.IsNotExist(err) {
+		t.Fatalf("Expected: os.IsNotExist error\nActual: %s", err)
+	}
+}
@@ -76,21 +76,61 @@ package uinput
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 	"unsafe"
 )
 
+// ErrDeviceGone is returned (wrapped) by the methods of a device if the device does no longer exist, for example
+// because it has been removed by the host or because its device file has been closed. Other write errors may be
+// transient. Callers can use errors.Is(err, ErrDeviceGone) to detect this case, e.g. to recreate the device.
+var ErrDeviceGone = errors.New("device is gone")
+
+// ErrWouldBlock is returned (wrapped) by the methods of a device if an event could not be written, since the
+// buffer of the device is full (EAGAIN). This happens if events are written faster than they are consumed; the
+// write may succeed if repeated later on. See WithNonBlocking in order to retry such writes automatically.
+var ErrWouldBlock = errors.New("device buffer is full")
+
+// ModuleNotLoadedError is returned by the Create functions if the uinput device file does not exist or has no
+// driver attached to it, which usually means that the uinput kernel module is not loaded. It wraps the original
+// error, so errors.Is(err, fs.ErrNotExist) keeps working.
+type ModuleNotLoadedError struct {
+	Path string
+	Err  error
+}
+
+func (e *ModuleNotLoadedError) Error() string {
+	return fmt.Sprintf("%s is not available, the uinput module may not be loaded (try \"modprobe uinput\"): %v", e.Path, e.Err)
+}
+
+func (e *ModuleNotLoadedError) Unwrap() error {
+	return e.Err
+}
+
+// moduleError returns a ModuleNotLoadedError if the given error of accessing the device file indicates that the
+// uinput module is missing. This is only assumed for paths of uinput device files (like /dev/uinput), as for any
+// other path it is more likely that it was simply mistyped. Otherwise, the error is returned as it is.
+func moduleError(path string, err error) error {
+	if filepath.Base(path) == "uinput" && (errors.Is(err, syscall.ENOENT) || errors.Is(err, syscall.ENODEV)) {
+		return &ModuleNotLoadedError{Path: path, Err: err}
+	}
+	return err
+}
+
 func validateDevicePath(path string) error {
 	if path == "" {
 		return errors.New("device path must not be empty")
 	}
 	_, err := os.Stat(path)
-	return err
+	return moduleError(path, err)
 }
 
 func validateUinputName(name []byte) error {
@@ -110,8 +150,22 @@ func toUinputName(name []byte) (uinputName [uinputMaxNameSize]byte) {
 }
 
 func createDeviceFile(path string) (fd *os.File, err error) {
-	deviceFile, err := os.OpenFile(path, syscall.O_WRONLY|syscall.O_NONBLOCK, 0660)
+	return openDeviceFile(path, syscall.O_WRONLY)
+}
+
+// createReadableDeviceFile works like createDeviceFile, but opens the device file for reading as well, which is
+// needed in order to receive the events that the kernel sends to the device, like EV_LED.
+func createReadableDeviceFile(path string) (fd *os.File, err error) {
+	return openDeviceFile(path, syscall.O_RDWR)
+}
+
+func openDeviceFile(path string, mode int) (fd *os.File, err error) {
+	deviceFile, err := os.OpenFile(path, mode|syscall.O_NONBLOCK, 0660)
 	if err != nil {
+		var moduleErr *ModuleNotLoadedError
+		if errors.As(moduleError(path, err), &moduleErr) {
+			return nil, moduleErr
+		}
 		return nil, errors.New("could not open device file")
 	}
 	return deviceFile, err
@@ -123,43 +177,228 @@ func registerDevice(deviceFile *os.File, evType uintptr) error {
 		defer deviceFile.Close()
 		err = releaseDevice(deviceFile)
 		if err != nil {
-			return fmt.Errorf("failed to close device: %v", err)
+			return fmt.Errorf("failed to close device: %w", err)
 		}
-		return fmt.Errorf("invalid file handle returned from ioctl: %v", err)
+		return fmt.Errorf("invalid file handle returned from ioctl: %w", err)
 	}
 	return nil
 }
 
-func createUsbDevice(deviceFile *os.File, dev uinputUserDev) (fd *os.File, err error) {
-	buf := new(bytes.Buffer)
-	err = binary.Write(buf, binary.LittleEndian, dev)
+// createUsbDevice configures the device as described by dev and creates it. On kernels that support it, the
+// device is configured using UI_DEV_SETUP and UI_ABS_SETUP, otherwise dev is written to the device file.
+func createUsbDevice(deviceFile *os.File, dev uinputUserDev, options deviceOptions) (fd *os.File, err error) {
+	if useSetup(deviceFile, options.setupMethod) {
+		err = setupDevice(deviceFile, dev, options.absResolution)
+	} else {
+		err = writeUserDev(deviceFile, dev)
+	}
 	if err != nil {
 		_ = deviceFile.Close()
-		return nil, fmt.Errorf("failed to write user device buffer: %v", err)
+		return nil, err
 	}
-	_, err = deviceFile.Write(buf.Bytes())
+
+	phys := devicePhys(dev, options)
+	err = setPhys(deviceFile, phys)
 	if err != nil {
 		_ = deviceFile.Close()
-		return nil, fmt.Errorf("failed to write uidev struct to device file: %v", err)
+		return nil, err
+	}
+
+	if options.keepAlive {
+		err = registerKeepAlive(deviceFile)
+		if err != nil {
+			_ = deviceFile.Close()
+			return nil, err
+		}
 	}
 
-	err = ioctl(deviceFile, uiDevCreate, uintptr(0))
+	err = issueDevCreate(deviceFile, options)
 	if err != nil {
 		_ = deviceFile.Close()
-		return nil, fmt.Errorf("failed to create device: %v", err)
+		if options.logger != nil {
+			options.logger.Error("failed to create input device", "device", uinputName(dev), "error", err)
+		}
+		return nil, fmt.Errorf("failed to create device: %w", err)
 	}
 
 	time.Sleep(time.Millisecond * 200)
 
+	trackDevice(deviceFile)
+	if options.logger != nil {
+		options.logger.Info("created input device", "device", uinputName(dev),
+			"vendor", dev.ID.Vendor, "product", dev.ID.Product, "phys", phys)
+	}
 	return deviceFile, err
 }
 
-func closeDevice(deviceFile *os.File) (err error) {
-	err = releaseDevice(deviceFile)
+// physCounter numbers the devices of this process, in order to generate unique phys strings.
+var physCounter atomic.Uint32
+
+// devicePhys returns the phys string of the device, which is either set by WithPhys or generated from the name of
+// the device and a counter, like "uinput/virtual/<name>/input0".
+func devicePhys(dev uinputUserDev, options deviceOptions) string {
+	if options.phys != "" {
+		return options.phys
+	}
+	return fmt.Sprintf("uinput/virtual/%s/input%d", uinputName(dev), physCounter.Add(1)-1)
+}
+
+// uinputName returns the name of the device without the null bytes that pad it.
+func uinputName(dev uinputUserDev) string {
+	return strings.TrimRight(string(dev.Name[:]), "\x00")
+}
+
+// setPhys sets the phys string of the device using UI_SET_PHYS, which needs to be done before the device is created.
+func setPhys(deviceFile *os.File, phys string) error {
+	buf := append([]byte(phys), 0)
+	err := ioctl(deviceFile, uiSetPhys, uintptr(unsafe.Pointer(&buf[0])))
 	if err != nil {
-		return fmt.Errorf("failed to close device: %v", err)
+		return fmt.Errorf("failed to set phys %q: %w", phys, err)
 	}
-	return deviceFile.Close()
+	return nil
+}
+
+// issueDevCreate issues UI_DEV_CREATE. Transient failures are retried as configured by WithCreateRetry, doubling
+// the backoff after every attempt.
+func issueDevCreate(deviceFile *os.File, options deviceOptions) error {
+	backoff := options.createBackoff
+	err := ioctl(deviceFile, uiDevCreate, uintptr(0))
+	for attempt := 1; err != nil && attempt < options.createAttempts && isTransientError(err); attempt++ {
+		time.Sleep(backoff)
+		backoff *= 2
+		err = ioctl(deviceFile, uiDevCreate, uintptr(0))
+	}
+	return err
+}
+
+// isTransientError reports whether the given error of an ioctl may go away when the request is repeated.
+func isTransientError(err error) bool {
+	return errors.Is(err, syscall.EBUSY) || errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EINTR)
+}
+
+// writeUserDev configures the device by writing the legacy uinput_user_dev struct to the device file.
+func writeUserDev(deviceFile *os.File, dev uinputUserDev) error {
+	buf := new(bytes.Buffer)
+	err := binary.Write(buf, binary.LittleEndian, dev)
+	if err != nil {
+		return fmt.Errorf("failed to write user device buffer: %w", err)
+	}
+	_, err = deviceFile.Write(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to write uidev struct to device file: %w", err)
+	}
+	return nil
+}
+
+// setupDevice configures the device using UI_ABS_SETUP for every axis that has a range, fuzz, flat value or
+// resolution, followed by UI_DEV_SETUP.
+func setupDevice(deviceFile *os.File, dev uinputUserDev, resolution [absSize]int32) error {
+	for _, absSetup := range absSetups(dev, resolution) {
+		err := ioctl(deviceFile, uiAbsSetup, uintptr(unsafe.Pointer(&absSetup)))
+		if err != nil {
+			return fmt.Errorf("failed to set up absolute axis %v: %w", absSetup.Code, err)
+		}
+	}
+
+	setup := uinputSetup{ID: dev.ID, Name: dev.Name, EffectsMax: dev.EffectsMax}
+	err := ioctl(deviceFile, uiDevSetup, uintptr(unsafe.Pointer(&setup)))
+	if err != nil {
+		return fmt.Errorf("failed to set up device: %w", err)
+	}
+	return nil
+}
+
+// absSetups returns the UI_ABS_SETUP requests for all axes of the device that need to be set up.
+func absSetups(dev uinputUserDev, resolution [absSize]int32) []uinputAbsSetup {
+	var setups []uinputAbsSetup
+	for code := 0; code < absSize; code++ {
+		if dev.Absmin[code] == 0 && dev.Absmax[code] == 0 && dev.Absfuzz[code] == 0 && dev.Absflat[code] == 0 &&
+			resolution[code] == 0 {
+			continue
+		}
+		setups = append(setups, uinputAbsSetup{
+			Code: uint16(code),
+			Absinfo: inputAbsinfo{
+				Minimum:    dev.Absmin[code],
+				Maximum:    dev.Absmax[code],
+				Fuzz:       dev.Absfuzz[code],
+				Flat:       dev.Absflat[code],
+				Resolution: resolution[code]}})
+	}
+	return setups
+}
+
+// KernelSupportsSetup reports whether the uinput module of the running kernel supports the UI_DEV_SETUP and
+// UI_ABS_SETUP requests (available since Linux 4.5). Devices are created using these requests if available,
+// falling back to the legacy uinput_user_dev struct otherwise. False is returned if /dev/uinput can not be opened.
+func KernelSupportsSetup() bool {
+	deviceFile, err := createDeviceFile("/dev/uinput")
+	if err != nil {
+		return false
+	}
+	defer deviceFile.Close()
+	return supportsSetup(deviceFile)
+}
+
+// A SetupMethod determines how a device is configured upon creation (see WithSetupMethod).
+type SetupMethod int
+
+const (
+	// SetupAuto uses the UI_DEV_SETUP and UI_ABS_SETUP requests if the kernel supports them, and the legacy
+	// uinput_user_dev struct otherwise. This is the default.
+	SetupAuto SetupMethod = iota
+	// SetupLegacy always writes the legacy uinput_user_dev struct to the device file.
+	SetupLegacy
+	// SetupIoctl always uses the UI_DEV_SETUP and UI_ABS_SETUP requests, which fails on kernels older than 4.5.
+	SetupIoctl
+)
+
+// useSetup reports whether the device is to be configured using the setup requests.
+func useSetup(deviceFile *os.File, method SetupMethod) bool {
+	switch method {
+	case SetupLegacy:
+		return false
+	case SetupIoctl:
+		return true
+	}
+	return supportsSetup(deviceFile)
+}
+
+func supportsSetup(deviceFile *os.File) bool {
+	version, err := uinputVersion(deviceFile)
+	return err == nil && version >= uinputSetupVersion
+}
+
+// uinputVersion returns the version of the uinput module (UI_GET_VERSION). It is a variable, so that tests can
+// simulate different kernel versions.
+var uinputVersion = func(deviceFile *os.File) (uint32, error) {
+	var version uint32
+	err := ioctl(deviceFile, uiGetVersion, uintptr(unsafe.Pointer(&version)))
+	return version, err
+}
+
+// closeDevice destroys the device and closes the device file. Both steps are attempted independently of each other,
+// so that the device file is closed even if destroying the device fails. The errors of both steps are joined.
+func closeDevice(deviceFile *os.File) error {
+	untrackDevice(deviceFile)
+	releaseGrab(deviceFile)
+
+	var destroyErr error
+	err := releaseDevice(deviceFile)
+	if err != nil {
+		destroyErr = fmt.Errorf("failed to close device: %w", err)
+	}
+	return errors.Join(destroyErr, deviceFile.Close())
+}
+
+// closeDeviceWithReport writes the events that are still pending in the report of the device (e.g. raw events sent
+// without a final SYN_REPORT, or updates deferred by a report interval) before closing the device.
+func closeDeviceWithReport(report *reportBuilder, deviceFile *os.File) error {
+	err := report.close()
+	if err != nil {
+		err = fmt.Errorf("failed to flush pending events: %w", err)
+	}
+	return errors.Join(err, report.heldKeys(), closeDevice(deviceFile))
 }
 
 func releaseDevice(deviceFile *os.File) (err error) {
@@ -176,50 +415,64 @@ func fetchSyspath(deviceFile *os.File) (string, error) {
 	return sysInputDir, err
 }
 
+// A SyspathContextFetcher waits for the syspath of a device, which may not be available right after the device has
+// been created. All devices of this package implement SyspathContextFetcher.
+type SyspathContextFetcher interface {
+	// FetchSyspathContext works like FetchSyspath, but retries until the syspath is available or the context is
+	// done. In the latter case, the last error is returned along with the error of the context.
+	FetchSyspathContext(ctx context.Context) (string, error)
+}
+
+// fetchSyspathContext calls fetchSyspath until it succeeds or the context is done, waiting for syspathPollInterval
+// between two attempts.
+func fetchSyspathContext(ctx context.Context, deviceFile *os.File) (string, error) {
+	for {
+		path, err := fetchSyspath(deviceFile)
+		if err == nil {
+			return path, nil
+		}
+
+		timer := time.NewTimer(syspathPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", fmt.Errorf("failed to fetch syspath: %w", errors.Join(err, ctx.Err()))
+		case <-timer.C:
+		}
+	}
+}
+
 // Note that mice and touch pads do have buttons as well. Therefore, this function is used
 // by all currently available devices and resides in the main source file.
-func sendBtnEvent(deviceFile *os.File, keys []int, btnState int) (err error) {
+func sendBtnEvent(report *reportBuilder, keys []int, btnState int) (err error) {
+	events := make([]inputEvent, 0, len(keys))
 	for _, key := range keys {
-		buf, err := inputEventToBuffer(inputEvent{
+		events = append(events, inputEvent{
 			Time:  syscall.Timeval{Sec: 0, Usec: 0},
 			Type:  evKey,
 			Code:  uint16(key),
 			Value: int32(btnState)})
-		if err != nil {
-			return fmt.Errorf("key event could not be set: %v", err)
-		}
-		_, err = deviceFile.Write(buf)
-		if err != nil {
-			return fmt.Errorf("writing btnEvent structure to the device file failed: %v", err)
-		}
 	}
-	return syncEvents(deviceFile)
-}
-
-func syncEvents(deviceFile *os.File) (err error) {
-	buf, err := inputEventToBuffer(inputEvent{
-		Time:  syscall.Timeval{Sec: 0, Usec: 0},
-		Type:  evSyn,
-		Code:  uint16(synReport),
-		Value: 0})
+	err = report.send(events...)
 	if err != nil {
-		return fmt.Errorf("writing sync event failed: %v", err)
+		return fmt.Errorf("writing btnEvent structure to the device file failed: %w", err)
 	}
-	_, err = deviceFile.Write(buf)
-	return err
+	return nil
 }
 
 func inputEventToBuffer(iev inputEvent) (buffer []byte, err error) {
 	buf := bytes.NewBuffer(make([]byte, 0, 24))
 	err = binary.Write(buf, binary.LittleEndian, iev)
 	if err != nil {
-		return nil, fmt.Errorf("failed to write input event to buffer: %v", err)
+		return nil, fmt.Errorf("failed to write input event to buffer: %w", err)
 	}
 	return buf.Bytes(), nil
 }
 
+// ioctl issues the given request on the device file. It is a variable, so that tests can replace it in order
+// to verify the issued requests without the need for an actual uinput device.
 // original function taken from: https://github.com/tianon/debian-golang-pty/blob/master/ioctl.go
-func ioctl(deviceFile *os.File, cmd, ptr uintptr) error {
+var ioctl = func(deviceFile *os.File, cmd, ptr uintptr) error {
 	_, _, errorCode := syscall.Syscall(syscall.SYS_IOCTL, deviceFile.Fd(), cmd, ptr)
 	if errorCode != 0 {
 		return errorCode
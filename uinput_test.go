@@ -1,11 +1,142 @@
 package uinput
 
 import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io/fs"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
+	"unsafe"
 )
 
+var (
+	_ SyspathContextFetcher = (*vKeyboard)(nil)
+	_ SyspathContextFetcher = vMouse{}
+	_ SyspathContextFetcher = (*vTouchPad)(nil)
+	_ SyspathContextFetcher = vMultiTouch{}
+	_ SyspathContextFetcher = noopKeyboard{}
+	_ SyspathContextFetcher = noopMouse{}
+	_ SyspathContextFetcher = noopTouchPad{}
+	_ SyspathContextFetcher = noopMultiTouch{}
+)
+
+type recordedEvent struct {
+	inputEvent
+	received time.Time
+}
+
+// recordEvents returns a pipe that may be used in place of the uinput device file, along with a function that
+// closes the pipe and returns all events that have been written to it. This allows verifying the emitted events
+// without the need for an actual uinput device.
+func recordEvents(t *testing.T) (*os.File, func() []recordedEvent) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to setup test. Unable to create pipe: %v", err)
+	}
+
+	done := make(chan []recordedEvent)
+	go func() {
+		var events []recordedEvent
+		for {
+			var ev inputEvent
+			err := binary.Read(r, binary.LittleEndian, &ev)
+			if err != nil {
+				break
+			}
+			events = append(events, recordedEvent{inputEvent: ev, received: time.Now()})
+		}
+		_ = r.Close()
+		done <- events
+	}()
+
+	return w, func() []recordedEvent {
+		_ = w.Close()
+		return <-done
+	}
+}
+
+// assertEvents verifies that the type, code and value of the recorded events match the expected ones.
+func assertEvents(t *testing.T, expected []inputEvent, actual []recordedEvent) {
+	t.Helper()
+	if len(expected) != len(actual) {
+		t.Fatalf("Expected %d events, but got %d: %v", len(expected), len(actual), actual)
+	}
+	for i := range expected {
+		e, a := expected[i], actual[i]
+		if e.Type != a.Type || e.Code != a.Code || e.Value != a.Value {
+			t.Fatalf("Event %d: expected type %d, code %d, value %d, but got type %d, code %d, value %d",
+				i, e.Type, e.Code, e.Value, a.Type, a.Code, a.Value)
+		}
+	}
+}
+
+type ioctlCall struct {
+	cmd uintptr
+	ptr uintptr
+}
+
+// fakeIoctl replaces the ioctl function with one that records all requests and lets them succeed, unless the
+// given function (which may be nil) returns an error for the request. The returned function restores the real ioctl.
+func fakeIoctl(fail func(cmd uintptr) error) (*[]ioctlCall, func()) {
+	original := ioctl
+	calls := &[]ioctlCall{}
+	ioctl = func(deviceFile *os.File, cmd, ptr uintptr) error {
+		*calls = append(*calls, ioctlCall{cmd: cmd, ptr: ptr})
+		if fail != nil {
+			return fail(cmd)
+		}
+		return nil
+	}
+	return calls, func() { ioctl = original }
+}
+
+// fakeDevicePath returns the path of a temporary file that may be used in place of /dev/uinput along with
+// fakeIoctl. The returned function removes the file.
+func fakeDevicePath(t *testing.T) (string, func()) {
+	file, err := ioutil.TempFile(os.TempDir(), "uinput-fake-device-")
+	if err != nil {
+		t.Fatalf("Failed to setup test. Unable to create tempfile: %v", err)
+	}
+	_ = file.Close()
+	return file.Name(), func() { _ = os.Remove(file.Name()) }
+}
+
+// readUserDev returns the uinput_user_dev struct that has been written to the given fake device file, which is
+// how devices are set up if UI_DEV_SETUP is not available (see fakeIoctl).
+func readUserDev(t *testing.T, path string) uinputUserDev {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open the fake device file. Last error was: %s\n", err)
+	}
+	defer file.Close()
+
+	var userDev uinputUserDev
+	err = binary.Read(file, binary.LittleEndian, &userDev)
+	if err != nil {
+		t.Fatalf("Failed to read the device setup. Last error was: %s\n", err)
+	}
+	return userDev
+}
+
+// registeredCodes returns the codes that have been registered using the given ioctl request.
+func registeredCodes(calls []ioctlCall, cmd uintptr) []uintptr {
+	var codes []uintptr
+	for _, call := range calls {
+		if call.cmd == cmd {
+			codes = append(codes, call.ptr)
+		}
+	}
+	return codes
+}
+
 func TestValidateDevicePathEmptyPathPanics(t *testing.T) {
 	expected := "device path must not be empty"
 	err := validateDevicePath("")
@@ -22,6 +153,30 @@ func TestValidateDevicePathInvalidPathPanics(t *testing.T) {
 	}
 }
 
+func TestMissingUinputDeviceFileSuggestsLoadingModule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "uinput")
+
+	err := validateDevicePath(path)
+	var moduleErr *ModuleNotLoadedError
+	if !errors.As(err, &moduleErr) {
+		t.Fatalf("Expected a ModuleNotLoadedError, but got: %v", err)
+	}
+	if moduleErr.Path != path || !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected the error to refer to %s and wrap the original error, but got: %v", path, err)
+	}
+	if !strings.Contains(err.Error(), "modprobe uinput") {
+		t.Fatalf("Expected the error to suggest loading the module, but got: %v", err)
+	}
+}
+
+func TestMissingDeviceFileWithOtherNameIsReturnedAsIs(t *testing.T) {
+	err := validateDevicePath(filepath.Join(t.TempDir(), "device"))
+	var moduleErr *ModuleNotLoadedError
+	if errors.As(err, &moduleErr) || !os.IsNotExist(err) {
+		t.Fatalf("Expected: os.IsNotExist error\nActual: %v", err)
+	}
+}
+
 func TestValidateUinputNameEmptyNamePanics(t *testing.T) {
 	expected := "device name may not be empty"
 	err := validateUinputName(nil)
@@ -40,7 +195,7 @@ func TestFailedDeviceFileCreationGeneratesError(t *testing.T) {
 
 func TestNonExistentDeviceFileCausesError(t *testing.T) {
 	expected := "failed to write uidev struct to device file:"
-	_, err := createUsbDevice(nil, uinputUserDev{})
+	_, err := createUsbDevice(nil, uinputUserDev{}, deviceOptions{})
 	if err == nil {
 		t.Fatalf("expected error, but got none")
 	}
@@ -48,3 +203,424 @@ func TestNonExistentDeviceFileCausesError(t *testing.T) {
 		t.Fatalf("got '%v', but expected '%v'", err.Error(), expected)
 	}
 }
+
+func TestCloseClosesDeviceFileEvenIfDestroyFails(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	destroyErr := errors.New("destroy failed")
+	_, restore := fakeIoctl(func(cmd uintptr) error {
+		if cmd == uiDevDestroy {
+			return destroyErr
+		}
+		return nil
+	})
+	defer restore()
+
+	deviceFile, err := createDeviceFile(path)
+	if err != nil {
+		t.Fatalf("Failed to setup test. Unable to open device file: %v", err)
+	}
+
+	err = closeDevice(deviceFile)
+	if !errors.Is(err, destroyErr) {
+		t.Fatalf("Expected the error of the destroy step to be returned, but got: %v", err)
+	}
+	_, err = deviceFile.Write([]byte{0})
+	if !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("Expected device file to be closed, but write returned: %v", err)
+	}
+}
+
+func TestCloseJoinsErrorsOfAllSteps(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	destroyErr := errors.New("destroy failed")
+	_, restore := fakeIoctl(func(cmd uintptr) error {
+		return destroyErr
+	})
+	defer restore()
+
+	deviceFile, err := createDeviceFile(path)
+	if err != nil {
+		t.Fatalf("Failed to setup test. Unable to open device file: %v", err)
+	}
+	_ = deviceFile.Close()
+
+	err = closeDevice(deviceFile)
+	if !errors.Is(err, destroyErr) || !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("Expected errors of both steps to be returned, but got: %v", err)
+	}
+}
+
+func TestCloseFlushesPendingEventsBeforeDestroy(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	defer fakeUinputVersion(uinputSetupVersion)()
+	sizeAtDestroy := int64(-1)
+	_, restore := fakeIoctl(func(cmd uintptr) error {
+		if cmd == uiDevDestroy {
+			info, _ := os.Stat(path)
+			sizeAtDestroy = info.Size()
+		}
+		return nil
+	})
+	defer restore()
+
+	keyboard, err := CreateKeyboard(path, []byte("Test Keyboard"), WithKeys(KeyA))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual keyboard. Last error was: %s\n", err)
+	}
+	// no SYN_REPORT is sent, so the event is left pending
+	err = keyboard.(EventSender).SendEvent(evKey, KeyA, btnStatePressed)
+	if err != nil {
+		t.Fatalf("Failed to send event. Last error was: %s\n", err)
+	}
+
+	err = keyboard.Close()
+	if err != nil {
+		t.Fatalf("Failed to close device. Last error was: %s\n", err)
+	}
+	// the pending event, followed by a SYN_REPORT
+	if expected := int64(2 * binary.Size(inputEvent{})); sizeAtDestroy != expected {
+		t.Fatalf("Expected %d bytes to be written before the device is destroyed, but got %d", expected, sizeAtDestroy)
+	}
+}
+
+// fakeUinputVersion makes the device creation assume the given version of the uinput module. The returned
+// function restores the original behavior.
+func fakeUinputVersion(version uint32) func() {
+	original := uinputVersion
+	uinputVersion = func(deviceFile *os.File) (uint32, error) {
+		return version, nil
+	}
+	return func() { uinputVersion = original }
+}
+
+func TestDeviceIsSetUpUsingIoctlsIfSupported(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+	defer fakeUinputVersion(uinputSetupVersion)()
+
+	touchPad, err := CreateTouchPad(path, []byte("Test TouchPad"), 0, 1024, 0, 768)
+	if err != nil {
+		t.Fatalf("Failed to create the virtual touch pad. Last error was: %s\n", err)
+	}
+	defer touchPad.Close()
+
+	if n := len(registeredCodes(*calls, uiDevSetup)); n != 1 {
+		t.Fatalf("Expected UI_DEV_SETUP to be issued once, but it was issued %d times", n)
+	}
+	// absX, absY and absPressure have a range
+	if n := len(registeredCodes(*calls, uiAbsSetup)); n != 3 {
+		t.Fatalf("Expected UI_ABS_SETUP to be issued for 3 axes, but it was issued %d times", n)
+	}
+	if info, _ := os.Stat(path); info.Size() != 0 {
+		t.Fatalf("Expected the legacy device struct not to be written, but the device file has %d bytes", info.Size())
+	}
+}
+
+func TestDeviceIsSetUpUsingUserDevOnOldKernels(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+	defer fakeUinputVersion(uinputSetupVersion - 1)()
+
+	touchPad, err := CreateTouchPad(path, []byte("Test TouchPad"), 0, 1024, 0, 768)
+	if err != nil {
+		t.Fatalf("Failed to create the virtual touch pad. Last error was: %s\n", err)
+	}
+	defer touchPad.Close()
+
+	if n := len(registeredCodes(*calls, uiDevSetup)) + len(registeredCodes(*calls, uiAbsSetup)); n != 0 {
+		t.Fatalf("Expected no setup ioctls on old kernels, but %d were issued", n)
+	}
+	if info, _ := os.Stat(path); info.Size() != int64(binary.Size(uinputUserDev{})) {
+		t.Fatalf("Expected the legacy device struct to be written, but the device file has %d bytes", info.Size())
+	}
+}
+
+func TestSetupMethodOverridesKernelVersion(t *testing.T) {
+	tests := []struct {
+		method   SetupMethod
+		version  uint32
+		useIoctl bool
+	}{
+		{method: SetupAuto, version: uinputSetupVersion, useIoctl: true},
+		{method: SetupAuto, version: uinputSetupVersion - 1, useIoctl: false},
+		{method: SetupLegacy, version: uinputSetupVersion, useIoctl: false},
+		{method: SetupIoctl, version: uinputSetupVersion - 1, useIoctl: true},
+	}
+
+	for _, test := range tests {
+		path, remove := fakeDevicePath(t)
+		calls, restore := fakeIoctl(nil)
+		restoreVersion := fakeUinputVersion(test.version)
+
+		touchPad, err := CreateTouchPad(path, []byte("Test TouchPad"), 0, 1024, 0, 768, WithSetupMethod(test.method))
+		if err != nil {
+			t.Fatalf("Failed to create the virtual touch pad. Last error was: %s\n", err)
+		}
+		_ = touchPad.Close()
+
+		usedIoctl := len(registeredCodes(*calls, uiDevSetup)) == 1
+		info, _ := os.Stat(path)
+		wroteUserDev := info.Size() == int64(binary.Size(uinputUserDev{}))
+		if usedIoctl != test.useIoctl || wroteUserDev == test.useIoctl {
+			t.Fatalf("Expected method %d on version %d to use the ioctl path: %t, but UI_DEV_SETUP issued: %t, "+
+				"struct written: %t", test.method, test.version, test.useIoctl, usedIoctl, wroteUserDev)
+		}
+
+		restoreVersion()
+		restore()
+		remove()
+	}
+}
+
+func TestSetupStructsMatchIoctlSizes(t *testing.T) {
+	// the size of the argument is encoded in bits 16-29 of the request
+	for cmd, size := range map[uintptr]uintptr{
+		uiDevSetup: unsafe.Sizeof(uinputSetup{}),
+		uiAbsSetup: unsafe.Sizeof(uinputAbsSetup{}),
+	} {
+		if encoded := (cmd >> 16) & 0x3fff; encoded != size {
+			t.Fatalf("Expected size %d for request %#x, but the struct has %d bytes", encoded, cmd, size)
+		}
+	}
+}
+
+// failDevCreate returns a fake ioctl behavior that fails UI_DEV_CREATE with the given errors, one per attempt.
+func failDevCreate(errs ...error) func(cmd uintptr) error {
+	return func(cmd uintptr) error {
+		if cmd != uiDevCreate || len(errs) == 0 {
+			return nil
+		}
+		err := errs[0]
+		errs = errs[1:]
+		return err
+	}
+}
+
+func TestDeviceCreationIsRetriedOnTransientErrors(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(failDevCreate(syscall.EBUSY, syscall.EBUSY))
+	defer restore()
+
+	dial, err := CreateDial(path, []byte("Test Dial"), WithCreateRetry(3, time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual dial. Last error was: %s\n", err)
+	}
+	defer dial.Close()
+
+	if n := len(registeredCodes(*calls, uiDevCreate)); n != 3 {
+		t.Fatalf("Expected UI_DEV_CREATE to be issued 3 times, but it was issued %d times", n)
+	}
+}
+
+func TestDeviceCreationFailsIfRetriesAreExhausted(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(failDevCreate(syscall.EBUSY, syscall.EBUSY))
+	defer restore()
+
+	_, err := CreateDial(path, []byte("Test Dial"), WithCreateRetry(2, time.Millisecond))
+	if !errors.Is(err, syscall.EBUSY) {
+		t.Fatalf("Expected creation to fail with EBUSY, but got: %v", err)
+	}
+	if n := len(registeredCodes(*calls, uiDevCreate)); n != 2 {
+		t.Fatalf("Expected UI_DEV_CREATE to be issued 2 times, but it was issued %d times", n)
+	}
+}
+
+func TestDeviceCreationIsNotRetriedOnPermanentErrors(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(failDevCreate(syscall.EINVAL))
+	defer restore()
+
+	_, err := CreateDial(path, []byte("Test Dial"), WithCreateRetry(3, time.Millisecond))
+	if !errors.Is(err, syscall.EINVAL) {
+		t.Fatalf("Expected creation to fail with EINVAL, but got: %v", err)
+	}
+	if n := len(registeredCodes(*calls, uiDevCreate)); n != 1 {
+		t.Fatalf("Expected UI_DEV_CREATE to be issued once, but it was issued %d times", n)
+	}
+}
+
+func TestFetchSyspathContextRetriesUntilSuccess(t *testing.T) {
+	attempts := 0
+	calls, restore := fakeIoctl(func(cmd uintptr) error {
+		if cmd != uiGetSysname {
+			return nil
+		}
+		attempts++
+		if attempts < 3 {
+			return syscall.ENOENT
+		}
+		return nil
+	})
+	defer restore()
+
+	file, stop := recordEvents(t)
+	defer stop()
+	dev := vScrollDevice{deviceBase: deviceBase{deviceFile: file}}
+	_, err := dev.FetchSyspathContext(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to fetch syspath. Last error was: %s\n", err)
+	}
+	if n := len(registeredCodes(*calls, uiGetSysname)); n != 3 {
+		t.Fatalf("Expected UI_GET_SYSNAME to be issued 3 times, but it was issued %d times", n)
+	}
+}
+
+func TestFetchSyspathContextReturnsLastErrorOnTimeout(t *testing.T) {
+	_, restore := fakeIoctl(func(cmd uintptr) error {
+		return syscall.ENOENT
+	})
+	defer restore()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*syspathPollInterval)
+	defer cancel()
+
+	file, stop := recordEvents(t)
+	defer stop()
+	dev := vScrollDevice{deviceBase: deviceBase{deviceFile: file}}
+	_, err := dev.FetchSyspathContext(ctx)
+	if !errors.Is(err, syscall.ENOENT) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the last error along with the deadline to be returned, but got: %v", err)
+	}
+}
+
+func TestDevicesWithTheSameNameGetDistinctPhys(t *testing.T) {
+	dev := uinputUserDev{Name: toUinputName([]byte("Test Device"))}
+
+	first := devicePhys(dev, deviceOptions{})
+	second := devicePhys(dev, deviceOptions{})
+	if first == second {
+		t.Fatalf("Expected distinct phys strings, but both are %q", first)
+	}
+	if !strings.HasPrefix(first, "uinput/virtual/Test Device/input") {
+		t.Fatalf("Expected the phys to contain the name of the device, but got %q", first)
+	}
+}
+
+func TestPhysCanBeSetExplicitly(t *testing.T) {
+	dev := uinputUserDev{Name: toUinputName([]byte("Test Device"))}
+
+	phys := devicePhys(dev, newDeviceOptions([]Option{WithPhys("usb-0000:00:14.0-1/input0")}))
+	if phys != "usb-0000:00:14.0-1/input0" {
+		t.Fatalf("Expected: %s\nActual: %s", "usb-0000:00:14.0-1/input0", phys)
+	}
+}
+
+func TestPhysIsSetIndependentlyOfName(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	_, restore := fakeIoctl(nil)
+	defer restore()
+	logger := &recordingLogger{}
+
+	dial, err := CreateDial(path, []byte("Test Dial"), WithSetupMethod(SetupLegacy),
+		WithPhys("usb-0000:00:14.0-1/input0"), WithLogger(logger))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual dial. Last error was: %s\n", err)
+	}
+	defer dial.Close()
+
+	if name := uinputName(readUserDev(t, path)); name != "Test Dial" {
+		t.Fatalf("Expected the name to be %q, but got %q", "Test Dial", name)
+	}
+	var phys any
+	for _, args := range logger.infoArgs {
+		for i := 0; i+1 < len(args); i += 2 {
+			if args[i] == "phys" {
+				phys = args[i+1]
+			}
+		}
+	}
+	if phys != "usb-0000:00:14.0-1/input0" {
+		t.Fatalf("Expected the phys to be set independently of the name, but got %v", phys)
+	}
+}
+
+func TestPhysIsSetBeforeDeviceIsCreated(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	calls, restore := fakeIoctl(nil)
+	defer restore()
+
+	dial, err := CreateDial(path, []byte("Test Dial"))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual dial. Last error was: %s\n", err)
+	}
+	defer dial.Close()
+
+	var cmds []uintptr
+	for _, call := range *calls {
+		if call.cmd == uiSetPhys || call.cmd == uiDevCreate {
+			cmds = append(cmds, call.cmd)
+		}
+	}
+	if !reflect.DeepEqual(cmds, []uintptr{uiSetPhys, uiDevCreate}) {
+		t.Fatalf("Expected UI_SET_PHYS to be issued before UI_DEV_CREATE, but got %v", cmds)
+	}
+}
+
+// recordingLogger is a Logger that keeps the messages that are logged through it, prefixed by their level.
+type recordingLogger struct {
+	messages []string
+	// infoArgs holds the arguments of the messages that have been logged at info level
+	infoArgs [][]any
+}
+
+func (l *recordingLogger) Debug(msg string, args ...any) {
+	l.messages = append(l.messages, "DEBUG "+msg)
+}
+func (l *recordingLogger) Info(msg string, args ...any) {
+	l.messages = append(l.messages, "INFO "+msg)
+	l.infoArgs = append(l.infoArgs, args)
+}
+func (l *recordingLogger) Error(msg string, args ...any) {
+	l.messages = append(l.messages, "ERROR "+msg)
+}
+
+func TestLoggerReceivesCreationAndReports(t *testing.T) {
+	path, remove := fakeDevicePath(t)
+	defer remove()
+	_, restore := fakeIoctl(nil)
+	defer restore()
+	logger := &recordingLogger{}
+
+	mouse, err := CreateMouse(path, []byte("Test Mouse"), WithLogger(logger))
+	if err != nil {
+		t.Fatalf("Failed to create the virtual mouse. Last error was: %s\n", err)
+	}
+	defer mouse.Close()
+	err = mouse.MoveRight(1)
+	if err != nil {
+		t.Fatalf("Failed to move mouse. Last error was: %s\n", err)
+	}
+
+	expected := []string{"INFO created input device", "DEBUG wrote report"}
+	if !reflect.DeepEqual(logger.messages, expected) {
+		t.Fatalf("Expected: %v\nActual: %v", expected, logger.messages)
+	}
+}
+
+func TestLoggerReceivesFailedWrites(t *testing.T) {
+	logger := &recordingLogger{}
+	mouse, err := CreateMouseWriter(failingWriter{err: syscall.EIO}, []byte("Test Mouse"), WithLogger(logger))
+	if err != nil {
+		t.Fatalf("Failed to create the mouse. Last error was: %s\n", err)
+	}
+
+	_ = mouse.MoveRight(1)
+
+	expected := []string{"ERROR failed to write report"}
+	if !reflect.DeepEqual(logger.messages, expected) {
+		t.Fatalf("Expected: %v\nActual: %v", expected, logger.messages)
+	}
+}
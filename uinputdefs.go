@@ -1,6 +1,10 @@
 package uinput
 
-import "syscall"
+import (
+	"syscall"
+	"time"
+	"unsafe"
+)
 
 // types needed from uinput.h
 const (
@@ -8,15 +12,39 @@ const (
 	uiDevCreate       = 0x5501
 	uiDevDestroy      = 0x5502
 	uiDevSetup        = 0x405c5503
+	uiAbsSetup        = 0x401c5504
+	uiGetVersion      = 0x8004552d
+	// uinputSetupVersion is the first version of uinput that supports UI_DEV_SETUP and UI_ABS_SETUP (kernel 4.5)
+	uinputSetupVersion = 5
 	// this is for 64 length buffer to store name
 	// for another length generate using : (len << 16) | 0x8000552C
 	uiGetSysname = 0x8041552c
 	uiSetEvBit   = 0x40045564
 	uiSetKeyBit  = 0x40045565
 
-	uiSetRelBit = 0x40045566
-	uiSetAbsBit = 0x40045567
-	busUsb      = 0x03
+	uiSetRelBit  = 0x40045566
+	uiSetAbsBit  = 0x40045567
+	uiSetMscBit  = 0x40045568
+	uiSetLedBit  = 0x40045569
+	uiSetPropBit = 0x4004556e
+	busUsb       = 0x03
+
+	// UI_SET_PHYS takes a pointer, so its size depends on the architecture
+	uiSetPhys = 0x4000556c | unsafe.Sizeof(uintptr(0))<<16
+
+	// EVIOCGRAB grabs an event node exclusively, it is issued on the event node rather than on the uinput device
+	eviocGrab = 0x40044590
+
+	// codes of the EV_UINPUT events that are sent to the device file in order to request force feedback effects
+	uiFFUpload = 1
+	uiFFErase  = 2
+
+	// the requests are answered using these ioctls; the size of uinput_ff_upload depends on the architecture
+	uiSetFFBit      = 0x4004556b
+	uiBeginFFUpload = 0xc00055c8 | unsafe.Sizeof(uinputFFUpload{})<<16
+	uiEndFFUpload   = 0x400055c9 | unsafe.Sizeof(uinputFFUpload{})<<16
+	uiBeginFFErase  = 0xc00c55ca
+	uiEndFFErase    = 0x400c55cb
 )
 
 // input event codes as specified in input-event-codes.h
@@ -25,40 +53,82 @@ const (
 	evKey          = 0x01
 	evRel          = 0x02
 	evAbs          = 0x03
+	evMsc          = 0x04
+	evLed          = 0x11
+	evFF           = 0x15
+	evUinput       = 0x0101
 	relX           = 0x0
 	relY           = 0x1
+	relZ           = 0x2
+	relRX          = 0x3
+	relRY          = 0x4
+	relRZ          = 0x5
 	relHWheel      = 0x6
 	relWheel       = 0x8
 	relDial        = 0x7
 	relWheelHiRes  = 0x0b
 	relHWheelHiRes = 0x0c
+	// relMax corresponds to REL_MAX, the highest code of all relative axes
+	relMax = 0x0f
+
+	absX        = 0x00
+	absY        = 0x01
+	absZ        = 0x02
+	absRX       = 0x03
+	absRY       = 0x04
+	absRZ       = 0x05
+	absHat0X    = 0x10
+	absHat0Y    = 0x11
+	absPressure = 0x18
+	absMisc     = 0x28
+
+	absMtSlot        = 0x2f
+	absMtTouchMajor  = 0x30
+	absMtOrientation = 0x34
+	absMtPositionX   = 0x35
+	absMtPositionY   = 0x36
+	absMtToolType    = 0x37
+	absMtBlobId      = 0x38
+	absMtTrackingId  = 0x39
+
+	mscScan      = 0x04
+	mscTimestamp = 0x05
+
+	ledNumL    = 0x00
+	ledCapsL   = 0x01
+	ledScrollL = 0x02
+
+	ffRumble = 0x50
+	// ffGain corresponds to FF_GAIN, the first code of the EV_FF events that do not refer to an effect. It equals
+	// FF_MAX_EFFECTS, the maximum number of effects of a device.
+	ffGain = 0x60
+
+	// inputPropDirect marks devices whose coordinates correspond to the screen, like touchscreens
+	inputPropDirect = 0x01
 
-	absX     = 0x00
-	absY     = 0x01
-	absZ     = 0x02
-	absRX    = 0x03
-	absRY    = 0x04
-	absRZ    = 0x05
-	absHat0X = 0x10
-	absHat0Y = 0x11
-
-	absMtSlot       = 0x2f
-	absMtTouchMajor = 0x30
-	absMtPositionX  = 0x35
-	absMtPositionY  = 0x36
-	absMtTrackingId = 0x39
-
-	synReport        = 0
+	synReport = 0
+	// btn0 corresponds to BTN_0, the first of the generic buttons BTN_0 to BTN_9
+	btn0             = 0x100
 	evMouseBtnLeft   = 0x110
 	evMouseBtnRight  = 0x111
 	evMouseBtnMiddle = 0x112
+	evBtnToolPen     = 0x140
+	evBtnToolRubber  = 0x141
 	evBtnTouch       = 0x14a
+	// kernelKeyMax corresponds to KEY_MAX, the highest code of all keys and buttons
+	kernelKeyMax = 0x2ff
 )
 
+// syspathPollInterval is the time between two attempts to fetch the syspath of a device (see FetchSyspathContext).
+const syspathPollInterval = 10 * time.Millisecond
+
 const (
 	btnStateReleased = 0
 	btnStatePressed  = 1
-	absSize          = 64
+	btnStateRepeated = 2
+	// absSize corresponds to ABS_CNT (ABS_MAX + 1) of the kernel, which is the number of entries of the
+	// abs arrays of uinput_user_dev. Axis codes need to be lower than this value.
+	absSize = 64
 )
 
 type inputID struct {
@@ -79,6 +149,29 @@ type uinputUserDev struct {
 	Absflat    [absSize]int32
 }
 
+// translated to go from uinput.h
+type uinputSetup struct {
+	ID         inputID
+	Name       [uinputMaxNameSize]byte
+	EffectsMax uint32
+}
+
+// translated to go from uinput.h
+type uinputAbsSetup struct {
+	Code    uint16
+	Absinfo inputAbsinfo
+}
+
+// translated to go from input.h
+type inputAbsinfo struct {
+	Value      int32
+	Minimum    int32
+	Maximum    int32
+	Fuzz       int32
+	Flat       int32
+	Resolution int32
+}
+
 // translated to go from input.h
 type inputEvent struct {
 	Time  syscall.Timeval
@@ -86,3 +179,47 @@ type inputEvent struct {
 	Code  uint16
 	Value int32
 }
+
+// translated to go from uinput.h
+type uinputFFUpload struct {
+	RequestID uint32
+	Retval    int32
+	Effect    ffEffect
+	Old       ffEffect
+}
+
+// translated to go from uinput.h
+type uinputFFErase struct {
+	RequestID uint32
+	Retval    int32
+	EffectID  uint32
+}
+
+// translated to go from input.h
+type ffEffect struct {
+	Type      uint16
+	ID        int16
+	Direction uint16
+	Trigger   ffTrigger
+	Replay    ffReplay
+	U         ffEffectUnion
+}
+
+// translated to go from input.h
+type ffTrigger struct {
+	Button   uint16
+	Interval uint16
+}
+
+// translated to go from input.h
+type ffReplay struct {
+	Length uint16
+	Delay  uint16
+}
+
+// ffEffectUnion takes the place of the union of the effect parameters in ff_effect. Its largest member,
+// ff_periodic_effect, ends with a pointer, which determines the size and alignment of the union.
+type ffEffectUnion struct {
+	Data       [24]byte
+	CustomData uintptr
+}
@@ -0,0 +1,263 @@
+package uinput
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidSequence is returned (wrapped) by a Validator if an event violates the rules of the input protocol.
+var ErrInvalidSequence = errors.New("invalid event sequence")
+
+// A Validator checks the events that are sent to a device against the rules of the kernel's input protocol, in
+// order to catch misuse in tests before it hits real hardware:
+//
+//   - the type and code of every event need to be registered in the configuration of the device
+//   - a key may only be pressed if it is released, and only be released (or repeated) if it is pressed
+//   - the multitouch axes of a slot may only be reported while the slot has a tracking id
+//   - all keys need to be released before the Validator is closed
+//
+// Events that pass the checks are forwarded to the wrapped device, which may be nil in order to validate event
+// sequences without creating a device at all (simulation mode). A Validator implements EventSender, so it can
+// be used with ReplayEvemu as well. In order to validate the events of the methods of a device instead, like
+// KeyDown or MoveTo, create the device using WithValidation, which rejects invalid reports before they are
+// written and derives the configuration from the device itself.
+type Validator struct {
+	mu  sync.Mutex
+	dev EventSender
+	// errs holds the violations that have been reported to the hook (see Validator.Hook)
+	errs []error
+
+	evTypes map[uint16]bool
+	keys    map[uint16]bool
+	rels    map[uint16]bool
+	abs     map[uint16]bool
+
+	pressed  map[uint16]bool
+	slot     int32
+	tracking map[int32]int32
+}
+
+// NewValidator returns a Validator that checks the events sent to the given device against the given configuration
+// (see DeviceBuilder.Config). The device may be nil.
+func NewValidator(dev EventSender, config DeviceConfig) *Validator {
+	v := &Validator{
+		dev:      dev,
+		evTypes:  codeSet(config.EvTypes),
+		keys:     codeSet(config.Keys),
+		rels:     codeSet(config.Rels),
+		abs:      make(map[uint16]bool, len(config.Abs)),
+		pressed:  make(map[uint16]bool),
+		tracking: make(map[int32]int32),
+	}
+	for _, axis := range config.Abs {
+		v.abs[axis.Code] = true
+	}
+	return v
+}
+
+func codeSet(codes []uint16) map[uint16]bool {
+	set := make(map[uint16]bool, len(codes))
+	for _, code := range codes {
+		set[code] = true
+	}
+	return set
+}
+
+// SendEvent validates the given event and forwards it to the device (see EventSender). Invalid events are not
+// forwarded, and events whose forwarding fails do not change the state of the Validator.
+func (v *Validator) SendEvent(evType uint16, code uint16, value int32) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	err := v.validate(evType, code, value)
+	if err != nil {
+		return err
+	}
+	if v.dev != nil {
+		err = v.dev.SendEvent(evType, code, value)
+		if err != nil {
+			return err
+		}
+	}
+	v.apply(evType, code, value)
+	return nil
+}
+
+// Hook returns an EventHook that validates every event that a device emits, no matter which of its methods
+// emitted it, e.g.:
+//
+//	validator := uinput.NewValidator(nil, config)
+//	keyboard, err := uinput.CreateKeyboard("/dev/uinput", name, uinput.WithEventHook(validator.Hook()))
+//
+// Since a hook can not reject events, the violations are collected instead and returned by Err and Close. Unlike
+// WithValidation, invalid events have been written to the device by the time they are reported.
+func (v *Validator) Hook() EventHook {
+	return func(evType uint16, code uint16, value int32) {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+
+		err := v.validate(evType, code, value)
+		if err != nil {
+			v.errs = append(v.errs, err)
+			return
+		}
+		v.apply(evType, code, value)
+	}
+}
+
+// Err returns the violations that have been reported to the hook of the Validator so far (see Validator.Hook).
+func (v *Validator) Err() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return errors.Join(v.errs...)
+}
+
+// validate checks the given event against the configuration and the current state, without changing the latter.
+func (v *Validator) validate(evType uint16, code uint16, value int32) error {
+	switch evType {
+	case evSyn:
+		return nil
+	case evKey:
+		return v.validateKey(code, value)
+	case evRel:
+		if !v.rels[code] {
+			return fmt.Errorf("%w: relative axis %d is not registered", ErrInvalidSequence, code)
+		}
+		return nil
+	case evAbs:
+		return v.validateAbs(code, value)
+	}
+	if !v.evTypes[evType] {
+		return fmt.Errorf("%w: event type %d is not registered", ErrInvalidSequence, evType)
+	}
+	return nil
+}
+
+func (v *Validator) validateKey(code uint16, value int32) error {
+	if !v.keys[code] {
+		return fmt.Errorf("%w: key %d is not registered", ErrInvalidSequence, code)
+	}
+	switch value {
+	case btnStatePressed:
+		if v.pressed[code] {
+			return fmt.Errorf("%w: key %d is pressed, but it has not been released", ErrInvalidSequence, code)
+		}
+	case btnStateReleased:
+		if !v.pressed[code] {
+			return fmt.Errorf("%w: key %d is released, but it has not been pressed", ErrInvalidSequence, code)
+		}
+	case btnStateRepeated:
+		if !v.pressed[code] {
+			return fmt.Errorf("%w: key %d is repeated, but it has not been pressed", ErrInvalidSequence, code)
+		}
+	default:
+		return fmt.Errorf("%w: invalid value %d of key %d", ErrInvalidSequence, value, code)
+	}
+	return nil
+}
+
+func (v *Validator) validateAbs(code uint16, value int32) error {
+	if !v.abs[code] {
+		return fmt.Errorf("%w: absolute axis %d is not registered", ErrInvalidSequence, code)
+	}
+	if code >= absMtTouchMajor && code != absMtTrackingId {
+		// all other multitouch axes describe the contact of the current slot
+		if id, ok := v.tracking[v.slot]; !ok || id < 0 {
+			return fmt.Errorf("%w: absolute axis %d of slot %d is reported before its tracking id", ErrInvalidSequence, code, v.slot)
+		}
+	}
+	return nil
+}
+
+// validateReport validates the given events in order, right before they are added to a report of a device (see
+// WithValidation). The state of the Validator is only updated if all of them are valid, so that a rejected call of
+// a device method leaves no trace.
+func (v *Validator) validateReport(events []inputEvent) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	pressed := make(map[uint16]bool, len(v.pressed))
+	for code := range v.pressed {
+		pressed[code] = true
+	}
+	tracking := make(map[int32]int32, len(v.tracking))
+	for slot, id := range v.tracking {
+		tracking[slot] = id
+	}
+	slot := v.slot
+
+	for _, ev := range events {
+		err := v.validate(ev.Type, ev.Code, ev.Value)
+		if err != nil {
+			v.pressed, v.tracking, v.slot = pressed, tracking, slot
+			return err
+		}
+		v.apply(ev.Type, ev.Code, ev.Value)
+	}
+	return nil
+}
+
+// apply updates the state of the Validator with an event that has passed validate.
+func (v *Validator) apply(evType uint16, code uint16, value int32) {
+	switch {
+	case evType == evKey && value == btnStatePressed:
+		v.pressed[code] = true
+	case evType == evKey && value == btnStateReleased:
+		delete(v.pressed, code)
+	case evType == evAbs && code == absMtSlot:
+		v.slot = value
+	case evType == evAbs && code == absMtTrackingId:
+		v.tracking[v.slot] = value
+	}
+}
+
+// Close closes the wrapped device if it implements io.Closer. It returns an error if keys are still pressed, along
+// with the violations that have been reported to the hook (see Validator.Hook).
+func (v *Validator) Close() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	err := errors.Join(errors.Join(v.errs...), v.heldKeysLocked())
+	if closer, ok := v.dev.(io.Closer); ok {
+		err = errors.Join(err, closer.Close())
+	}
+	return err
+}
+
+// heldKeys returns an error if keys are still pressed.
+func (v *Validator) heldKeys() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.heldKeysLocked()
+}
+
+func (v *Validator) heldKeysLocked() error {
+	if len(v.pressed) == 0 {
+		return nil
+	}
+	codes := make([]int, 0, len(v.pressed))
+	for code := range v.pressed {
+		codes = append(codes, int(code))
+	}
+	sort.Ints(codes)
+	return fmt.Errorf("%w: keys %v are still pressed", ErrInvalidSequence, codes)
+}
+
+// deviceCapabilities returns the configuration of the device that the given writer belongs to, as registered in the
+// kernel (see WithValidation). It is a variable in order to be replaced in tests, which do not create actual devices.
+var deviceCapabilities = func(w io.Writer) (DeviceConfig, error) {
+	deviceFile, ok := w.(*os.File)
+	if !ok {
+		return DeviceConfig{}, errors.New("only devices that have been created by this package can be validated")
+	}
+	syspath, err := fetchSyspath(deviceFile)
+	if err != nil {
+		return DeviceConfig{}, fmt.Errorf("failed to fetch syspath: %w", err)
+	}
+	return readCapabilities(strings.TrimRight(syspath, "\x00"))
+}
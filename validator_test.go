@@ -0,0 +1,258 @@
+package uinput
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"syscall"
+	"testing"
+)
+
+func newTestValidator(t *testing.T) *Validator {
+	config, err := NewDeviceBuilder().
+		AddKey(KeyA).
+		AddRel(relX).
+		AddAbs(absMtSlot, 0, 1, 0).
+		AddAbs(absMtTrackingId, 0, multiTouchMaxTrackingID, 0).
+		AddAbs(absMtPositionX, 0, 1024, 0).
+		Config([]byte("Test Device"))
+	if err != nil {
+		t.Fatalf("Failed to setup test. Unable to build the config: %v", err)
+	}
+	return NewValidator(nil, config)
+}
+
+func TestValidatorRejectsUnbalancedPress(t *testing.T) {
+	v := newTestValidator(t)
+
+	err := v.SendEvent(evKey, KeyA, btnStatePressed)
+	if err != nil {
+		t.Fatalf("Failed to press key. Last error was: %s\n", err)
+	}
+	err = v.SendEvent(evKey, KeyA, btnStatePressed)
+	if !errors.Is(err, ErrInvalidSequence) {
+		t.Fatalf("Expected pressing a pressed key to fail, but got: %v", err)
+	}
+
+	err = v.Close()
+	if !errors.Is(err, ErrInvalidSequence) {
+		t.Fatalf("Expected closing with a pressed key to fail, but got: %v", err)
+	}
+}
+
+func TestValidatorRejectsReleaseWithoutPress(t *testing.T) {
+	v := newTestValidator(t)
+
+	err := v.SendEvent(evKey, KeyA, btnStateReleased)
+	if !errors.Is(err, ErrInvalidSequence) {
+		t.Fatalf("Expected releasing a released key to fail, but got: %v", err)
+	}
+}
+
+func TestValidatorRejectsUnregisteredCodes(t *testing.T) {
+	v := newTestValidator(t)
+
+	for _, ev := range []inputEvent{
+		{Type: evKey, Code: KeyB, Value: btnStatePressed},
+		{Type: evRel, Code: relY, Value: 1},
+		{Type: evAbs, Code: absX, Value: 1},
+		{Type: evMsc, Code: mscScan, Value: 1},
+	} {
+		err := v.SendEvent(ev.Type, ev.Code, ev.Value)
+		if !errors.Is(err, ErrInvalidSequence) {
+			t.Fatalf("Expected event %+v to be rejected, but got: %v", ev, err)
+		}
+	}
+}
+
+func TestValidatorRequiresTrackingIDBeforeMultiTouchPosition(t *testing.T) {
+	v := newTestValidator(t)
+
+	err := v.SendEvent(evAbs, absMtPositionX, 10)
+	if !errors.Is(err, ErrInvalidSequence) {
+		t.Fatalf("Expected a position without tracking id to be rejected, but got: %v", err)
+	}
+
+	for _, ev := range []inputEvent{
+		{Type: evAbs, Code: absMtSlot, Value: 1},
+		{Type: evAbs, Code: absMtTrackingId, Value: 0},
+		{Type: evAbs, Code: absMtPositionX, Value: 10},
+		{Type: evSyn, Code: synReport},
+	} {
+		err = v.SendEvent(ev.Type, ev.Code, ev.Value)
+		if err != nil {
+			t.Fatalf("Failed to send event %+v. Last error was: %s\n", ev, err)
+		}
+	}
+}
+
+func TestValidatorForwardsValidEvents(t *testing.T) {
+	file, stop := recordEvents(t)
+	config, err := NewDeviceBuilder().AddKey(KeyA).Config([]byte("Test Device"))
+	if err != nil {
+		t.Fatalf("Failed to setup test. Unable to build the config: %v", err)
+	}
+	v := NewValidator(vDevice{deviceBase: deviceBase{report: newReportBuilder(file)}}, config)
+
+	for _, ev := range []inputEvent{
+		{Type: evKey, Code: KeyA, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: KeyA, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	} {
+		err = v.SendEvent(ev.Type, ev.Code, ev.Value)
+		if err != nil {
+			t.Fatalf("Failed to send event %+v. Last error was: %s\n", ev, err)
+		}
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: KeyA, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+		{Type: evKey, Code: KeyA, Value: btnStateReleased},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestValidatorHookValidatesDeviceMethods(t *testing.T) {
+	config, err := NewDeviceBuilder().AddKey(KeyA).Config([]byte("Test Keyboard"))
+	if err != nil {
+		t.Fatalf("Failed to setup test. Unable to build the config: %v", err)
+	}
+	v := NewValidator(nil, config)
+	options := newDeviceOptions([]Option{WithEventHook(v.Hook())})
+	vk := &vKeyboard{deviceBase: deviceBase{report: newReportBuilderWithOptions(ioutil.Discard, nil, options)}}
+
+	err = vk.KeyPress(KeyA)
+	if err != nil {
+		t.Fatalf("Failed to press key. Last error was: %s\n", err)
+	}
+	if err = v.Err(); err != nil {
+		t.Fatalf("Expected a key press to be valid, but got: %v", err)
+	}
+
+	err = vk.KeyDown(KeyB)
+	if err != nil {
+		t.Fatalf("Failed to press key. Last error was: %s\n", err)
+	}
+	if err = v.Err(); !errors.Is(err, ErrInvalidSequence) {
+		t.Fatalf("Expected pressing an unregistered key to be reported, but got: %v", err)
+	}
+	if err = v.Close(); !errors.Is(err, ErrInvalidSequence) {
+		t.Fatalf("Expected closing to report the violation, but got: %v", err)
+	}
+}
+
+type failingSender struct {
+	err error
+}
+
+func (s failingSender) SendEvent(evType uint16, code uint16, value int32) error {
+	return s.err
+}
+
+func TestValidatorIgnoresEventsThatFailToForward(t *testing.T) {
+	config, err := NewDeviceBuilder().AddKey(KeyA).Config([]byte("Test Device"))
+	if err != nil {
+		t.Fatalf("Failed to setup test. Unable to build the config: %v", err)
+	}
+	v := NewValidator(failingSender{err: syscall.EIO}, config)
+
+	err = v.SendEvent(evKey, KeyA, btnStatePressed)
+	if !errors.Is(err, syscall.EIO) {
+		t.Fatalf("Expected the error of the device, but got: %v", err)
+	}
+	// the key has not been pressed, so there is nothing to release
+	err = v.Close()
+	if err != nil {
+		t.Fatalf("Expected the failed press not to be tracked, but got: %v", err)
+	}
+}
+
+// fakeDeviceCapabilities makes all devices validate their events against the given configuration (see
+// WithValidation). The returned function restores the original capabilities.
+func fakeDeviceCapabilities(config DeviceConfig) func() {
+	original := deviceCapabilities
+	deviceCapabilities = func(w io.Writer) (DeviceConfig, error) {
+		return config, nil
+	}
+	return func() { deviceCapabilities = original }
+}
+
+func TestWithValidationRejectsInvalidMethodCallsBeforeWriting(t *testing.T) {
+	config, err := NewDeviceBuilder().AddKey(KeyA).Config([]byte("Test Keyboard"))
+	if err != nil {
+		t.Fatalf("Failed to setup test. Unable to build the config: %v", err)
+	}
+	defer fakeDeviceCapabilities(config)()
+	file, stop := recordEvents(t)
+	options := newDeviceOptions([]Option{WithValidation()})
+	vk := &vKeyboard{deviceBase: deviceBase{report: newReportBuilderWithOptions(file, nil, options)}}
+
+	err = vk.KeyUp(KeyA)
+	if !errors.Is(err, ErrInvalidSequence) {
+		t.Fatalf("Expected releasing a released key to fail, but got: %v", err)
+	}
+	err = vk.KeyPress(KeyB)
+	if !errors.Is(err, ErrInvalidSequence) {
+		t.Fatalf("Expected pressing an unregistered key to fail, but got: %v", err)
+	}
+	err = vk.KeyDown(KeyA)
+	if err != nil {
+		t.Fatalf("Failed to press key. Last error was: %s\n", err)
+	}
+	err = vk.Close()
+	if !errors.Is(err, ErrInvalidSequence) {
+		t.Fatalf("Expected closing with a pressed key to fail, but got: %v", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: KeyA, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestWithValidationRejectsWholeReport(t *testing.T) {
+	config, err := NewDeviceBuilder().
+		AddKey(KeyA).
+		AddAbs(absMtSlot, 0, 1, 0).
+		AddAbs(absMtTrackingId, 0, multiTouchMaxTrackingID, 0).
+		AddAbs(absMtPositionX, 0, 1024, 0).
+		Config([]byte("Test Device"))
+	if err != nil {
+		t.Fatalf("Failed to setup test. Unable to build the config: %v", err)
+	}
+	defer fakeDeviceCapabilities(config)()
+	file, stop := recordEvents(t)
+	rb := newReportBuilderWithOptions(file, nil, newDeviceOptions([]Option{WithValidation()}))
+
+	// the press is valid on its own, but the position of the report lacks a tracking id
+	err = rb.send(
+		inputEvent{Type: evKey, Code: KeyA, Value: btnStatePressed},
+		inputEvent{Type: evAbs, Code: absMtPositionX, Value: 10},
+	)
+	if !errors.Is(err, ErrInvalidSequence) {
+		t.Fatalf("Expected a position without tracking id to be rejected, but got: %v", err)
+	}
+	err = rb.send(inputEvent{Type: evKey, Code: KeyA, Value: btnStatePressed})
+	if err != nil {
+		t.Fatalf("Expected the rejected report to leave the key released, but got: %v", err)
+	}
+
+	assertEvents(t, []inputEvent{
+		{Type: evKey, Code: KeyA, Value: btnStatePressed},
+		{Type: evSyn, Code: synReport},
+	}, stop())
+}
+
+func TestWithValidationFailsWithoutDevice(t *testing.T) {
+	mouse, err := CreateMouseWriter(ioutil.Discard, []byte("Test Mouse"), WithValidation())
+	if err != nil {
+		t.Fatalf("Failed to create the mouse writer. Last error was: %s\n", err)
+	}
+
+	err = mouse.LeftClick()
+	if err == nil || errors.Is(err, ErrInvalidSequence) {
+		t.Fatalf("Expected validation to fail due to the missing device, but got: %v", err)
+	}
+}